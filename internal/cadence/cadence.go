@@ -45,4 +45,8 @@ var Cmd = &cobra.Command{
 func init() {
 	Cmd.AddCommand(languageserver.Cmd)
 	lintCommand.AddToParent(Cmd)
+	fmtCommand.AddToParent(Cmd)
+	docsCommand.AddToParent(Cmd)
+	graphCommand.AddToParent(Cmd)
+	replCommand.AddToParent(Cmd)
 }
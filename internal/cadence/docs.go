@@ -0,0 +1,632 @@
+/*
+ * Flow CLI
+ *
+ * Copyright Flow Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cadence
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/onflow/cadence/ast"
+	"github.com/onflow/cadence/common"
+	"github.com/onflow/cadence/parser"
+	"github.com/onflow/flowkit/v2"
+	"github.com/onflow/flowkit/v2/output"
+
+	"github.com/onflow/flow-cli/internal/command"
+	"github.com/onflow/flow-cli/internal/util"
+)
+
+const (
+	docsFormatMarkdown = "markdown"
+	docsFormatHTML     = "html"
+)
+
+type docsFlagsCollection struct {
+	DocFormat string `default:"markdown" flag:"doc-format" info:"Format of the docs: 'markdown' or 'html'"`
+	OutDir    string `default:"docs/contracts" flag:"out-dir" info:"Directory to write the docs to"`
+}
+
+var docsFlags = docsFlagsCollection{}
+
+var docsCommand = &command.Command{
+	Cmd: &cobra.Command{
+		Use:   "docs",
+		Short: "Generate reference docs for the contracts of the project",
+		Example: `# Write a Markdown page per contract and an index to docs/contracts
+flow cadence docs
+
+# Write a single HTML page
+flow cadence docs --doc-format html --out-dir build/docs`,
+		Args: cobra.NoArgs,
+	},
+	Flags: &docsFlags,
+	RunS:  generateDocs,
+}
+
+// contractDocs is the reference of a contract declared in flow.json.
+type contractDocs struct {
+	Name       string
+	Location   string
+	Dependency bool
+	Doc        string
+	// Imports are the names of the contracts imported by the contract
+	Imports []string
+	// ImportedBy are the names of the contracts of the project importing the contract
+	ImportedBy   []string
+	Composites   []typeDocs
+	Interfaces   []typeDocs
+	Events       []memberDocs
+	Entitlements []memberDocs
+}
+
+// typeDocs is the reference of a composite type or interface, with its public members.
+type typeDocs struct {
+	Name      string
+	Kind      string
+	Signature string
+	Doc       string
+	Fields    []memberDocs
+	Functions []memberDocs
+}
+
+type memberDocs struct {
+	Name      string
+	Signature string
+	Doc       string
+}
+
+type docsResult struct {
+	Format    string   `json:"format"`
+	Directory string   `json:"directory"`
+	Files     []string `json:"files"`
+	contracts int
+}
+
+var _ command.Result = &docsResult{}
+
+func generateDocs(
+	_ []string,
+	_ command.GlobalFlags,
+	_ output.Logger,
+	_ flowkit.Services,
+	state *flowkit.State,
+) (command.Result, error) {
+	if docsFlags.DocFormat != docsFormatMarkdown && docsFlags.DocFormat != docsFormatHTML {
+		return nil, fmt.Errorf("unsupported format %q, expected %q or %q", docsFlags.DocFormat, docsFormatMarkdown, docsFormatHTML)
+	}
+
+	contracts, err := loadContractDocs(state)
+	if err != nil {
+		return nil, err
+	}
+	if len(contracts) == 0 {
+		return nil, fmt.Errorf("no contracts found in the project configuration")
+	}
+
+	files, err := renderDocs(contracts, docsFlags.DocFormat)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &docsResult{
+		Format:    docsFlags.DocFormat,
+		Directory: docsFlags.OutDir,
+		contracts: len(contracts),
+	}
+	err = state.ReaderWriter().MkdirAll(docsFlags.OutDir, 0755)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create docs directory: %w", err)
+	}
+	for _, name := range sortedKeys(files) {
+		path := filepath.Join(docsFlags.OutDir, name)
+		err = state.ReaderWriter().WriteFile(path, files[name], 0644)
+		if err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		result.Files = append(result.Files, path)
+	}
+
+	return result, nil
+}
+
+// loadContractDocs parses every contract declared in flow.json, sorted by name.
+func loadContractDocs(state *flowkit.State) ([]*contractDocs, error) {
+	var contracts []*contractDocs
+	byName := make(map[string]*contractDocs)
+
+	for _, contract := range *state.Contracts() {
+		code, err := state.ReadFile(contract.Location)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read contract %s: %w", contract.Name, err)
+		}
+
+		docs, err := newContractDocs(contract.Name, contract.Location, code)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse contract %s: %w", contract.Name, err)
+		}
+		docs.Dependency = contract.IsDependency
+
+		contracts = append(contracts, docs)
+		byName[docs.Name] = docs
+	}
+
+	sort.Slice(contracts, func(i, j int) bool {
+		return contracts[i].Name < contracts[j].Name
+	})
+	for _, contract := range contracts {
+		for _, imported := range contract.Imports {
+			if dependency, ok := byName[imported]; ok {
+				dependency.ImportedBy = append(dependency.ImportedBy, contract.Name)
+			}
+		}
+	}
+
+	return contracts, nil
+}
+
+func newContractDocs(name string, location string, code []byte) (*contractDocs, error) {
+	program, err := parser.ParseProgram(nil, code, parser.Config{})
+	if err != nil {
+		return nil, err
+	}
+
+	docs := &contractDocs{
+		Name:     name,
+		Location: filepath.ToSlash(location),
+	}
+
+	for _, declaration := range program.ImportDeclarations() {
+		if len(declaration.Imports) == 0 {
+			docs.Imports = append(docs.Imports, strings.TrimSuffix(filepath.Base(declaration.Location.String()), ".cdc"))
+		}
+		for _, imported := range declaration.Imports {
+			docs.Imports = append(docs.Imports, imported.Identifier.Identifier)
+		}
+	}
+	sort.Strings(docs.Imports)
+
+	for _, declaration := range program.Declarations() {
+		if !declaration.DeclarationKind().IsTypeDeclaration() || !isPublicDeclaration(declaration) {
+			continue
+		}
+		if declaration.DeclarationIdentifier().Identifier == name && docs.Doc == "" {
+			docs.Doc = declarationDoc(declaration, code)
+		}
+		docs.addDeclaration(declaration, "", code)
+	}
+
+	return docs, nil
+}
+
+// addDeclaration adds the public type declaration and its nested declarations,
+// which are named qualified by the names of their parents, e.g. "Counter.Vault".
+func (c *contractDocs) addDeclaration(declaration ast.Declaration, prefix string, code []byte) {
+	name := prefix + declaration.DeclarationIdentifier().Identifier
+	kind := declaration.DeclarationKind()
+
+	switch kind {
+	case common.DeclarationKindEvent:
+		c.Events = append(c.Events, newMemberDocs(name, declaration, code))
+		return
+	case common.DeclarationKindEntitlement, common.DeclarationKindEntitlementMapping:
+		c.Entitlements = append(c.Entitlements, newMemberDocs(name, declaration, code))
+		return
+	}
+
+	docs := typeDocs{
+		Name:      name,
+		Kind:      kind.Keywords(),
+		Signature: declarationSignature(declaration, code),
+		Doc:       declarationDoc(declaration, code),
+	}
+
+	var nested []ast.Declaration
+	if members := declaration.DeclarationMembers(); members != nil {
+		for _, member := range members.Declarations() {
+			if member.DeclarationIdentifier() == nil || !isPublicDeclaration(member) {
+				continue
+			}
+
+			switch member.DeclarationKind() {
+			case common.DeclarationKindField:
+				docs.Fields = append(docs.Fields, newMemberDocs(member.DeclarationIdentifier().Identifier, member, code))
+			case common.DeclarationKindFunction:
+				docs.Functions = append(docs.Functions, newMemberDocs(member.DeclarationIdentifier().Identifier, member, code))
+			case common.DeclarationKindEnumCase:
+				docs.Fields = append(docs.Fields, newMemberDocs(member.DeclarationIdentifier().Identifier, member, code))
+			default:
+				if member.DeclarationKind().IsTypeDeclaration() {
+					nested = append(nested, member)
+				}
+			}
+		}
+	}
+
+	if kind.IsInterfaceDeclaration() {
+		c.Interfaces = append(c.Interfaces, docs)
+	} else {
+		c.Composites = append(c.Composites, docs)
+	}
+
+	for _, member := range nested {
+		c.addDeclaration(member, name+".", code)
+	}
+}
+
+func newMemberDocs(name string, declaration ast.Declaration, code []byte) memberDocs {
+	return memberDocs{
+		Name:      name,
+		Signature: declarationSignature(declaration, code),
+		Doc:       declarationDoc(declaration, code),
+	}
+}
+
+// isPublicDeclaration is true for declarations with access(all) or entitlement access,
+// which are part of the API of a contract for everyone, or for the holders of the entitlements.
+func isPublicDeclaration(declaration ast.Declaration) bool {
+	switch access := declaration.DeclarationAccess().(type) {
+	case ast.PrimitiveAccess:
+		return access == ast.AccessAll || access == ast.AccessNotSpecified &&
+			declaration.DeclarationKind() == common.DeclarationKindEnumCase
+	default:
+		return true
+	}
+}
+
+// declarationSignature returns the source of the declaration without its body,
+// with whitespace collapsed, e.g. "access(all) fun deposit(from: @Vault)".
+func declarationSignature(declaration ast.Declaration, code []byte) string {
+	start := declaration.StartPosition().Offset
+	end := declaration.EndPosition(nil).Offset + 1
+
+	switch declaration := declaration.(type) {
+	case *ast.FunctionDeclaration:
+		if declaration.FunctionBlock != nil {
+			end = declaration.FunctionBlock.StartPosition().Offset
+		}
+	case *ast.CompositeDeclaration, *ast.InterfaceDeclaration, *ast.AttachmentDeclaration:
+		if declaration.DeclarationKind() != common.DeclarationKindEvent {
+			if body := bytes.IndexByte(code[start:end], '{'); body >= 0 {
+				end = start + body
+			}
+		}
+	}
+
+	return strings.Join(strings.Fields(string(code[start:end])), " ")
+}
+
+// declarationDoc returns the doc comment of the declaration.
+//
+// The parser does not keep the doc comments of some declarations, e.g. of a declaration
+// following an entitlement, so these are read from the code preceding the declaration.
+func declarationDoc(declaration ast.Declaration, code []byte) string {
+	if doc := declaration.DeclarationDocString(); doc != "" {
+		return cleanDocString(doc)
+	}
+
+	preceding := strings.TrimRight(string(code[:declaration.StartPosition().Offset]), " \t\r\n")
+	if strings.HasSuffix(preceding, "*/") {
+		start := strings.LastIndex(preceding, "/*")
+		if start < 0 || !strings.HasPrefix(preceding[start:], "/**") {
+			return ""
+		}
+		return cleanDocString(strings.TrimSuffix(preceding[start+len("/**"):], "*/"))
+	}
+
+	lines := strings.Split(preceding, "\n")
+	var docLines []string
+	for i := len(lines) - 1; i >= 0; i-- {
+		line := strings.TrimSpace(lines[i])
+		if !strings.HasPrefix(line, "///") {
+			break
+		}
+		docLines = append([]string{strings.TrimPrefix(line, "///")}, docLines...)
+	}
+	return cleanDocString(strings.Join(docLines, "\n"))
+}
+
+// cleanDocString removes the comment markers and indentation of the lines of a doc string.
+func cleanDocString(doc string) string {
+	lines := strings.Split(doc, "\n")
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+		line = strings.TrimPrefix(line, "*")
+		lines[i] = strings.TrimSpace(line)
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// renderDocs returns the content of the docs by file name: for Markdown a page per contract
+// and an index with the import graph, for HTML a single page with all contracts.
+func renderDocs(contracts []*contractDocs, format string) (map[string][]byte, error) {
+	files := make(map[string][]byte)
+	if format == docsFormatHTML {
+		var b bytes.Buffer
+		err := docsHTMLTemplate.Execute(&b, struct {
+			Contracts []*contractDocs
+			Edges     [][2]string
+		}{contracts, importEdges(contracts)})
+		if err != nil {
+			return nil, err
+		}
+		files["index.html"] = b.Bytes()
+		return files, nil
+	}
+
+	projectContracts := make(map[string]bool)
+	for _, contract := range contracts {
+		projectContracts[contract.Name] = true
+	}
+	for _, contract := range contracts {
+		files[contract.Name+".md"] = []byte(contractMarkdown(contract, projectContracts))
+	}
+	files["README.md"] = []byte(indexMarkdown(contracts))
+	return files, nil
+}
+
+// importEdges returns the imports of all contracts as pairs of importing and imported contract.
+func importEdges(contracts []*contractDocs) [][2]string {
+	var edges [][2]string
+	for _, contract := range contracts {
+		for _, imported := range contract.Imports {
+			edges = append(edges, [2]string{contract.Name, imported})
+		}
+	}
+	return edges
+}
+
+func indexMarkdown(contracts []*contractDocs) string {
+	var sb strings.Builder
+	sb.WriteString("# Contracts\n\n")
+	sb.WriteString("| Contract | Source | Description |\n|---|---|---|\n")
+	for _, contract := range contracts {
+		name := fmt.Sprintf("[%s](%s.md)", contract.Name, contract.Name)
+		if contract.Dependency {
+			name += " (dependency)"
+		}
+		summary, _, _ := strings.Cut(contract.Doc, "\n")
+		sb.WriteString(fmt.Sprintf("| %s | `%s` | %s |\n", name, contract.Location, strings.ReplaceAll(summary, "|", `\|`)))
+	}
+
+	edges := importEdges(contracts)
+	if len(edges) > 0 {
+		sb.WriteString("\n## Import graph\n\n```mermaid\ngraph TD\n")
+		for _, edge := range edges {
+			sb.WriteString(fmt.Sprintf("    %s --> %s\n", edge[0], edge[1]))
+		}
+		sb.WriteString("```\n")
+	}
+
+	return sb.String()
+}
+
+func contractMarkdown(contract *contractDocs, projectContracts map[string]bool) string {
+	var sb strings.Builder
+	contractLinks := func(names []string) string {
+		links := make([]string, 0, len(names))
+		for _, name := range names {
+			if projectContracts[name] {
+				links = append(links, fmt.Sprintf("[%s](%s.md)", name, name))
+			} else {
+				links = append(links, fmt.Sprintf("`%s`", name))
+			}
+		}
+		return strings.Join(links, ", ")
+	}
+
+	sb.WriteString(fmt.Sprintf("# %s\n\n", contract.Name))
+	if contract.Doc != "" {
+		sb.WriteString(contract.Doc + "\n\n")
+	}
+	sb.WriteString(fmt.Sprintf("Source: `%s`\n", contract.Location))
+	if len(contract.Imports) > 0 {
+		sb.WriteString(fmt.Sprintf("\nImports: %s\n", contractLinks(contract.Imports)))
+	}
+	if len(contract.ImportedBy) > 0 {
+		sb.WriteString(fmt.Sprintf("\nImported by: %s\n", contractLinks(contract.ImportedBy)))
+	}
+
+	writeTypes := func(title string, types []typeDocs) {
+		if len(types) == 0 {
+			return
+		}
+		sb.WriteString(fmt.Sprintf("\n## %s\n", title))
+		for _, t := range types {
+			sb.WriteString(fmt.Sprintf("\n### %s\n\n```cadence\n%s\n```\n", t.Name, t.Signature))
+			if t.Doc != "" {
+				sb.WriteString("\n" + t.Doc + "\n")
+			}
+			if len(t.Fields) > 0 {
+				sb.WriteString("\n#### Fields\n\n")
+				for _, field := range t.Fields {
+					sb.WriteString(fmt.Sprintf("- `%s`", field.Signature))
+					if field.Doc != "" {
+						sb.WriteString(" - " + strings.ReplaceAll(field.Doc, "\n", " "))
+					}
+					sb.WriteString("\n")
+				}
+			}
+			if len(t.Functions) > 0 {
+				sb.WriteString("\n#### Functions\n")
+				writeMembers(&sb, "#####", t.Functions)
+			}
+		}
+	}
+	writeTypes("Composite types", contract.Composites)
+	writeTypes("Interfaces", contract.Interfaces)
+
+	if len(contract.Events) > 0 {
+		sb.WriteString("\n## Events\n")
+		writeMembers(&sb, "###", contract.Events)
+	}
+	if len(contract.Entitlements) > 0 {
+		sb.WriteString("\n## Entitlements\n")
+		writeMembers(&sb, "###", contract.Entitlements)
+	}
+
+	return sb.String()
+}
+
+func writeMembers(sb *strings.Builder, heading string, members []memberDocs) {
+	for _, member := range members {
+		sb.WriteString(fmt.Sprintf("\n%s %s\n\n```cadence\n%s\n```\n", heading, member.Name, member.Signature))
+		if member.Doc != "" {
+			sb.WriteString("\n" + member.Doc + "\n")
+		}
+	}
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (r *docsResult) String() string {
+	var sb strings.Builder
+	for _, file := range r.Files {
+		sb.WriteString(fmt.Sprintf("%s\n", file))
+	}
+	return sb.String()
+}
+
+func (r *docsResult) Oneliner() string {
+	return fmt.Sprintf(
+		"Generated %s docs for %d %s in %s",
+		r.Format,
+		r.contracts,
+		util.Pluralize("contract", r.contracts),
+		r.Directory,
+	)
+}
+
+func (r *docsResult) JSON() any {
+	return r
+}
+
+// htmlTypeDocs is a type in the HTML reference, whose anchor is qualified by the name of its contract,
+// as the contract itself is a type, and types of different contracts can have the same name.
+type htmlTypeDocs struct {
+	typeDocs
+	Anchor string
+}
+
+func newHTMLTypeDocs(contract string, docs typeDocs) htmlTypeDocs {
+	return htmlTypeDocs{
+		typeDocs: docs,
+		Anchor:   contract + "." + docs.Name,
+	}
+}
+
+var docsHTMLTemplate = template.Must(template.New("docs").Funcs(template.FuncMap{
+	"typeInContract": newHTMLTypeDocs,
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Contracts</title>
+<style>
+body { font-family: sans-serif; margin: 2em; max-width: 60em; }
+table { border-collapse: collapse; margin-bottom: 2em; }
+td, th { padding: 0.25em 1em; text-align: left; border-bottom: 1px solid #ddd; }
+pre { background: #f6f8fa; padding: 0.5em 1em; white-space: pre-wrap; }
+.doc { white-space: pre-line; }
+</style>
+</head>
+<body>
+<h1>Contracts</h1>
+<table>
+<tr><th>Contract</th><th>Source</th><th>Imports</th></tr>
+{{- range .Contracts}}
+<tr><td><a href="#{{.Name}}">{{.Name}}</a>{{if .Dependency}} (dependency){{end}}</td><td><code>{{.Location}}</code></td><td>{{range $i, $name := .Imports}}{{if $i}}, {{end}}{{$name}}{{end}}</td></tr>
+{{- end}}
+</table>
+{{- if .Edges}}
+<h2>Import graph</h2>
+<ul>
+{{- range .Edges}}
+<li>{{index . 0}} &rarr; {{index . 1}}</li>
+{{- end}}
+</ul>
+{{- end}}
+{{- range .Contracts}}
+{{- $contract := .Name}}
+<h1 id="{{.Name}}">{{.Name}}</h1>
+{{- if .Doc}}
+<p class="doc">{{.Doc}}</p>
+{{- end}}
+<p>Source: <code>{{.Location}}</code></p>
+{{- if .ImportedBy}}
+<p>Imported by: {{range $i, $name := .ImportedBy}}{{if $i}}, {{end}}<a href="#{{$name}}">{{$name}}</a>{{end}}</p>
+{{- end}}
+{{- if .Composites}}
+<h2>Composite types</h2>
+{{- range .Composites}}{{template "type" (typeInContract $contract .)}}{{end}}
+{{- end}}
+{{- if .Interfaces}}
+<h2>Interfaces</h2>
+{{- range .Interfaces}}{{template "type" (typeInContract $contract .)}}{{end}}
+{{- end}}
+{{- if .Events}}
+<h2>Events</h2>
+{{- range .Events}}{{template "member" .}}{{end}}
+{{- end}}
+{{- if .Entitlements}}
+<h2>Entitlements</h2>
+{{- range .Entitlements}}{{template "member" .}}{{end}}
+{{- end}}
+{{- end}}
+</body>
+</html>
+{{- define "type"}}
+<h3 id="{{.Anchor}}">{{.Name}}</h3>
+<pre>{{.Signature}}</pre>
+{{- if .Doc}}
+<p class="doc">{{.Doc}}</p>
+{{- end}}
+{{- if .Fields}}
+<h4>Fields</h4>
+<ul>
+{{- range .Fields}}
+<li><code>{{.Signature}}</code>{{if .Doc}} - {{.Doc}}{{end}}</li>
+{{- end}}
+</ul>
+{{- end}}
+{{- if .Functions}}
+<h4>Functions</h4>
+{{- range .Functions}}{{template "member" .}}{{end}}
+{{- end}}
+{{- end}}
+{{- define "member"}}
+<h5>{{.Name}}</h5>
+<pre>{{.Signature}}</pre>
+{{- if .Doc}}
+<p class="doc">{{.Doc}}</p>
+{{- end}}
+{{- end}}
+`))
@@ -0,0 +1,202 @@
+/*
+ * Flow CLI
+ *
+ * Copyright Flow Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cadence
+
+import (
+	"testing"
+
+	"github.com/onflow/flowkit/v2"
+	"github.com/onflow/flowkit/v2/config"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+const documentedContract = `import "FungibleToken"
+
+/// Counter counts the increments of its users.
+access(all) contract Counter {
+
+    access(all) entitlement Increment
+
+    /// Emitted when a counter is incremented
+    access(all) event Incremented(by: Int)
+
+    /// The number of counters created
+    access(all) var total: Int
+
+    access(self) var secret: Int
+
+    /** A counter owned by a user. */
+    access(all) resource Count: Counter.Countable {
+        access(all) var value: Int
+
+        /// Increments the counter and returns the new value.
+        access(Increment) fun increment(by: Int): Int {
+            self.value = self.value + by
+            emit Incremented(by: by)
+            return self.value
+        }
+
+        access(contract) fun reset() {
+            self.value = 0
+        }
+
+        init() {
+            self.value = 0
+        }
+    }
+
+    access(all) resource interface Countable {
+        access(all) var value: Int
+    }
+
+    access(all) enum Kind: UInt8 {
+        case up
+        case down
+    }
+
+    access(all) fun createCount(): @Count {
+        Counter.total = Counter.total + 1
+        return <- create Count()
+    }
+
+    init() {
+        self.total = 0
+        self.secret = 42
+    }
+}
+`
+
+func Test_Docs(t *testing.T) {
+	t.Parallel()
+
+	t.Run("documents public declarations", func(t *testing.T) {
+		t.Parallel()
+
+		docs, err := newContractDocs("Counter", "cadence/contracts/Counter.cdc", []byte(documentedContract))
+		require.NoError(t, err)
+
+		require.Equal(t, "Counter counts the increments of its users.", docs.Doc)
+		require.Equal(t, []string{"FungibleToken"}, docs.Imports)
+
+		require.Len(t, docs.Composites, 3)
+		require.Equal(t, "Counter", docs.Composites[0].Name)
+		require.Equal(t, "access(all) contract Counter", docs.Composites[0].Signature)
+		require.Equal(t, []memberDocs{
+			{Name: "total", Signature: "access(all) var total: Int", Doc: "The number of counters created"},
+		}, docs.Composites[0].Fields)
+		require.Equal(t, []memberDocs{
+			{Name: "createCount", Signature: "access(all) fun createCount(): @Count"},
+		}, docs.Composites[0].Functions)
+
+		require.Equal(t, "Counter.Count", docs.Composites[1].Name)
+		require.Equal(t, "resource", docs.Composites[1].Kind)
+		require.Equal(t, "access(all) resource Count: Counter.Countable", docs.Composites[1].Signature)
+		require.Equal(t, "A counter owned by a user.", docs.Composites[1].Doc)
+		require.Equal(t, []memberDocs{
+			{
+				Name:      "increment",
+				Signature: "access(Increment) fun increment(by: Int): Int",
+				Doc:       "Increments the counter and returns the new value.",
+			},
+		}, docs.Composites[1].Functions)
+
+		require.Equal(t, "Counter.Kind", docs.Composites[2].Name)
+		require.Len(t, docs.Composites[2].Fields, 2)
+
+		require.Len(t, docs.Interfaces, 1)
+		require.Equal(t, "Counter.Countable", docs.Interfaces[0].Name)
+		require.Equal(t, "resource interface", docs.Interfaces[0].Kind)
+
+		require.Equal(t, []memberDocs{
+			{Name: "Counter.Incremented", Signature: "access(all) event Incremented(by: Int)", Doc: "Emitted when a counter is incremented"},
+		}, docs.Events)
+		require.Equal(t, []memberDocs{
+			{Name: "Counter.Increment", Signature: "access(all) entitlement Increment"},
+		}, docs.Entitlements)
+	})
+
+	t.Run("renders markdown with import graph", func(t *testing.T) {
+		t.Parallel()
+
+		counter, err := newContractDocs("Counter", "Counter.cdc", []byte(documentedContract))
+		require.NoError(t, err)
+		token, err := newContractDocs("FungibleToken", "FungibleToken.cdc", []byte("access(all) contract interface FungibleToken {}"))
+		require.NoError(t, err)
+		token.ImportedBy = []string{"Counter"}
+
+		files, err := renderDocs([]*contractDocs{counter, token}, docsFormatMarkdown)
+		require.NoError(t, err)
+		require.Equal(t, []string{"Counter.md", "FungibleToken.md", "README.md"}, sortedKeys(files))
+
+		require.Equal(t,
+			"# Contracts\n\n"+
+				"| Contract | Source | Description |\n|---|---|---|\n"+
+				"| [Counter](Counter.md) | `Counter.cdc` | Counter counts the increments of its users. |\n"+
+				"| [FungibleToken](FungibleToken.md) | `FungibleToken.cdc` |  |\n"+
+				"\n## Import graph\n\n```mermaid\ngraph TD\n    Counter --> FungibleToken\n```\n",
+			string(files["README.md"]),
+		)
+		require.Contains(t, string(files["Counter.md"]), "Imports: [FungibleToken](FungibleToken.md)\n")
+		require.Contains(t, string(files["Counter.md"]), "\n### Counter.Count\n\n```cadence\naccess(all) resource Count: Counter.Countable\n```\n\nA counter owned by a user.\n")
+		require.Contains(t, string(files["Counter.md"]), "- `access(all) var total: Int` - The number of counters created\n")
+		require.NotContains(t, string(files["Counter.md"]), "secret")
+		require.NotContains(t, string(files["Counter.md"]), "reset")
+		require.Contains(t, string(files["FungibleToken.md"]), "Imported by: [Counter](Counter.md)\n")
+	})
+
+	t.Run("renders html", func(t *testing.T) {
+		t.Parallel()
+
+		counter, err := newContractDocs("Counter", "Counter.cdc", []byte(documentedContract))
+		require.NoError(t, err)
+
+		files, err := renderDocs([]*contractDocs{counter}, docsFormatHTML)
+		require.NoError(t, err)
+		require.Equal(t, []string{"index.html"}, sortedKeys(files))
+		require.Contains(t, string(files["index.html"]), `<h1 id="Counter">Counter</h1>`)
+		require.Contains(t, string(files["index.html"]), `<h3 id="Counter.Counter">Counter</h3>`)
+		require.Contains(t, string(files["index.html"]), `<h3 id="Counter.Counter.Count">Counter.Count</h3>`)
+		require.Contains(t, string(files["index.html"]), "<li>Counter &rarr; FungibleToken</li>")
+		require.Contains(t, string(files["index.html"]), "<pre>access(all) fun createCount(): @Count</pre>")
+	})
+
+	t.Run("loads contracts of the project", func(t *testing.T) {
+		t.Parallel()
+
+		rw := afero.Afero{Fs: afero.NewMemMapFs()}
+		state, err := flowkit.Init(rw)
+		require.NoError(t, err)
+
+		require.NoError(t, rw.WriteFile("Counter.cdc", []byte(documentedContract), 0644))
+		require.NoError(t, rw.WriteFile("FungibleToken.cdc", []byte("access(all) contract interface FungibleToken {}"), 0644))
+		state.Contracts().AddOrUpdate(config.Contract{Name: "FungibleToken", Location: "FungibleToken.cdc"})
+		state.Contracts().AddOrUpdate(config.Contract{Name: "Counter", Location: "Counter.cdc"})
+
+		contracts, err := loadContractDocs(state)
+		require.NoError(t, err)
+		require.Len(t, contracts, 2)
+		require.Equal(t, "Counter", contracts[0].Name)
+		require.Equal(t, []string{"Counter"}, contracts[1].ImportedBy)
+
+		state.Contracts().AddOrUpdate(config.Contract{Name: "Missing", Location: "Missing.cdc"})
+		_, err = loadContractDocs(state)
+		require.ErrorContains(t, err, "failed to read contract Missing")
+	})
+}
@@ -0,0 +1,427 @@
+/*
+ * Flow CLI
+ *
+ * Copyright Flow Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cadence
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/exp/slices"
+
+	"github.com/logrusorgru/aurora/v4"
+	"github.com/spf13/cobra"
+	"github.com/turbolent/prettier"
+
+	"github.com/onflow/cadence/parser"
+	"github.com/onflow/flowkit/v2"
+	"github.com/onflow/flowkit/v2/output"
+
+	"github.com/onflow/flow-cli/internal/command"
+	"github.com/onflow/flow-cli/internal/util"
+)
+
+type fmtFlagsCollection struct {
+	Check   bool   `default:"false" flag:"check" info:"Fail if any file is not formatted, without changing the files"`
+	Diff    bool   `default:"false" flag:"diff" info:"Print the changes needed to format the files as a diff"`
+	Write   bool   `default:"false" flag:"write" info:"Write the formatted code to the files"`
+	BaseDir string `default:"" flag:"base-dir" info:"Directory to search for .cdc files (defaults to current directory)"`
+}
+
+var fmtFlags = fmtFlagsCollection{}
+
+var fmtCommand = &command.Command{
+	Cmd: &cobra.Command{
+		Use:   "fmt [files...]",
+		Short: "Format Cadence code",
+		Example: `# List the .cdc files in the project which are not formatted
+flow cadence fmt
+
+# Format specific files
+flow cadence fmt --write file1.cdc file2.cdc
+
+# Fail if any file is not formatted, e.g. in CI, and print the changes needed
+flow cadence fmt --check --diff
+
+# Formatting is configured in the "format" section of flow.json:
+#   {"lineWidth": 100, "indent": 2, "exclude": ["imports/**"]}`,
+		Args: cobra.ArbitraryArgs,
+	},
+	Flags: &fmtFlags,
+	RunS:  formatCommand,
+}
+
+// Default settings of the formatter, the same as used by the Cadence pretty-printer.
+const (
+	defaultFormatLineWidth = 80
+	defaultFormatIndent    = 4
+)
+
+// formatConfig is the format section of flow.json, e.g.:
+//
+//	{
+//	  "lineWidth": 100,
+//	  "indent": 2,
+//	  "exclude": ["imports/**"]
+//	}
+type formatConfig struct {
+	// LineWidth is the width lines are wrapped at, if possible.
+	LineWidth int `json:"lineWidth,omitempty"`
+	// Indent is the number of spaces of each indentation level.
+	Indent int `json:"indent,omitempty"`
+	// UseTabs indents with a tab instead of spaces.
+	UseTabs bool `json:"useTabs,omitempty"`
+	// Exclude holds the patterns of files that are not formatted, see isExcludedBy.
+	Exclude []string `json:"exclude,omitempty"`
+}
+
+// loadFormatConfig reads the format section of the given configuration files,
+// where later files take precedence.
+func loadFormatConfig(state *flowkit.State, configPaths []string) (*formatConfig, error) {
+	config := &formatConfig{}
+
+	for _, configPath := range configPaths {
+		data, err := state.ReadFile(configPath)
+		if err != nil {
+			continue
+		}
+
+		var section struct {
+			Format *formatConfig `json:"format"`
+		}
+		err = json.Unmarshal(data, &section)
+		if err != nil {
+			return nil, fmt.Errorf("invalid format configuration in %s: %w", configPath, err)
+		}
+		if section.Format != nil {
+			config = section.Format
+		}
+	}
+
+	err := config.validate()
+	if err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+func (c *formatConfig) validate() error {
+	if c.LineWidth < 0 {
+		return fmt.Errorf("invalid format line width %d, expected a positive number", c.LineWidth)
+	}
+	if c.Indent < 0 {
+		return fmt.Errorf("invalid format indent %d, expected a positive number", c.Indent)
+	}
+	if c.UseTabs && c.Indent != 0 {
+		return fmt.Errorf("format indent can not be set together with useTabs")
+	}
+
+	return validateExcludePatterns("format", c.Exclude)
+}
+
+func (c *formatConfig) lineWidth() int {
+	if c.LineWidth == 0 {
+		return defaultFormatLineWidth
+	}
+	return c.LineWidth
+}
+
+func (c *formatConfig) indent() string {
+	if c.UseTabs {
+		return "\t"
+	}
+	if c.Indent == 0 {
+		return strings.Repeat(" ", defaultFormatIndent)
+	}
+	return strings.Repeat(" ", c.Indent)
+}
+
+// errCommentsNotPreserved is returned for code with comments which could not be put back
+// into the formatted code, as the AST does not hold them, so the code is left as it is.
+var errCommentsNotPreserved = errors.New("contains comments which can not be preserved by the formatter")
+
+// formatCode returns the code printed from its AST, with the comments of the code put back.
+func formatCode(code []byte, config *formatConfig) ([]byte, error) {
+	program, err := parser.ParseProgram(nil, code, parser.Config{})
+	if err != nil {
+		return nil, err
+	}
+
+	comments, err := codeComments(code)
+	if err != nil {
+		return nil, err
+	}
+
+	var sb strings.Builder
+	prettier.Prettier(&sb, program.Doc(), config.lineWidth(), config.indent())
+
+	// The pretty-printer indents empty lines and omits the final newline
+	lines := strings.Split(sb.String(), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t")
+	}
+	formatted := []byte(strings.TrimRight(strings.Join(lines, "\n"), "\n") + "\n")
+
+	// Never write code that can not be parsed anymore
+	formattedProgram, err := parser.ParseProgram(nil, formatted, parser.Config{})
+	if err != nil {
+		return nil, fmt.Errorf("formatting produced invalid code: %w", err)
+	}
+
+	if len(comments) == 0 {
+		return formatted, nil
+	}
+
+	formatted, ok := insertComments(program, comments, formattedProgram, formatted, config.indent())
+	if !ok {
+		return nil, errCommentsNotPreserved
+	}
+
+	// Never lose or move a comment into code
+	_, err = parser.ParseProgram(nil, formatted, parser.Config{})
+	if err != nil {
+		return nil, errCommentsNotPreserved
+	}
+	preserved, err := codeComments(formatted)
+	if err != nil {
+		return nil, errCommentsNotPreserved
+	}
+	if !equalComments(comments, preserved) {
+		return nil, errCommentsNotPreserved
+	}
+
+	return formatted, nil
+}
+
+type fmtFileResult struct {
+	FilePath string `json:"filePath"`
+	// Formatted is true if the code of the file is formatted already.
+	Formatted bool `json:"formatted"`
+	// Skipped holds the reason the file could not be formatted, if any.
+	Skipped string `json:"skipped,omitempty"`
+	// Error holds the error formatting the file, e.g. a syntax error, if any.
+	Error string `json:"error,omitempty"`
+
+	code      []byte
+	formatted []byte
+}
+
+type fmtResult struct {
+	Files []fmtFileResult `json:"files"`
+	check bool
+	write bool
+	// diff holds the changes of all files as a unified diff, if requested.
+	diff string
+}
+
+var _ command.ResultWithExitCode = &fmtResult{}
+
+func formatCommand(
+	args []string,
+	globalFlags command.GlobalFlags,
+	logger output.Logger,
+	_ flowkit.Services,
+	state *flowkit.State,
+) (command.Result, error) {
+	if fmtFlags.Check && fmtFlags.Write {
+		return nil, fmt.Errorf("--check and --write can not be used together")
+	}
+
+	config, err := loadFormatConfig(state, globalFlags.ConfigPaths)
+	if err != nil {
+		return nil, err
+	}
+
+	var filePaths []string
+	if len(args) == 0 {
+		baseDir := "."
+		if fmtFlags.BaseDir != "" {
+			baseDir = fmtFlags.BaseDir
+		}
+		filePaths, err = findAllCadenceFiles(baseDir)
+		if err != nil {
+			return nil, fmt.Errorf("error finding Cadence files: %w", err)
+		}
+		if len(filePaths) == 0 {
+			return nil, fmt.Errorf("no .cdc files found in the project")
+		}
+	} else {
+		filePaths = args
+	}
+
+	filePaths = slices.DeleteFunc(filePaths, func(filePath string) bool {
+		return isExcludedBy(config.Exclude, filePath)
+	})
+	if len(filePaths) == 0 {
+		return nil, fmt.Errorf("all .cdc files are excluded by the format configuration")
+	}
+
+	result, err := formatFiles(state, config, filePaths...)
+	if err != nil {
+		return nil, err
+	}
+	result.check = fmtFlags.Check
+
+	if fmtFlags.Diff {
+		result.diff = result.changesDiff()
+	}
+
+	if fmtFlags.Write {
+		err = result.writeFiles(state, logger)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// formatFiles formats the code of the files, without writing them.
+func formatFiles(state *flowkit.State, config *formatConfig, filePaths ...string) (*fmtResult, error) {
+	result := &fmtResult{
+		Files: make([]fmtFileResult, 0, len(filePaths)),
+	}
+
+	for _, filePath := range filePaths {
+		code, err := state.ReadFile(filePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", filePath, err)
+		}
+
+		fileResult := fmtFileResult{
+			FilePath: filePath,
+			code:     code,
+		}
+
+		formatted, err := formatCode(code, config)
+		switch {
+		case errors.Is(err, errCommentsNotPreserved):
+			fileResult.Skipped = err.Error()
+		case err != nil:
+			fileResult.Error = err.Error()
+		default:
+			fileResult.formatted = formatted
+			fileResult.Formatted = bytes.Equal(code, formatted)
+		}
+
+		result.Files = append(result.Files, fileResult)
+	}
+
+	return result, nil
+}
+
+// unformatted returns the files whose formatted code differs from their code.
+func (r *fmtResult) unformatted() []fmtFileResult {
+	var unformatted []fmtFileResult
+	for _, file := range r.Files {
+		if file.formatted != nil && !file.Formatted {
+			unformatted = append(unformatted, file)
+		}
+	}
+	return unformatted
+}
+
+func (r *fmtResult) changesDiff() string {
+	var sb strings.Builder
+	for _, file := range r.unformatted() {
+		sb.WriteString(util.UnifiedDiff(filepath.ToSlash(file.FilePath), string(file.code), string(file.formatted)))
+	}
+	return sb.String()
+}
+
+func (r *fmtResult) writeFiles(state *flowkit.State, logger output.Logger) error {
+	unformatted := r.unformatted()
+	for _, file := range unformatted {
+		err := state.ReaderWriter().WriteFile(file.FilePath, file.formatted, 0644)
+		if err != nil {
+			return fmt.Errorf("failed to write %s: %w", file.FilePath, err)
+		}
+	}
+	r.write = true
+
+	logger.Info(fmt.Sprintf(
+		"%s Formatted %d %s",
+		output.SuccessEmoji(),
+		len(unformatted),
+		util.Pluralize("file", len(unformatted)),
+	))
+
+	return nil
+}
+
+func (r *fmtResult) String() string {
+	var sb strings.Builder
+
+	if r.diff != "" {
+		sb.WriteString(fmt.Sprintf("%s\n", r.diff))
+	}
+
+	for _, file := range r.Files {
+		switch {
+		case file.Error != "":
+			sb.WriteString(fmt.Sprintf("%s %s\n", aurora.Red(file.FilePath+":").String(), file.Error))
+		case file.Skipped != "":
+			sb.WriteString(fmt.Sprintf("%s skipped, %s\n", aurora.Yellow(file.FilePath+":").String(), file.Skipped))
+		case !file.Formatted && !r.write:
+			sb.WriteString(fmt.Sprintf("%s\n", file.FilePath))
+		}
+	}
+
+	if r.write || len(r.unformatted()) == 0 {
+		sb.WriteString(aurora.Green(r.Oneliner()).String())
+	} else {
+		sb.WriteString(aurora.Red(r.Oneliner()).String())
+	}
+
+	return sb.String()
+}
+
+func (r *fmtResult) Oneliner() string {
+	unformatted := len(r.unformatted())
+	if r.write || unformatted == 0 {
+		return "All files are formatted"
+	}
+
+	return fmt.Sprintf(
+		"%d %s not formatted, run with --write to format them",
+		unformatted,
+		util.Pluralize("file", unformatted),
+	)
+}
+
+func (r *fmtResult) JSON() any {
+	return r
+}
+
+// ExitCode is 1 if any file could not be formatted, or is not formatted in check mode.
+func (r *fmtResult) ExitCode() int {
+	for _, file := range r.Files {
+		if file.Error != "" {
+			return 1
+		}
+	}
+	if r.check && len(r.unformatted()) > 0 {
+		return 1
+	}
+	return 0
+}
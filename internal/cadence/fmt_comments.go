@@ -0,0 +1,310 @@
+/*
+ * Flow CLI
+ *
+ * Copyright Flow Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cadence
+
+import (
+	"strings"
+
+	"github.com/onflow/cadence/ast"
+	"github.com/onflow/cadence/parser/lexer"
+)
+
+// codeComment is a comment of the code, including doc comments.
+type codeComment struct {
+	text  string
+	start ast.Position
+	end   ast.Position
+	// ownLine is true if there is no code before the comment on its line.
+	ownLine bool
+	// blankLineAfter is true if the comment is followed by an empty line.
+	blankLineAfter bool
+}
+
+// codeComments returns the comments of the code, in the order they appear.
+func codeComments(code []byte) ([]codeComment, error) {
+	tokens, err := lexer.Lex(code, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tokens.Reclaim()
+
+	var comments []codeComment
+	var blockStart ast.Position
+	nesting := 0
+
+	for {
+		token := tokens.Next()
+		switch token.Type {
+		case lexer.TokenEOF:
+			return comments, nil
+		case lexer.TokenLineComment:
+			comments = append(comments, newCodeComment(code, token.StartPos, token.EndPos))
+		case lexer.TokenBlockCommentStart:
+			if nesting == 0 {
+				blockStart = token.StartPos
+			}
+			nesting++
+		case lexer.TokenBlockCommentEnd:
+			nesting--
+			if nesting == 0 {
+				comments = append(comments, newCodeComment(code, blockStart, token.EndPos))
+			}
+		}
+	}
+}
+
+func newCodeComment(code []byte, start ast.Position, end ast.Position) codeComment {
+	lineStart := strings.LastIndexByte(string(code[:start.Offset]), '\n') + 1
+
+	after := strings.TrimLeft(string(code[end.Offset+1:]), " \t\r")
+	if strings.HasPrefix(after, "\n") {
+		after = strings.TrimLeft(after[1:], " \t\r")
+	} else {
+		after = ""
+	}
+
+	// The trailing whitespace of lines is removed by the formatter
+	lines := strings.Split(string(code[start.Offset:end.Offset+1]), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t\r")
+	}
+
+	return codeComment{
+		text:           strings.Join(lines, "\n"),
+		start:          start,
+		end:            end,
+		ownLine:        strings.TrimSpace(string(code[lineStart:start.Offset])) == "",
+		blankLineAfter: strings.HasPrefix(after, "\n"),
+	}
+}
+
+// commentAnchor is an element of a program comments are placed relative to.
+type commentAnchor struct {
+	element ast.Element
+	start   ast.Position
+	end     ast.Position
+	// head is the start of the line opening the element, i.e. its identifier for declarations,
+	// which are printed with their access modifier on a separate line.
+	head ast.Position
+}
+
+// commentAnchors returns the statements, declarations and blocks of the program, in pre-order.
+func commentAnchors(program *ast.Program) []commentAnchor {
+	var anchors []commentAnchor
+
+	var walk func(ast.Element)
+	walk = func(element ast.Element) {
+		switch element.(type) {
+		case ast.Statement, ast.Declaration, *ast.Block, *ast.FunctionBlock:
+			anchor := commentAnchor{
+				element: element,
+				start:   element.StartPosition(),
+				end:     element.EndPosition(nil),
+			}
+			anchor.head = anchor.start
+			if declaration, ok := element.(ast.Declaration); ok {
+				identifier := declaration.DeclarationIdentifier()
+				if identifier != nil && identifier.Identifier != "" {
+					anchor.head = identifier.Pos
+				}
+			}
+			anchors = append(anchors, anchor)
+		}
+		element.Walk(walk)
+	}
+	program.Walk(walk)
+
+	return anchors
+}
+
+// insertComments puts the comments of the code back into its formatted code.
+//
+// The formatted program has the same elements as the program of the code,
+// so comments are placed relative to the element they precede or follow in the code:
+// a comment on its own line is inserted before the next element in the same block,
+// or before the end of the block, and a comment after code is appended to the line
+// the code before it ends on.
+func insertComments(
+	program *ast.Program,
+	comments []codeComment,
+	formattedProgram *ast.Program,
+	formatted []byte,
+	indent string,
+) ([]byte, bool) {
+	anchors := commentAnchors(program)
+	formattedAnchors := commentAnchors(formattedProgram)
+	if len(anchors) != len(formattedAnchors) {
+		return nil, false
+	}
+	for i := range anchors {
+		if anchors[i].element.ElementType() != formattedAnchors[i].element.ElementType() {
+			return nil, false
+		}
+	}
+
+	lines := strings.Split(strings.TrimSuffix(string(formatted), "\n"), "\n")
+	before := make(map[int][]string)
+	appended := make(map[int]string)
+	var atEnd []string
+
+	insertBefore := func(line int, indentation string, comment codeComment) {
+		commentLines := strings.Split(comment.text, "\n")
+		for i, commentLine := range commentLines {
+			if i > 0 {
+				// Continuation lines of block comments keep their indentation relative to the comment
+				commentLine = commentLine[min(len(leadingWhitespace(commentLine)), comment.start.Column):]
+			}
+			commentLines[i] = indentation + commentLine
+		}
+		if comment.blankLineAfter {
+			commentLines = append(commentLines, "")
+		}
+		before[line] = append(before[line], commentLines...)
+	}
+
+	for _, comment := range comments {
+		if !comment.ownLine {
+			if line := trailingCommentLine(anchors, formattedAnchors, comment); line > 0 {
+				appended[line] += " " + comment.text
+				continue
+			}
+		}
+
+		container := containingAnchor(anchors, comment)
+		if i := nextAnchor(anchors, comment, container); i >= 0 {
+			line := formattedAnchors[i].start.Line
+			insertBefore(line, leadingWhitespace(lines[line-1]), comment)
+			continue
+		}
+
+		if container < 0 {
+			atEnd = append(atEnd, comment.text)
+			continue
+		}
+
+		formattedContainer := formattedAnchors[container]
+		if formattedContainer.start.Line == formattedContainer.end.Line {
+			line := formattedContainer.start.Line
+			insertBefore(line, leadingWhitespace(lines[line-1]), comment)
+		} else {
+			line := formattedContainer.end.Line
+			insertBefore(line, leadingWhitespace(lines[line-1])+indent, comment)
+		}
+	}
+
+	var sb strings.Builder
+	for i, line := range lines {
+		for _, commentLine := range before[i+1] {
+			sb.WriteString(strings.TrimRight(commentLine, " \t"))
+			sb.WriteString("\n")
+		}
+		sb.WriteString(line)
+		sb.WriteString(appended[i+1])
+		sb.WriteString("\n")
+	}
+	for _, comment := range atEnd {
+		sb.WriteString(comment)
+		sb.WriteString("\n")
+	}
+
+	return []byte(strings.TrimLeft(sb.String(), "\n")), true
+}
+
+// trailingCommentLine returns the line of the formatted code a comment after code is appended to:
+// the line the outermost anchor ending last before the comment ends on, or else the line
+// opening the innermost anchor starting before it, e.g. a block or declaration.
+// It returns 0 if there is no such anchor.
+func trailingCommentLine(anchors []commentAnchor, formattedAnchors []commentAnchor, comment codeComment) int {
+	ending := -1
+	for i, anchor := range anchors {
+		if anchor.end.Line == comment.start.Line &&
+			anchor.end.Offset < comment.start.Offset &&
+			(ending < 0 || anchor.end.Offset > anchors[ending].end.Offset) {
+			ending = i
+		}
+	}
+	if ending >= 0 {
+		return formattedAnchors[ending].end.Line
+	}
+
+	starting := -1
+	for i, anchor := range anchors {
+		if anchor.head.Line == comment.start.Line &&
+			anchor.head.Offset < comment.start.Offset &&
+			anchor.end.Offset > comment.end.Offset {
+			starting = i
+		}
+	}
+	if starting >= 0 {
+		return formattedAnchors[starting].head.Line
+	}
+
+	return 0
+}
+
+// containingAnchor returns the index of the innermost anchor containing the comment,
+// or -1 if the comment is at the top level of the program.
+func containingAnchor(anchors []commentAnchor, comment codeComment) int {
+	result := -1
+	for i, anchor := range anchors {
+		if anchor.start.Offset < comment.start.Offset && anchor.end.Offset > comment.end.Offset {
+			result = i
+		}
+	}
+	return result
+}
+
+// nextAnchor returns the index of the first anchor after the comment within the container,
+// or -1 if there is none.
+func nextAnchor(anchors []commentAnchor, comment codeComment, container int) int {
+	for i, anchor := range anchors {
+		if anchor.start.Offset <= comment.end.Offset {
+			continue
+		}
+		if container >= 0 && anchor.start.Offset > anchors[container].end.Offset {
+			return -1
+		}
+		return i
+	}
+	return -1
+}
+
+func leadingWhitespace(line string) string {
+	return line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+}
+
+// equalComments returns true if the comments have the same text, ignoring the indentation of their lines.
+func equalComments(comments []codeComment, others []codeComment) bool {
+	if len(comments) != len(others) {
+		return false
+	}
+	for i := range comments {
+		lines := strings.Split(comments[i].text, "\n")
+		otherLines := strings.Split(others[i].text, "\n")
+		if len(lines) != len(otherLines) {
+			return false
+		}
+		for j := range lines {
+			if strings.TrimLeft(lines[j], " \t") != strings.TrimLeft(otherLines[j], " \t") {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,265 @@
+/*
+ * Flow CLI
+ *
+ * Copyright Flow Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cadence
+
+import (
+	"testing"
+
+	"github.com/onflow/flowkit/v2/output"
+	"github.com/stretchr/testify/require"
+)
+
+const unformattedCode = `access(all)   contract Foo {
+  access(all) fun bar(a: Int): Int { if a > 1 { return a*2 }
+    return 0 }
+  init() {}
+}
+`
+
+const formattedCode = `access(all)
+contract Foo {
+    access(all)
+    fun bar(a: Int): Int {
+        if a > 1 {
+            return a * 2
+        }
+        return 0
+    }
+
+    init() {}
+}
+`
+
+func Test_Format(t *testing.T) {
+	t.Parallel()
+
+	t.Run("formats code", func(t *testing.T) {
+		t.Parallel()
+
+		formatted, err := formatCode([]byte(unformattedCode), &formatConfig{})
+		require.NoError(t, err)
+		require.Equal(t, formattedCode, string(formatted))
+
+		formattedAgain, err := formatCode(formatted, &formatConfig{})
+		require.NoError(t, err)
+		require.Equal(t, formattedCode, string(formattedAgain))
+	})
+
+	t.Run("uses configured indentation", func(t *testing.T) {
+		t.Parallel()
+
+		code := []byte("transaction { execute { log(1) } }")
+
+		formatted, err := formatCode(code, &formatConfig{Indent: 2})
+		require.NoError(t, err)
+		require.Equal(t, "transaction {\n  execute {\n    log(1)\n  }\n}\n", string(formatted))
+
+		formatted, err = formatCode(code, &formatConfig{UseTabs: true})
+		require.NoError(t, err)
+		require.Equal(t, "transaction {\n\texecute {\n\t\tlog(1)\n\t}\n}\n", string(formatted))
+	})
+
+	t.Run("keeps comments", func(t *testing.T) {
+		t.Parallel()
+
+		code := `/*
+ * License
+ */
+
+import "Bar"
+
+/// Foo is a contract.
+access(all)   contract Foo {
+  // The total
+  access(all) var total: Int // in tokens
+
+  /** Doubles a.
+      Returns 0 otherwise */
+  access(all) fun bar(a: Int): Int { // start
+    // check
+    if a > 1 { return a*2 }
+    return 0
+    // unreachable
+  }
+
+  init() { self.total = 0 }
+}
+// end
+`
+
+		expected := `/*
+ * License
+ */
+
+import "Bar"
+
+/// Foo is a contract.
+access(all)
+contract Foo {
+    // The total
+    access(all)
+    var total: Int // in tokens
+
+    /** Doubles a.
+        Returns 0 otherwise */
+    access(all)
+    fun bar(a: Int): Int { // start
+        // check
+        if a > 1 {
+            return a * 2
+        }
+        return 0
+        // unreachable
+    }
+
+    init() {
+        self.total = 0
+    }
+}
+// end
+`
+
+		formatted, err := formatCode([]byte(code), &formatConfig{})
+		require.NoError(t, err)
+		require.Equal(t, expected, string(formatted))
+
+		formattedAgain, err := formatCode(formatted, &formatConfig{})
+		require.NoError(t, err)
+		require.Equal(t, expected, string(formattedAgain))
+
+		formatted, err = formatCode([]byte("access(all) contract Foo { /* bar */ }"), &formatConfig{})
+		require.NoError(t, err)
+		require.Equal(t, "access(all)\ncontract Foo {} /* bar */\n", string(formatted))
+	})
+
+	t.Run("reports unformatted files in check mode", func(t *testing.T) {
+		t.Parallel()
+
+		state := setupMockState(t)
+		require.NoError(t, state.ReaderWriter().WriteFile("Foo.cdc", []byte(unformattedCode), 0644))
+		require.NoError(t, state.ReaderWriter().WriteFile("Bar.cdc", []byte(formattedCode), 0644))
+		require.NoError(t, state.ReaderWriter().WriteFile("Comments.cdc", []byte("// Baz\n"), 0644))
+		require.NoError(t, state.ReaderWriter().WriteFile("Invalid.cdc", []byte("access(all) fun"), 0644))
+
+		result, err := formatFiles(state, &formatConfig{}, "Foo.cdc", "Bar.cdc", "Comments.cdc")
+		require.NoError(t, err)
+		require.Equal(t, 0, result.ExitCode())
+		require.Equal(t, "1 file not formatted, run with --write to format them", result.Oneliner())
+
+		result.check = true
+		require.Equal(t, 1, result.ExitCode())
+		require.False(t, result.Files[0].Formatted)
+		require.True(t, result.Files[1].Formatted)
+		require.True(t, result.Files[2].Formatted)
+
+		require.Equal(t,
+			"--- a/Foo.cdc\n+++ b/Foo.cdc\n"+
+				"@@ -1,5 +1,12 @@\n"+
+				"-access(all)   contract Foo {\n"+
+				"-  access(all) fun bar(a: Int): Int { if a > 1 { return a*2 }\n"+
+				"-    return 0 }\n"+
+				"-  init() {}\n"+
+				"+access(all)\n"+
+				"+contract Foo {\n"+
+				"+    access(all)\n"+
+				"+    fun bar(a: Int): Int {\n"+
+				"+        if a > 1 {\n"+
+				"+            return a * 2\n"+
+				"+        }\n"+
+				"+        return 0\n"+
+				"+    }\n"+
+				"+\n"+
+				"+    init() {}\n"+
+				" }\n",
+			result.changesDiff(),
+		)
+
+		result, err = formatFiles(state, &formatConfig{}, "Bar.cdc", "Invalid.cdc")
+		require.NoError(t, err)
+		require.NotEmpty(t, result.Files[1].Error)
+		require.Equal(t, 1, result.ExitCode())
+	})
+
+	t.Run("writes formatted files", func(t *testing.T) {
+		t.Parallel()
+
+		state := setupMockState(t)
+		require.NoError(t, state.ReaderWriter().WriteFile("Foo.cdc", []byte(unformattedCode), 0644))
+
+		result, err := formatFiles(state, &formatConfig{}, "Foo.cdc")
+		require.NoError(t, err)
+
+		err = result.writeFiles(state, output.NewStdoutLogger(output.NoneLog))
+		require.NoError(t, err)
+		require.Equal(t, 0, result.ExitCode())
+		require.Equal(t, "All files are formatted", result.Oneliner())
+
+		code, err := state.ReadFile("Foo.cdc")
+		require.NoError(t, err)
+		require.Equal(t, formattedCode, string(code))
+	})
+}
+
+func Test_FormatConfig(t *testing.T) {
+	t.Parallel()
+
+	t.Run("loads format section of flow.json", func(t *testing.T) {
+		t.Parallel()
+
+		state := setupMockState(t)
+		require.NoError(t, state.ReaderWriter().WriteFile("flow.json", []byte(`{
+			"format": {"lineWidth": 100, "indent": 2, "exclude": ["imports/**"]}
+		}`), 0644))
+
+		config, err := loadFormatConfig(state, []string{"flow.json"})
+		require.NoError(t, err)
+		require.Equal(t, &formatConfig{LineWidth: 100, Indent: 2, Exclude: []string{"imports/**"}}, config)
+		require.Equal(t, 100, config.lineWidth())
+		require.Equal(t, "  ", config.indent())
+		require.True(t, isExcludedBy(config.Exclude, "imports/0x1/Foo.cdc"))
+	})
+
+	t.Run("defaults without configuration", func(t *testing.T) {
+		t.Parallel()
+
+		state := setupMockState(t)
+		require.NoError(t, state.ReaderWriter().WriteFile("flow.json", []byte(`{}`), 0644))
+
+		config, err := loadFormatConfig(state, []string{"flow.json"})
+		require.NoError(t, err)
+		require.Equal(t, defaultFormatLineWidth, config.lineWidth())
+		require.Equal(t, "    ", config.indent())
+	})
+
+	t.Run("rejects invalid configuration", func(t *testing.T) {
+		t.Parallel()
+
+		for format, expectedErr := range map[string]string{
+			`{"lineWidth": -1}`:              "invalid format line width -1",
+			`{"indent": 2, "useTabs": true}`: "format indent can not be set together with useTabs",
+			`{"exclude": ["["]}`:             `invalid format exclude pattern "["`,
+		} {
+			state := setupMockState(t)
+			require.NoError(t, state.ReaderWriter().WriteFile("flow.json", []byte(`{"format": `+format+`}`), 0644))
+
+			_, err := loadFormatConfig(state, []string{"flow.json"})
+			require.ErrorContains(t, err, expectedErr, format)
+		}
+	})
+}
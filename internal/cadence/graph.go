@@ -0,0 +1,545 @@
+/*
+ * Flow CLI
+ *
+ * Copyright Flow Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cadence
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/onflow/cadence/common"
+	"github.com/onflow/cadence/parser"
+	"github.com/onflow/flowkit/v2"
+	"github.com/onflow/flowkit/v2/config"
+	"github.com/onflow/flowkit/v2/output"
+
+	"github.com/onflow/flow-cli/internal/command"
+	"github.com/onflow/flow-cli/internal/dependencymanager"
+	"github.com/onflow/flow-cli/internal/util"
+)
+
+const (
+	graphFormatDOT     = "dot"
+	graphFormatMermaid = "mermaid"
+	graphFormatJSON    = "json"
+)
+
+const (
+	graphNodeContract   = "contract"
+	graphNodeDependency = "dependency"
+	// graphNodeUnresolved is an imported contract which is neither a contract nor a dependency of the project
+	graphNodeUnresolved = "unresolved"
+)
+
+type graphFlagsCollection struct {
+	GraphFormat string `default:"dot" flag:"graph-format" info:"Format of the graph: 'dot', 'mermaid' or 'json'"`
+}
+
+var graphFlags = graphFlagsCollection{}
+
+var graphCommand = &command.Command{
+	Cmd: &cobra.Command{
+		Use:   "graph [<directory>...]",
+		Short: "Show the import graph of the contracts and dependencies of the project",
+		Long: `Show the import graph of the contracts and dependencies of the project.
+
+Import cycles, contracts that nothing imports and dependencies that are never imported are reported.
+Scripts, transactions and tests in the directories (default: cadence) are not part of the graph,
+but their imports count as usages of contracts and dependencies.`,
+		Example: `# Render the graph with Graphviz
+flow cadence graph | dot -Tsvg > imports.svg
+
+# Show the graph as a Mermaid diagram
+flow cadence graph --graph-format mermaid`,
+		Args: cobra.ArbitraryArgs,
+	},
+	Flags: &graphFlags,
+	RunS:  showGraph,
+}
+
+// graphSource is a Cadence file whose imports are part of the graph.
+type graphSource struct {
+	// Name is the name of the contract, or empty for scripts, transactions and tests
+	Name     string
+	Kind     string
+	Location string
+	Code     []byte
+}
+
+type graphNode struct {
+	Name     string `json:"name"`
+	Kind     string `json:"kind"`
+	Location string `json:"location,omitempty"`
+}
+
+type graphEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+type importGraph struct {
+	Nodes []graphNode `json:"nodes"`
+	Edges []graphEdge `json:"edges"`
+	// Cycles are the sets of contracts which import each other
+	Cycles [][]string `json:"cycles"`
+	// Unimported are the contracts of the project that nothing imports
+	Unimported []string `json:"unimportedContracts"`
+	// UnusedDependencies are the dependencies of flow.json that nothing imports
+	UnusedDependencies []string `json:"unusedDependencies"`
+	// Errors are the files which could not be read or parsed
+	Errors []string `json:"errors,omitempty"`
+	format string
+}
+
+var _ command.ResultWithExitCode = &importGraph{}
+
+func showGraph(
+	args []string,
+	_ command.GlobalFlags,
+	_ output.Logger,
+	_ flowkit.Services,
+	state *flowkit.State,
+) (command.Result, error) {
+	switch graphFlags.GraphFormat {
+	case graphFormatDOT, graphFormatMermaid, graphFormatJSON:
+	default:
+		return nil, fmt.Errorf(
+			"unsupported format %q, expected %q, %q or %q",
+			graphFlags.GraphFormat, graphFormatDOT, graphFormatMermaid, graphFormatJSON,
+		)
+	}
+
+	directories := args
+	if len(directories) == 0 {
+		directories = []string{"cadence"}
+	}
+
+	sources, errs := loadGraphSources(state, directories)
+	graph := newImportGraph(sources)
+	graph.Errors = append(errs, graph.Errors...)
+	graph.format = graphFlags.GraphFormat
+
+	return graph, nil
+}
+
+// loadGraphSources reads the contracts and installed dependencies of the project, followed by
+// the other Cadence files in the directories. Files which can not be read are returned as errors.
+func loadGraphSources(state *flowkit.State, directories []string) ([]graphSource, []string) {
+	var sources []graphSource
+	var errs []string
+	seen := make(map[string]bool)
+
+	for _, contract := range *state.Contracts() {
+		kind := graphNodeContract
+		if contract.IsDependency {
+			kind = graphNodeDependency
+		}
+
+		seen[filepath.Clean(contract.Location)] = true
+		code, err := state.ReadFile(contract.Location)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %s", contract.Name, err))
+		}
+		sources = append(sources, graphSource{Name: contract.Name, Kind: kind, Location: contract.Location, Code: code})
+	}
+
+	// Dependencies are added as contracts when installed, others are read from the installer's location
+	for _, dependency := range *state.Dependencies() {
+		if _, err := state.Contracts().ByName(dependency.Name); err == nil {
+			continue
+		}
+
+		location := dependencymanager.ContractFilePath(dependency.Source.Address.String(), dependency.Source.ContractName)
+		seen[filepath.Clean(location)] = true
+		code, err := state.ReadFile(location)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: dependency is not installed", dependency.Name))
+		}
+		sources = append(sources, graphSource{Name: dependency.Name, Kind: graphNodeDependency, Location: location, Code: code})
+	}
+
+	for _, directory := range directories {
+		if _, err := os.Stat(directory); os.IsNotExist(err) {
+			continue
+		}
+
+		files, err := findAllCadenceFiles(directory)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %s", directory, err))
+			continue
+		}
+		for _, file := range files {
+			if seen[filepath.Clean(file)] {
+				continue
+			}
+			seen[filepath.Clean(file)] = true
+
+			code, err := state.ReadFile(file)
+			if err != nil {
+				errs = append(errs, fmt.Sprintf("%s: %s", file, err))
+				continue
+			}
+			sources = append(sources, graphSource{Location: file, Code: code})
+		}
+	}
+
+	return sources, errs
+}
+
+// newImportGraph returns the graph of the imports between the named sources.
+//
+// String imports are resolved to files like the linter resolves them, by the contract name
+// or the path relative to the importing file. Imports which can not be resolved to a source,
+// e.g. of dependencies which are not installed, are identified by the imported name or path.
+// Address imports are resolved by name.
+func newImportGraph(sources []graphSource) *importGraph {
+	graph := &importGraph{}
+
+	kinds := make(map[string]string)
+	byLocation := make(map[string]string)
+	var contracts config.Contracts
+	for _, source := range sources {
+		if source.Name == "" {
+			continue
+		}
+		if _, ok := kinds[source.Name]; ok {
+			continue
+		}
+		kinds[source.Name] = source.Kind
+		byLocation[filepath.Clean(source.Location)] = source.Name
+		contracts.AddOrUpdate(config.Contract{Name: source.Name, Location: source.Location})
+		graph.Nodes = append(graph.Nodes, graphNode{Name: source.Name, Kind: source.Kind, Location: filepath.ToSlash(source.Location)})
+	}
+
+	imported := make(map[string]bool)
+	edges := make(map[graphEdge]bool)
+	for _, source := range sources {
+		if source.Code == nil {
+			continue
+		}
+
+		program, err := parser.ParseProgram(nil, source.Code, parser.Config{})
+		if err != nil {
+			graph.Errors = append(graph.Errors, fmt.Sprintf("%s: %s", source.Location, err))
+			continue
+		}
+
+		for _, declaration := range program.ImportDeclarations() {
+			var names []string
+			switch location := declaration.Location.(type) {
+			case common.IdentifierLocation:
+				// Standard library contracts, e.g. Crypto and Test
+				continue
+			case common.StringLocation:
+				var imported common.Location = location
+				if util.IsPathLocation(location) {
+					imported = util.NormalizePathLocation(common.StringLocation(source.Location), location)
+				}
+				name := imported.String()
+				if path, err := resolveImportFilepath(&contracts, imported); err == nil {
+					if resolved, ok := byLocation[filepath.Clean(path)]; ok {
+						name = resolved
+					}
+				}
+				names = append(names, name)
+			default:
+				for _, identifier := range declaration.Imports {
+					names = append(names, identifier.Identifier.Identifier)
+				}
+			}
+
+			for _, name := range names {
+				imported[name] = true
+				if _, ok := kinds[name]; !ok {
+					kinds[name] = graphNodeUnresolved
+					graph.Nodes = append(graph.Nodes, graphNode{Name: name, Kind: graphNodeUnresolved})
+				}
+				if source.Name != "" {
+					edge := graphEdge{From: source.Name, To: name}
+					if !edges[edge] {
+						edges[edge] = true
+						graph.Edges = append(graph.Edges, edge)
+					}
+				}
+			}
+		}
+	}
+
+	sort.Slice(graph.Nodes, func(i, j int) bool {
+		return graph.Nodes[i].Name < graph.Nodes[j].Name
+	})
+	sort.Slice(graph.Edges, func(i, j int) bool {
+		if graph.Edges[i].From != graph.Edges[j].From {
+			return graph.Edges[i].From < graph.Edges[j].From
+		}
+		return graph.Edges[i].To < graph.Edges[j].To
+	})
+
+	for _, node := range graph.Nodes {
+		if imported[node.Name] {
+			continue
+		}
+		switch node.Kind {
+		case graphNodeContract:
+			graph.Unimported = append(graph.Unimported, node.Name)
+		case graphNodeDependency:
+			graph.UnusedDependencies = append(graph.UnusedDependencies, node.Name)
+		}
+	}
+
+	graph.Cycles = graph.findCycles()
+
+	return graph
+}
+
+// findCycles returns the strongly connected components of the graph with more than one contract,
+// or a contract importing itself, using Tarjan's algorithm.
+func (g *importGraph) findCycles() [][]string {
+	successors := make(map[string][]string)
+	for _, edge := range g.Edges {
+		successors[edge.From] = append(successors[edge.From], edge.To)
+	}
+
+	index := 0
+	indices := make(map[string]int)
+	lowLinks := make(map[string]int)
+	onStack := make(map[string]bool)
+	var stack []string
+	var cycles [][]string
+
+	var connect func(name string)
+	connect = func(name string) {
+		indices[name] = index
+		lowLinks[name] = index
+		index++
+		stack = append(stack, name)
+		onStack[name] = true
+
+		for _, successor := range successors[name] {
+			if _, visited := indices[successor]; !visited {
+				connect(successor)
+				lowLinks[name] = min(lowLinks[name], lowLinks[successor])
+			} else if onStack[successor] {
+				lowLinks[name] = min(lowLinks[name], indices[successor])
+			}
+		}
+
+		if lowLinks[name] != indices[name] {
+			return
+		}
+
+		var component []string
+		for {
+			last := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[last] = false
+			component = append(component, last)
+			if last == name {
+				break
+			}
+		}
+
+		if len(component) > 1 || g.hasEdge(name, name) {
+			sort.Strings(component)
+			cycles = append(cycles, component)
+		}
+	}
+
+	for _, node := range g.Nodes {
+		if _, visited := indices[node.Name]; !visited {
+			connect(node.Name)
+		}
+	}
+
+	sort.Slice(cycles, func(i, j int) bool {
+		return cycles[i][0] < cycles[j][0]
+	})
+	return cycles
+}
+
+func (g *importGraph) hasEdge(from, to string) bool {
+	for _, edge := range g.Edges {
+		if edge.From == from && edge.To == to {
+			return true
+		}
+	}
+	return false
+}
+
+// inCycle returns whether the edge is part of an import cycle.
+func (g *importGraph) inCycle(edge graphEdge) bool {
+	for _, cycle := range g.Cycles {
+		if containsString(cycle, edge.From) && containsString(cycle, edge.To) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// findings returns the problems of the graph as lines of text.
+func (g *importGraph) findings() []string {
+	var findings []string
+	for _, cycle := range g.Cycles {
+		findings = append(findings, fmt.Sprintf("import cycle: %s", strings.Join(cycle, ", ")))
+	}
+	for _, name := range g.Unimported {
+		findings = append(findings, fmt.Sprintf("contract %s is not imported by anything", name))
+	}
+	for _, name := range g.UnusedDependencies {
+		findings = append(findings, fmt.Sprintf("dependency %s is never imported", name))
+	}
+	for _, err := range g.Errors {
+		findings = append(findings, fmt.Sprintf("error: %s", err))
+	}
+	return findings
+}
+
+func (g *importGraph) dot() string {
+	var sb strings.Builder
+	for _, finding := range g.findings() {
+		sb.WriteString(fmt.Sprintf("// %s\n", finding))
+	}
+
+	sb.WriteString("digraph imports {\n")
+	for _, node := range g.Nodes {
+		var attributes []string
+		switch node.Kind {
+		case graphNodeDependency:
+			attributes = append(attributes, "shape=box")
+		case graphNodeUnresolved:
+			attributes = append(attributes, "style=dashed")
+		}
+		if containsString(g.Unimported, node.Name) || containsString(g.UnusedDependencies, node.Name) {
+			attributes = append(attributes, "color=orange")
+		}
+		sb.WriteString(fmt.Sprintf("  %q", node.Name))
+		if len(attributes) > 0 {
+			sb.WriteString(fmt.Sprintf(" [%s]", strings.Join(attributes, ", ")))
+		}
+		sb.WriteString(";\n")
+	}
+	for _, edge := range g.Edges {
+		sb.WriteString(fmt.Sprintf("  %q -> %q", edge.From, edge.To))
+		if g.inCycle(edge) {
+			sb.WriteString(" [color=red]")
+		}
+		sb.WriteString(";\n")
+	}
+	sb.WriteString("}\n")
+
+	return sb.String()
+}
+
+func (g *importGraph) mermaid() string {
+	var sb strings.Builder
+	for _, finding := range g.findings() {
+		sb.WriteString(fmt.Sprintf("%%%% %s\n", finding))
+	}
+
+	sb.WriteString("graph TD\n")
+
+	// Names are not valid Mermaid IDs, e.g. paths of unresolved imports or keywords like end,
+	// so nodes get generated IDs and are labeled with their names
+	ids := make(map[string]string, len(g.Nodes))
+	for i, node := range g.Nodes {
+		id := fmt.Sprintf("n%d", i)
+		ids[node.Name] = id
+
+		label := fmt.Sprintf("\"%s\"", strings.ReplaceAll(node.Name, "\"", "#quot;"))
+		switch node.Kind {
+		case graphNodeDependency:
+			sb.WriteString(fmt.Sprintf("    %s[%s]\n", id, label))
+		case graphNodeUnresolved:
+			sb.WriteString(fmt.Sprintf("    %s{{%s}}\n", id, label))
+		default:
+			sb.WriteString(fmt.Sprintf("    %s(%s)\n", id, label))
+		}
+	}
+
+	var cycleEdges []string
+	for i, edge := range g.Edges {
+		sb.WriteString(fmt.Sprintf("    %s --> %s\n", ids[edge.From], ids[edge.To]))
+		if g.inCycle(edge) {
+			cycleEdges = append(cycleEdges, fmt.Sprintf("%d", i))
+		}
+	}
+	if len(cycleEdges) > 0 {
+		sb.WriteString(fmt.Sprintf("    linkStyle %s stroke:red\n", strings.Join(cycleEdges, ",")))
+	}
+
+	var unused []string
+	for _, name := range append(append([]string{}, g.Unimported...), g.UnusedDependencies...) {
+		unused = append(unused, ids[name])
+	}
+	if len(unused) > 0 {
+		sb.WriteString("    classDef unused stroke:orange\n")
+		sb.WriteString(fmt.Sprintf("    class %s unused\n", strings.Join(unused, ",")))
+	}
+
+	return sb.String()
+}
+
+func (g *importGraph) String() string {
+	switch g.format {
+	case graphFormatMermaid:
+		return g.mermaid()
+	case graphFormatJSON:
+		data, _ := json.MarshalIndent(g, "", "  ")
+		return string(data)
+	default:
+		return g.dot()
+	}
+}
+
+func (g *importGraph) Oneliner() string {
+	findings := g.findings()
+	if len(findings) == 0 {
+		return fmt.Sprintf("%d %s, %d %s, no problems found",
+			len(g.Nodes), util.Pluralize("contract", len(g.Nodes)),
+			len(g.Edges), util.Pluralize("import", len(g.Edges)),
+		)
+	}
+	return strings.Join(findings, "; ")
+}
+
+func (g *importGraph) JSON() any {
+	return g
+}
+
+// ExitCode is 1 if the graph has import cycles, which Cadence does not allow.
+func (g *importGraph) ExitCode() int {
+	if len(g.Cycles) > 0 {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,185 @@
+/*
+ * Flow CLI
+ *
+ * Copyright Flow Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cadence
+
+import (
+	"path/filepath"
+	"testing"
+
+	flowsdk "github.com/onflow/flow-go-sdk"
+	"github.com/onflow/flowkit/v2"
+	"github.com/onflow/flowkit/v2/config"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+var graphSources = []graphSource{
+	{
+		Name:     "Counter",
+		Kind:     graphNodeContract,
+		Location: "cadence/contracts/Counter.cdc",
+		Code:     []byte("import \"FungibleToken\"\nimport Math from \"./utils/Math.cdc\"\naccess(all) contract Counter {}"),
+	},
+	{
+		Name:     "Math",
+		Kind:     graphNodeContract,
+		Location: "cadence/contracts/utils/Math.cdc",
+		Code:     []byte("import Crypto\naccess(all) contract Math {}"),
+	},
+	{
+		Name:     "FungibleToken",
+		Kind:     graphNodeDependency,
+		Location: "imports/f233dcee88fe0abe/FungibleToken.cdc",
+		Code:     []byte("import ViewResolver from 0xf233dcee88fe0abe\naccess(all) contract interface FungibleToken {}"),
+	},
+	{
+		Name:     "NonFungibleToken",
+		Kind:     graphNodeDependency,
+		Location: "imports/1d7e57aa55817448/NonFungibleToken.cdc",
+		Code:     []byte("access(all) contract interface NonFungibleToken {}"),
+	},
+	{
+		Location: "cadence/scripts/GetCount.cdc",
+		Code:     []byte("import \"Counter\"\naccess(all) fun main(): Int { return 1 }"),
+	},
+}
+
+func Test_Graph(t *testing.T) {
+	t.Parallel()
+
+	t.Run("builds import graph", func(t *testing.T) {
+		t.Parallel()
+
+		graph := newImportGraph(graphSources)
+
+		require.Equal(t, []graphNode{
+			{Name: "Counter", Kind: graphNodeContract, Location: "cadence/contracts/Counter.cdc"},
+			{Name: "FungibleToken", Kind: graphNodeDependency, Location: "imports/f233dcee88fe0abe/FungibleToken.cdc"},
+			{Name: "Math", Kind: graphNodeContract, Location: "cadence/contracts/utils/Math.cdc"},
+			{Name: "NonFungibleToken", Kind: graphNodeDependency, Location: "imports/1d7e57aa55817448/NonFungibleToken.cdc"},
+			{Name: "ViewResolver", Kind: graphNodeUnresolved},
+		}, graph.Nodes)
+		require.Equal(t, []graphEdge{
+			{From: "Counter", To: "FungibleToken"},
+			{From: "Counter", To: "Math"},
+			{From: "FungibleToken", To: "ViewResolver"},
+		}, graph.Edges)
+
+		require.Empty(t, graph.Cycles)
+		require.Empty(t, graph.Unimported)
+		require.Equal(t, []string{"NonFungibleToken"}, graph.UnusedDependencies)
+		require.Equal(t, 0, graph.ExitCode())
+		require.Equal(t, "dependency NonFungibleToken is never imported", graph.Oneliner())
+	})
+
+	t.Run("reports cycles and unimported contracts", func(t *testing.T) {
+		t.Parallel()
+
+		graph := newImportGraph([]graphSource{
+			{Name: "A", Kind: graphNodeContract, Location: "A.cdc", Code: []byte("import \"B\"\naccess(all) contract A {}")},
+			{Name: "B", Kind: graphNodeContract, Location: "B.cdc", Code: []byte("import \"C\"\naccess(all) contract B {}")},
+			{Name: "C", Kind: graphNodeContract, Location: "C.cdc", Code: []byte("import \"A\"\naccess(all) contract C {}")},
+			{Name: "D", Kind: graphNodeContract, Location: "D.cdc", Code: []byte("import \"B\"\naccess(all) contract D {}")},
+		})
+
+		require.Equal(t, [][]string{{"A", "B", "C"}}, graph.Cycles)
+		require.Equal(t, []string{"D"}, graph.Unimported)
+		require.Equal(t, 1, graph.ExitCode())
+
+		require.Equal(t,
+			"// import cycle: A, B, C\n"+
+				"// contract D is not imported by anything\n"+
+				"digraph imports {\n"+
+				"  \"A\";\n  \"B\";\n  \"C\";\n  \"D\" [color=orange];\n"+
+				"  \"A\" -> \"B\" [color=red];\n"+
+				"  \"B\" -> \"C\" [color=red];\n"+
+				"  \"C\" -> \"A\" [color=red];\n"+
+				"  \"D\" -> \"B\";\n"+
+				"}\n",
+			graph.dot(),
+		)
+
+		require.Equal(t,
+			"%% import cycle: A, B, C\n"+
+				"%% contract D is not imported by anything\n"+
+				"graph TD\n"+
+				"    n0(\"A\")\n    n1(\"B\")\n    n2(\"C\")\n    n3(\"D\")\n"+
+				"    n0 --> n1\n    n1 --> n2\n    n2 --> n0\n    n3 --> n1\n"+
+				"    linkStyle 0,1,2 stroke:red\n"+
+				"    classDef unused stroke:orange\n"+
+				"    class n3 unused\n",
+			graph.mermaid(),
+		)
+	})
+
+	t.Run("reports contracts importing themselves", func(t *testing.T) {
+		t.Parallel()
+
+		graph := newImportGraph([]graphSource{
+			{Name: "A", Kind: graphNodeContract, Location: "A.cdc", Code: []byte("import A from \"./A.cdc\"\naccess(all) contract A {}")},
+		})
+		require.Equal(t, [][]string{{"A"}}, graph.Cycles)
+	})
+
+	t.Run("identifies unresolved path imports by their path", func(t *testing.T) {
+		t.Parallel()
+
+		graph := newImportGraph([]graphSource{
+			{Name: "A", Kind: graphNodeContract, Location: "contracts/A.cdc", Code: []byte("import B from \"./B.cdc\"\naccess(all) contract A {}")},
+		})
+		require.Equal(t, []graphNode{
+			{Name: "A", Kind: graphNodeContract, Location: "contracts/A.cdc"},
+			{Name: "contracts/B.cdc", Kind: graphNodeUnresolved},
+		}, graph.Nodes)
+		require.Equal(t, []graphEdge{{From: "A", To: "contracts/B.cdc"}}, graph.Edges)
+		require.Equal(t,
+			"%% contract A is not imported by anything\n"+
+				"graph TD\n"+
+				"    n0(\"A\")\n    n1{{\"contracts/B.cdc\"}}\n"+
+				"    n0 --> n1\n"+
+				"    classDef unused stroke:orange\n"+
+				"    class n0 unused\n",
+			graph.mermaid(),
+		)
+	})
+
+	t.Run("loads contracts, dependencies and other files", func(t *testing.T) {
+		t.Parallel()
+
+		rw := afero.Afero{Fs: afero.NewMemMapFs()}
+		state, err := flowkit.Init(rw)
+		require.NoError(t, err)
+
+		require.NoError(t, rw.WriteFile("Counter.cdc", []byte("access(all) contract Counter {}"), 0644))
+		state.Contracts().AddOrUpdate(config.Contract{Name: "Counter", Location: "Counter.cdc"})
+		state.Dependencies().AddOrUpdate(config.Dependency{
+			Name: "FungibleToken",
+			Source: config.Source{
+				NetworkName:  "mainnet",
+				Address:      flowsdk.HexToAddress("f233dcee88fe0abe"),
+				ContractName: "FungibleToken",
+			},
+		})
+
+		sources, errs := loadGraphSources(state, []string{"missing"})
+		require.Len(t, sources, 2)
+		require.Equal(t, "imports/f233dcee88fe0abe/FungibleToken.cdc", filepath.ToSlash(sources[1].Location))
+		require.Equal(t, []string{"FungibleToken: dependency is not installed"}, errs)
+	})
+}
@@ -22,7 +22,9 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strings"
+	"sync"
 
 	"golang.org/x/exp/slices"
 
@@ -42,6 +44,11 @@ import (
 type lintFlagsCollection struct {
 	WarningsAsErrors bool   `default:"false" flag:"warnings-as-errors" info:"Treat warnings as errors"`
 	BaseDir          string `default:"" flag:"base-dir" info:"Directory to search for .cdc files (defaults to current directory)"`
+	Baseline         string `default:"" flag:"baseline" info:"Baseline file of known problems, which are not reported. Records all current problems if the file does not exist"`
+	UpdateBaseline   bool   `default:"false" flag:"update-baseline" info:"Record all current problems in the baseline file"`
+	NoCache          bool   `default:"false" flag:"no-cache" info:"Lint all files, without using the diagnostics cached by previous runs"`
+	Fix              bool   `default:"false" flag:"fix" info:"Apply the suggested fixes to the files"`
+	FixDryRun        bool   `default:"false" flag:"fix-dry-run" info:"Print the suggested fixes as a diff without applying them"`
 }
 
 type fileResult struct {
@@ -52,6 +59,15 @@ type fileResult struct {
 type lintResult struct {
 	Results  []fileResult
 	exitCode int
+	// format is formatSARIF or formatGitHub to render the result in that format, empty otherwise.
+	format string
+	// severities holds the severity configured for the rule that reported a diagnostic, if any.
+	severities map[diagnosticKey]severity
+	// categoryHelp holds the help of each diagnostic category reported by analyzers,
+	// from the descriptions of the rules that reported its diagnostics.
+	categoryHelp map[string]string
+	// fixDiff holds the suggested fixes as a unified diff, printed before the diagnostics.
+	fixDiff string
 }
 
 var _ command.ResultWithExitCode = &lintResult{}
@@ -66,7 +82,28 @@ var lintCommand = &command.Command{
 flow cadence lint
 
 # Lint specific files
-flow cadence lint file1.cdc file2.cdc`,
+flow cadence lint file1.cdc file2.cdc
+
+# Rules are configured in the "lint" section of flow.json or in a .cadencelint file:
+#   {"rules": {"unused-variable": "off", "hardcoded-address": "error"}, "exclude": ["imports/**"]}
+# Custom rules are JSON files in the directory set as "plugins", e.g. no-storage.json:
+#   {"message": "transactions must not request storage access",
+#    "match": [{"element": "TransactionDeclaration"}, {"element": "ReferenceType", "text": "Storage"}]}
+# A single line is excluded from a rule with a comment on the line before:
+#   // lint-disable-next-line unused-variable
+
+# Apply suggested fixes, or only print them as a diff
+flow cadence lint --fix
+flow cadence lint --fix-dry-run
+
+# Record the current problems, afterwards only new problems are reported
+flow cadence lint --baseline lint-baseline.json --warnings-as-errors
+
+# Write a SARIF log for GitHub code scanning
+flow cadence lint --format sarif --save lint.sarif
+
+# Annotate pull requests from a GitHub Actions workflow
+flow cadence lint --format github`,
 		Args: cobra.ArbitraryArgs,
 	},
 	Flags: &lintFlags,
@@ -87,13 +124,24 @@ func lint(
 	flow flowkit.Services,
 	state *flowkit.State,
 ) (command.Result, error) {
+	if lintFlags.UpdateBaseline && lintFlags.Baseline == "" {
+		return nil, fmt.Errorf("--update-baseline requires --baseline")
+	}
+	if lintFlags.Fix && lintFlags.FixDryRun {
+		return nil, fmt.Errorf("--fix and --fix-dry-run can not be used together")
+	}
+
+	config, err := loadLintConfig(state, globalFlags.ConfigPaths)
+	if err != nil {
+		return nil, err
+	}
+
 	var filePaths []string
 	if len(args) == 0 {
 		baseDir := "."
 		if lintFlags.BaseDir != "" {
 			baseDir = lintFlags.BaseDir
 		}
-		var err error
 		filePaths, err = findAllCadenceFiles(baseDir)
 		if err != nil {
 			return nil, fmt.Errorf("error finding Cadence files: %w", err)
@@ -105,11 +153,60 @@ func lint(
 		filePaths = args
 	}
 
-	result, err := lintFiles(state, lintFlags.WarningsAsErrors, filePaths...)
+	filePaths = slices.DeleteFunc(filePaths, config.isExcluded)
+	if len(filePaths) == 0 {
+		return nil, fmt.Errorf("all .cdc files are excluded by the lint configuration")
+	}
+
+	var cache *lintCache
+	if !lintFlags.NoCache {
+		cache = newLintCache(state, config, globalFlags.ConfigPaths)
+	}
+
+	result, err := lintFilesWithConfig(state, config, cache, lintFlags.WarningsAsErrors, filePaths...)
 	if err != nil {
 		return nil, err
 	}
 
+	if lintFlags.Fix || lintFlags.FixDryRun {
+		fixes, err := fixFiles(state, result)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(fixes) == 0 {
+			logger.Info("No suggested fixes to apply")
+		} else if lintFlags.FixDryRun {
+			result.fixDiff = fixesDiff(fixes)
+		} else {
+			err = writeFixes(state, logger, fixes)
+			if err != nil {
+				return nil, err
+			}
+
+			// Report the problems that remain after fixing
+			result, err = lintFilesWithConfig(state, config, cache, lintFlags.WarningsAsErrors, filePaths...)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if lintFlags.Baseline != "" {
+		err = applyBaseline(state, logger, result, lintFlags.Baseline, lintFlags.UpdateBaseline)
+		if err != nil {
+			return nil, err
+		}
+		result.exitCode = result.computeExitCode(lintFlags.WarningsAsErrors)
+	}
+
+	// Diagnostics are rendered in these formats by the result itself,
+	// as they are not supported by other commands.
+	switch format := strings.ToLower(globalFlags.Format); format {
+	case formatSARIF, formatGitHub:
+		result.format = format
+	}
+
 	return result, nil
 }
 
@@ -121,52 +218,156 @@ func lintFiles(
 	*lintResult,
 	error,
 ) {
-	l := newLinter(state)
-	results := make([]fileResult, 0)
-	exitCode := 0
+	return lintFilesWithConfig(state, &lintConfig{}, nil, warningsAsErrors, filePaths...)
+}
 
-	for _, location := range filePaths {
-		diagnostics, err := l.lintFile(location)
-		if err != nil {
-			// If there's an internal error (like a panic), convert it to a diagnostic
-			// and continue processing other files
-			diagnostics = []analysis.Diagnostic{
-				{
-					Location: common.StringLocation(location),
-					Category: ErrorCategory,
-					Message:  err.Error(),
-					Range:    ast.Range{},
-				},
+// lintFilesWithConfig lints the files in parallel. Programs imported by several files are
+// checked only once, and files are not linted again if their diagnostics are in the cache.
+func lintFilesWithConfig(
+	state *flowkit.State,
+	config *lintConfig,
+	cache *lintCache,
+	warningsAsErrors bool,
+	filePaths ...string,
+) (
+	*lintResult,
+	error,
+) {
+	results := make([]fileResult, len(filePaths))
+	checked := newCheckerCache()
+
+	var severities map[diagnosticKey]severity
+	var rules map[diagnosticKey]string
+	var severitiesMu sync.Mutex
+
+	files := make(chan int)
+	var wg sync.WaitGroup
+	for range min(runtime.NumCPU(), len(filePaths)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			l := newLinterWithConfig(state, config)
+			l.checked = checked
+
+			for i := range files {
+				location := filePaths[i]
+				diagnostics, err := l.lintFile(location, cache)
+				if err != nil {
+					// If there's an internal error (like a panic), convert it to a diagnostic
+					// and continue processing other files
+					diagnostics = []analysis.Diagnostic{
+						{
+							Location: common.StringLocation(location),
+							Category: ErrorCategory,
+							Message:  err.Error(),
+							Range:    ast.Range{},
+						},
+					}
+				}
+
+				// Sort for consistent output
+				sortDiagnostics(diagnostics)
+				results[i] = fileResult{
+					FilePath:    location,
+					Diagnostics: diagnostics,
+				}
 			}
-			exitCode = 1
-		}
 
-		// Sort for consistent output
-		sortDiagnostics(diagnostics)
-		results = append(results, fileResult{
-			FilePath:    location,
-			Diagnostics: diagnostics,
-		})
-
-		// Set the exitCode to 1 if any of the diagnostics are error-level,
-		// or warning-level when warningsAsErrors is set.
-		for _, diagnostic := range diagnostics {
-			severity := getDiagnosticSeverity(diagnostic)
+			severitiesMu.Lock()
+			defer severitiesMu.Unlock()
+			for key, diagnosticSeverity := range l.severities {
+				if severities == nil {
+					severities = make(map[diagnosticKey]severity)
+				}
+				severities[key] = diagnosticSeverity
+			}
+			for key, rule := range l.rules {
+				if rules == nil {
+					rules = make(map[diagnosticKey]string)
+				}
+				rules[key] = rule
+			}
+		}()
+	}
+
+	for i := range filePaths {
+		files <- i
+	}
+	close(files)
+	wg.Wait()
+
+	result := &lintResult{
+		Results:      results,
+		severities:   severities,
+		categoryHelp: categoryHelp(config.analyzers(), rules),
+	}
+	result.exitCode = result.computeExitCode(warningsAsErrors)
+
+	return result, nil
+}
+
+// computeExitCode returns 1 if any of the diagnostics are error-level,
+// or warning-level when warningsAsErrors is set.
+func (r *lintResult) computeExitCode(warningsAsErrors bool) int {
+	for _, result := range r.Results {
+		for _, diagnostic := range result.Diagnostics {
+			severity := r.severity(diagnostic)
 			if severity == errorSeverity {
-				exitCode = 1
-				break
+				return 1
 			}
 			if severity == warningSeverity && warningsAsErrors {
-				exitCode = 1
-				break
+				return 1
 			}
 		}
 	}
+	return 0
+}
+
+// applyBaseline removes the problems recorded in the baseline file from the result.
+// All problems are recorded first if the baseline file does not exist, or update is set.
+func applyBaseline(
+	state *flowkit.State,
+	logger output.Logger,
+	result *lintResult,
+	baselinePath string,
+	update bool,
+) error {
+	baseline, err := loadLintBaseline(state, baselinePath)
+	if err != nil {
+		return err
+	}
 
-	return &lintResult{
-		Results:  results,
-		exitCode: exitCode,
-	}, nil
+	if baseline == nil || update {
+		baseline = newLintBaseline(state, result)
+		err = baseline.save(state, baselinePath)
+		if err != nil {
+			return err
+		}
+		logger.Info(fmt.Sprintf(
+			"%s Recorded %d %s in baseline %s",
+			output.SaveEmoji(),
+			len(baseline.Issues),
+			util.Pluralize("problem", len(baseline.Issues)),
+			baselinePath,
+		))
+	}
+
+	known, fixed := baseline.filter(state, result)
+	if known > 0 {
+		logger.Info(fmt.Sprintf("Ignoring %d known %s from baseline %s", known, util.Pluralize("problem", known), baselinePath))
+	}
+	if fixed > 0 {
+		logger.Info(fmt.Sprintf(
+			"%s %d %s from baseline %s no longer found, run with --update-baseline to remove them",
+			output.TryEmoji(),
+			fixed,
+			util.Pluralize("problem", fixed),
+			baselinePath,
+		))
+	}
+
+	return nil
 }
 
 func getDiagnosticSeverity(
@@ -178,6 +379,22 @@ func getDiagnosticSeverity(
 	return warningSeverity
 }
 
+// diagnosticSeverity returns the severity configured for the rule that reported
+// the diagnostic, or the default severity of the diagnostic otherwise.
+func diagnosticSeverity(
+	severities map[diagnosticKey]severity,
+	diagnostic analysis.Diagnostic,
+) severity {
+	if severity, ok := severities[newDiagnosticKey(diagnostic)]; ok {
+		return severity
+	}
+	return getDiagnosticSeverity(diagnostic)
+}
+
+func (r *lintResult) severity(diagnostic analysis.Diagnostic) severity {
+	return diagnosticSeverity(r.severities, diagnostic)
+}
+
 // Sort diagnostics in order of precedence: start pos -> category -> message
 func sortDiagnostics(
 	diagnostics []analysis.Diagnostic,
@@ -195,9 +412,9 @@ func sortDiagnostics(
 	})
 }
 
-func renderDiagnostic(diagnostic analysis.Diagnostic) string {
+func renderDiagnostic(diagnostic analysis.Diagnostic, severity severity) string {
 	categoryColor := aurora.RedFg
-	if getDiagnosticSeverity(diagnostic) == warningSeverity {
+	if severity == warningSeverity {
 		categoryColor = aurora.YellowFg
 	}
 
@@ -217,7 +434,7 @@ func (r *lintResult) countProblems() (int, int) {
 	numWarnings := 0
 	for _, result := range r.Results {
 		for _, diagnostic := range result.Diagnostics {
-			if isErrorDiagnostic(diagnostic) {
+			if r.severity(diagnostic) == errorSeverity {
 				numErrors++
 			} else {
 				numWarnings++
@@ -228,11 +445,22 @@ func (r *lintResult) countProblems() (int, int) {
 }
 
 func (r *lintResult) String() string {
+	switch r.format {
+	case formatSARIF:
+		return r.sarif()
+	case formatGitHub:
+		return r.githubAnnotations()
+	}
+
 	var sb strings.Builder
 
+	if r.fixDiff != "" {
+		sb.WriteString(fmt.Sprintf("%s\n", r.fixDiff))
+	}
+
 	for _, result := range r.Results {
 		for _, diagnostic := range result.Diagnostics {
-			sb.WriteString(fmt.Sprintf("%s\n\n", renderDiagnostic(diagnostic)))
+			sb.WriteString(fmt.Sprintf("%s\n\n", renderDiagnostic(diagnostic, r.severity(diagnostic))))
 		}
 	}
 
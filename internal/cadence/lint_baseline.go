@@ -0,0 +1,196 @@
+/*
+ * Flow CLI
+ *
+ * Copyright Flow Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cadence
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/onflow/cadence/tools/analysis"
+	"github.com/onflow/flowkit/v2"
+)
+
+// lintBaseline holds the known problems of a project, e.g.:
+//
+//	{
+//	  "issues": [
+//	    {
+//	      "file": "cadence/contracts/Foo.cdc",
+//	      "rule": "removal-hint",
+//	      "fingerprint": "5f0c6e7a9b2d4c1e",
+//	      "message": "unnecessary force operator"
+//	    }
+//	  ]
+//	}
+//
+// Problems are identified by their file, rule and a fingerprint of the code
+// they are reported on, so they stay known when lines are added or removed above them.
+type lintBaseline struct {
+	Issues []baselineIssue `json:"issues"`
+}
+
+type baselineIssue struct {
+	File        string `json:"file"`
+	Rule        string `json:"rule"`
+	Fingerprint string `json:"fingerprint"`
+	// Message is not used for matching, it only makes the baseline easier to review.
+	Message string `json:"message"`
+}
+
+// key identifies the problem, the same problem may occur several times in a file.
+func (i baselineIssue) key() baselineIssue {
+	return baselineIssue{
+		File:        i.File,
+		Rule:        i.Rule,
+		Fingerprint: i.Fingerprint,
+	}
+}
+
+// loadLintBaseline reads the baseline file, or returns nil if it does not exist yet.
+func loadLintBaseline(state *flowkit.State, path string) (*lintBaseline, error) {
+	data, err := state.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var baseline lintBaseline
+	err = json.Unmarshal(data, &baseline)
+	if err != nil {
+		return nil, fmt.Errorf("invalid lint baseline %s: %w", path, err)
+	}
+
+	return &baseline, nil
+}
+
+// newLintBaseline records all problems of the result.
+func newLintBaseline(state *flowkit.State, result *lintResult) *lintBaseline {
+	baseline := &lintBaseline{
+		Issues: make([]baselineIssue, 0),
+	}
+
+	for _, fileResult := range result.Results {
+		code, _ := state.ReadFile(fileResult.FilePath)
+		for _, diagnostic := range fileResult.Diagnostics {
+			baseline.Issues = append(baseline.Issues, newBaselineIssue(fileResult.FilePath, code, diagnostic))
+		}
+	}
+
+	sort.SliceStable(baseline.Issues, func(i, j int) bool {
+		a, b := baseline.Issues[i], baseline.Issues[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Rule != b.Rule {
+			return a.Rule < b.Rule
+		}
+		return a.Fingerprint < b.Fingerprint
+	})
+
+	return baseline
+}
+
+func (b *lintBaseline) save(state *flowkit.State, path string) error {
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	err = state.ReaderWriter().WriteFile(path, append(data, '\n'), 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write lint baseline %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// filter removes the problems recorded in the baseline from the result.
+// It returns the number of removed problems, and the number of problems
+// in the baseline which no longer occur, e.g. because they were fixed.
+func (b *lintBaseline) filter(state *flowkit.State, result *lintResult) (int, int) {
+	remaining := make(map[baselineIssue]int, len(b.Issues))
+	for _, issue := range b.Issues {
+		remaining[issue.key()]++
+	}
+
+	known := 0
+	for i, fileResult := range result.Results {
+		code, _ := state.ReadFile(fileResult.FilePath)
+
+		diagnostics := make([]analysis.Diagnostic, 0, len(fileResult.Diagnostics))
+		for _, diagnostic := range fileResult.Diagnostics {
+			key := newBaselineIssue(fileResult.FilePath, code, diagnostic).key()
+			if remaining[key] > 0 {
+				remaining[key]--
+				known++
+				continue
+			}
+			diagnostics = append(diagnostics, diagnostic)
+		}
+		result.Results[i].Diagnostics = diagnostics
+	}
+
+	fixed := 0
+	for _, count := range remaining {
+		fixed += count
+	}
+
+	return known, fixed
+}
+
+func newBaselineIssue(filePath string, code []byte, diagnostic analysis.Diagnostic) baselineIssue {
+	return baselineIssue{
+		File:        filepath.ToSlash(filepath.Clean(filePath)),
+		Rule:        diagnostic.Category,
+		Fingerprint: diagnosticFingerprint(code, diagnostic),
+		Message:     diagnostic.Message,
+	}
+}
+
+// diagnosticFingerprint hashes the message of the diagnostic together with
+// the source lines it is reported on, ignoring whitespace, but not line numbers.
+func diagnosticFingerprint(code []byte, diagnostic analysis.Diagnostic) string {
+	hash := sha256.New()
+	hash.Write([]byte(diagnostic.Category))
+	hash.Write([]byte{0})
+	hash.Write([]byte(diagnostic.Message))
+	hash.Write([]byte{0})
+
+	startPos, endPos := diagnostic.Range.StartPos, diagnostic.Range.EndPos
+	if startPos.Line > 0 && startPos.Offset <= endPos.Offset && endPos.Offset < len(code) {
+		// Extend the range to whole lines
+		start := strings.LastIndexByte(string(code[:startPos.Offset]), '\n') + 1
+		end := len(code)
+		if i := strings.IndexByte(string(code[endPos.Offset:]), '\n'); i >= 0 {
+			end = endPos.Offset + i
+		}
+		hash.Write([]byte(strings.Join(strings.Fields(string(code[start:end])), " ")))
+	}
+
+	return hex.EncodeToString(hash.Sum(nil))[:16]
+}
@@ -0,0 +1,328 @@
+/*
+ * Flow CLI
+ *
+ * Copyright Flow Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cadence
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	cdctests "github.com/onflow/cadence-tools/test/helpers"
+	"github.com/onflow/cadence/ast"
+	"github.com/onflow/cadence/common"
+	cdcerrors "github.com/onflow/cadence/errors"
+	"github.com/onflow/cadence/sema"
+	"github.com/onflow/cadence/tools/analysis"
+	"github.com/onflow/flowkit/v2"
+
+	"github.com/onflow/flow-cli/build"
+)
+
+// checkerCache holds the checked programs imported by the linted files,
+// shared by all linters of a run.
+//
+// Only programs that were checked successfully are added, so they are never
+// modified afterwards and can be imported by several linters at the same time.
+type checkerCache struct {
+	mu       sync.Mutex
+	checkers map[string]*sema.Checker
+	// imports holds the files imported by each checked location
+	imports map[string]map[string]struct{}
+
+	blockchainHelpersOnce sync.Once
+	blockchainHelpers     *sema.Checker
+}
+
+func newCheckerCache() *checkerCache {
+	return &checkerCache{
+		checkers: make(map[string]*sema.Checker),
+		imports:  make(map[string]map[string]struct{}),
+	}
+}
+
+func (c *checkerCache) get(location string) (*sema.Checker, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	checker, ok := c.checkers[location]
+	return checker, ok
+}
+
+func (c *checkerCache) add(location string, checker *sema.Checker) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.checkers[location] = checker
+}
+
+func (c *checkerCache) addImport(location string, importedFilePath string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.imports[location] == nil {
+		c.imports[location] = make(map[string]struct{})
+	}
+	c.imports[location][importedFilePath] = struct{}{}
+}
+
+// dependencies returns all files imported by the location, directly or indirectly.
+func (c *checkerCache) dependencies(location string) []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	visited := map[string]struct{}{}
+	var visit func(location string)
+	visit = func(location string) {
+		for imported := range c.imports[location] {
+			if _, ok := visited[imported]; ok {
+				continue
+			}
+			visited[imported] = struct{}{}
+			visit(imported)
+		}
+	}
+	visit(location)
+	delete(visited, location)
+
+	dependencies := make([]string, 0, len(visited))
+	for dependency := range visited {
+		dependencies = append(dependencies, dependency)
+	}
+	sort.Strings(dependencies)
+
+	return dependencies
+}
+
+// blockchainHelpersChecker checks the BlockchainHelpers contract only once for all linters.
+func (c *checkerCache) blockchainHelpersChecker() *sema.Checker {
+	c.blockchainHelpersOnce.Do(func() {
+		c.blockchainHelpers = cdctests.BlockchainHelpersChecker()
+	})
+	return c.blockchainHelpers
+}
+
+// lintCache stores the diagnostics of linted files on disk, so unchanged files
+// are not linted again by later runs.
+//
+// The diagnostics of a file are reused as long as the file and all files it imports
+// are unchanged, and the CLI version, the lint configuration and the project
+// configuration are the same.
+type lintCache struct {
+	dir string
+	// salt is a hash of everything other than the files that affects the diagnostics
+	salt string
+}
+
+type lintCacheEntry struct {
+	Hash string `json:"hash"`
+	// Dependencies holds the hashes of the imported files
+	Dependencies map[string]string  `json:"dependencies"`
+	Diagnostics  []cachedDiagnostic `json:"diagnostics"`
+}
+
+type cachedDiagnostic struct {
+	Category         string                                 `json:"category"`
+	Message          string                                 `json:"message"`
+	SecondaryMessage string                                 `json:"secondaryMessage,omitempty"`
+	Code             string                                 `json:"code,omitempty"`
+	URL              string                                 `json:"url,omitempty"`
+	Range            ast.Range                              `json:"range"`
+	SuggestedFixes   []cdcerrors.SuggestedFix[ast.TextEdit] `json:"suggestedFixes,omitempty"`
+	// Severity is the severity configured for the rule that reported the diagnostic, if any
+	Severity severity `json:"severity,omitempty"`
+	// Rule is the rule that reported the diagnostic, if it was reported by an analyzer
+	Rule string `json:"rule,omitempty"`
+}
+
+// newLintCache returns the cache in the user cache directory, or nil if there is none.
+// Development builds without a version do not cache, as their diagnostics may change with any build.
+func newLintCache(state *flowkit.State, config *lintConfig, configPaths []string) *lintCache {
+	if !build.IsDefined(build.Semver()) {
+		return nil
+	}
+
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return nil
+	}
+
+	hash := sha256.New()
+	hash.Write([]byte(build.Semver()))
+	hash.Write([]byte(build.Commit()))
+	configJSON, _ := json.Marshal(config)
+	hash.Write(configJSON)
+	for _, pluginRule := range config.pluginRules {
+		pluginRuleJSON, _ := json.Marshal(pluginRule)
+		hash.Write([]byte(pluginRule.Name))
+		hash.Write(pluginRuleJSON)
+	}
+	for _, configPath := range configPaths {
+		data, _ := state.ReadFile(configPath)
+		hash.Write([]byte(contentHash(data)))
+	}
+
+	return &lintCache{
+		dir:  filepath.Join(cacheDir, "flow-cli", "lint"),
+		salt: hex.EncodeToString(hash.Sum(nil)),
+	}
+}
+
+func contentHash(code []byte) string {
+	hash := sha256.Sum256(code)
+	return hex.EncodeToString(hash[:])
+}
+
+// entryPath returns the path of the cache entry of the file, which is overwritten when the file changes.
+func (c *lintCache) entryPath(filePath string) string {
+	absPath, err := filepath.Abs(filePath)
+	if err != nil {
+		absPath = filePath
+	}
+
+	hash := sha256.Sum256([]byte(c.salt + "\x00" + absPath))
+	return filepath.Join(c.dir, hex.EncodeToString(hash[:])+".json")
+}
+
+// load returns the cached diagnostics of the file, and their configured severities and rules by index,
+// if neither the file nor any of the files it imports changed.
+func (c *lintCache) load(
+	state *flowkit.State,
+	filePath string,
+	code []byte,
+) ([]analysis.Diagnostic, map[int]severity, map[int]string, bool) {
+	data, err := os.ReadFile(c.entryPath(filePath))
+	if err != nil {
+		return nil, nil, nil, false
+	}
+
+	var entry lintCacheEntry
+	if json.Unmarshal(data, &entry) != nil || entry.Hash != contentHash(code) {
+		return nil, nil, nil, false
+	}
+
+	for dependency, hash := range entry.Dependencies {
+		dependencyCode, err := state.ReadFile(dependency)
+		if err != nil {
+			dependencyCode = nil
+		}
+		if contentHash(dependencyCode) != hash {
+			return nil, nil, nil, false
+		}
+	}
+
+	location := common.StringLocation(filePath)
+	diagnostics := make([]analysis.Diagnostic, 0, len(entry.Diagnostics))
+	severities := make(map[int]severity)
+	rules := make(map[int]string)
+	for i, cached := range entry.Diagnostics {
+		diagnostics = append(diagnostics, analysis.Diagnostic{
+			Location:         location,
+			Category:         cached.Category,
+			Message:          cached.Message,
+			SecondaryMessage: cached.SecondaryMessage,
+			Code:             cached.Code,
+			URL:              cached.URL,
+			SuggestedFixes:   cached.SuggestedFixes,
+			Range:            cached.Range,
+		})
+		if cached.Severity != "" {
+			severities[i] = cached.Severity
+		}
+		if cached.Rule != "" {
+			rules[i] = cached.Rule
+		}
+	}
+
+	return diagnostics, severities, rules, true
+}
+
+// store caches the diagnostics of the file. Failing to write the cache is not an error,
+// the file is linted again by the next run.
+func (c *lintCache) store(
+	state *flowkit.State,
+	filePath string,
+	code []byte,
+	diagnostics []analysis.Diagnostic,
+	severities map[int]severity,
+	rules map[int]string,
+	dependencies []string,
+) {
+	entry := lintCacheEntry{
+		Hash:         contentHash(code),
+		Dependencies: make(map[string]string, len(dependencies)),
+		Diagnostics:  make([]cachedDiagnostic, 0, len(diagnostics)),
+	}
+
+	for _, dependency := range dependencies {
+		dependencyCode, err := state.ReadFile(dependency)
+		if err != nil {
+			dependencyCode = nil
+		}
+		entry.Dependencies[dependency] = contentHash(dependencyCode)
+	}
+
+	location := common.StringLocation(filePath)
+	for i, diagnostic := range diagnostics {
+		// Only diagnostics of the file itself can be restored
+		if diagnostic.Location != location {
+			return
+		}
+
+		entry.Diagnostics = append(entry.Diagnostics, cachedDiagnostic{
+			Category:         diagnostic.Category,
+			Message:          diagnostic.Message,
+			SecondaryMessage: diagnostic.SecondaryMessage,
+			Code:             diagnostic.Code,
+			URL:              diagnostic.URL,
+			Range:            diagnostic.Range,
+			SuggestedFixes:   diagnostic.SuggestedFixes,
+			Severity:         severities[i],
+			Rule:             rules[i],
+		})
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+
+	err = os.MkdirAll(c.dir, 0755)
+	if err != nil {
+		return
+	}
+
+	// Write to a temporary file first, so concurrent runs never read a partial entry
+	file, err := os.CreateTemp(c.dir, "entry-*")
+	if err != nil {
+		return
+	}
+	_, err = file.Write(data)
+	closeErr := file.Close()
+	if err != nil || closeErr != nil {
+		_ = os.Remove(file.Name())
+		return
+	}
+	if os.Rename(file.Name(), c.entryPath(filePath)) != nil {
+		_ = os.Remove(file.Name())
+	}
+}
@@ -0,0 +1,317 @@
+/*
+ * Flow CLI
+ *
+ * Copyright Flow Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cadence
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	cdclint "github.com/onflow/cadence-tools/lint"
+	"github.com/onflow/cadence/tools/analysis"
+	"github.com/onflow/flowkit/v2"
+	"golang.org/x/exp/maps"
+)
+
+// lintConfigFile configures the linter of projects without a lint section in flow.json.
+const lintConfigFile = ".cadencelint"
+
+// The value of a rule in the lint configuration that disables the rule.
+const ruleOff = "off"
+
+// lintConfig is the lint section of flow.json, or the content of the .cadencelint file, e.g.:
+//
+//	{
+//	  "rules": {
+//	    "unused-variable": "off",
+//	    "hardcoded-address": "error"
+//	  },
+//	  "exclude": ["imports/**", "*_test.cdc"],
+//	  "plugins": "lint-rules"
+//	}
+type lintConfig struct {
+	// Rules maps analyzer names to "off", "warning" or "error".
+	// Rules that are not configured are enabled with their default severity.
+	Rules map[string]string `json:"rules,omitempty"`
+	// Exclude holds the patterns of files that are not linted.
+	Exclude []string `json:"exclude,omitempty"`
+	// Plugins is the directory of custom rules, see pluginRule,
+	// relative to the directory of the configuration file.
+	Plugins string `json:"plugins,omitempty"`
+
+	pluginRules []*pluginRule
+}
+
+// loadLintConfig reads the lint section of the given configuration files,
+// where later files take precedence, or the .cadencelint file if none has a lint section.
+func loadLintConfig(state *flowkit.State, configPaths []string) (*lintConfig, error) {
+	var config *lintConfig
+	// configDir is the directory of the configuration file of the lint configuration
+	configDir := "."
+
+	for _, configPath := range configPaths {
+		data, err := state.ReadFile(configPath)
+		if err != nil {
+			continue
+		}
+
+		var section struct {
+			Lint *lintConfig `json:"lint"`
+		}
+		err = json.Unmarshal(data, &section)
+		if err != nil {
+			return nil, fmt.Errorf("invalid lint configuration in %s: %w", configPath, err)
+		}
+		if section.Lint != nil {
+			config = section.Lint
+			configDir = filepath.Dir(configPath)
+		}
+	}
+
+	if config == nil {
+		data, err := state.ReadFile(lintConfigFile)
+		if errors.Is(err, os.ErrNotExist) {
+			return &lintConfig{}, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		config = &lintConfig{}
+		err = json.Unmarshal(data, config)
+		if err != nil {
+			return nil, fmt.Errorf("invalid lint configuration in %s: %w", lintConfigFile, err)
+		}
+	}
+
+	if config.Plugins != "" {
+		pluginsDir := config.Plugins
+		if !filepath.IsAbs(pluginsDir) {
+			pluginsDir = filepath.Join(configDir, pluginsDir)
+		}
+		pluginRules, err := loadPluginRules(state, pluginsDir)
+		if err != nil {
+			return nil, err
+		}
+		config.pluginRules = pluginRules
+	}
+
+	err := config.validate()
+	if err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+func (c *lintConfig) validate() error {
+	for _, pluginRule := range c.pluginRules {
+		if _, ok := cdclint.Analyzers[pluginRule.Name]; ok {
+			return fmt.Errorf("lint plugin %q has the same name as a built-in rule", pluginRule.Name)
+		}
+	}
+
+	analyzers := c.analyzers()
+	for rule, value := range c.Rules {
+		if _, ok := analyzers[rule]; !ok {
+			return fmt.Errorf(
+				"unknown lint rule %q, available rules are: %s",
+				rule,
+				strings.Join(ruleNames(analyzers), ", "),
+			)
+		}
+
+		switch value {
+		case ruleOff, string(warningSeverity), string(errorSeverity):
+		default:
+			return fmt.Errorf(
+				"invalid value %q for lint rule %q, expected %q, %q or %q",
+				value,
+				rule,
+				ruleOff,
+				warningSeverity,
+				errorSeverity,
+			)
+		}
+	}
+
+	return validateExcludePatterns("lint", c.Exclude)
+}
+
+// validateExcludePatterns returns an error for the first invalid pattern of the section.
+func validateExcludePatterns(section string, patterns []string) error {
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid %s exclude pattern %q: %w", section, pattern, err)
+		}
+	}
+
+	return nil
+}
+
+// analyzers returns the analyzers of the built-in and custom rules by name.
+func (c *lintConfig) analyzers() map[string]*analysis.Analyzer {
+	analyzers := maps.Clone(cdclint.Analyzers)
+	for _, pluginRule := range c.pluginRules {
+		analyzers[pluginRule.Name] = pluginRule.analyzer()
+	}
+	return analyzers
+}
+
+func ruleNames(analyzers map[string]*analysis.Analyzer) []string {
+	names := maps.Keys(analyzers)
+	sort.Strings(names)
+	return names
+}
+
+func (c *lintConfig) ruleEnabled(rule string) bool {
+	return c.Rules[rule] != ruleOff
+}
+
+// ruleSeverity returns the severity configured for the rule,
+// or the severity declared by the custom rule, if any.
+func (c *lintConfig) ruleSeverity(rule string) (severity, bool) {
+	switch value := c.Rules[rule]; value {
+	case string(warningSeverity), string(errorSeverity):
+		return severity(value), true
+	}
+	for _, pluginRule := range c.pluginRules {
+		if pluginRule.Name == rule && pluginRule.Severity != "" {
+			return severity(pluginRule.Severity), true
+		}
+	}
+	return "", false
+}
+
+// isExcluded reports whether the file matches any of the exclude patterns.
+func (c *lintConfig) isExcluded(filePath string) bool {
+	return isExcludedBy(c.Exclude, filePath)
+}
+
+// isExcludedBy reports whether the file matches any of the patterns.
+//
+// Patterns without a slash are matched against the file name, e.g. "*_test.cdc".
+// Other patterns are matched against the path, where a pattern for a directory,
+// or ending with "/**", excludes all files in it.
+func isExcludedBy(patterns []string, filePath string) bool {
+	filePath = filepath.ToSlash(filepath.Clean(filePath))
+
+	for _, pattern := range patterns {
+		pattern = strings.TrimSuffix(path.Clean(filepath.ToSlash(pattern)), "/**")
+
+		if !strings.Contains(pattern, "/") {
+			if match, _ := path.Match(pattern, path.Base(filePath)); match {
+				return true
+			}
+		}
+
+		// Match the path and all of its parent directories
+		for dir := filePath; dir != "." && dir != "/"; dir = path.Dir(dir) {
+			if match, _ := path.Match(pattern, dir); match {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// ruleAnalyzers returns the analyzers of all enabled rules.
+// Their diagnostics are passed to report together with the name of the rule.
+func (c *lintConfig) ruleAnalyzers(report func(rule string, diagnostic analysis.Diagnostic)) []*analysis.Analyzer {
+	ruleAnalyzers := c.analyzers()
+	analyzers := make([]*analysis.Analyzer, 0, len(ruleAnalyzers))
+
+	for _, rule := range ruleNames(ruleAnalyzers) {
+		if !c.ruleEnabled(rule) {
+			continue
+		}
+
+		rule := rule
+		analyzer := ruleAnalyzers[rule]
+		analyzers = append(analyzers, &analysis.Analyzer{
+			Description: analyzer.Description,
+			Requires:    analyzer.Requires,
+			Run: func(pass *analysis.Pass) interface{} {
+				pass.Report = func(diagnostic analysis.Diagnostic) {
+					report(rule, diagnostic)
+				}
+				return analyzer.Run(pass)
+			},
+		})
+	}
+
+	return analyzers
+}
+
+const lintDisableNextLinePrefix = "// lint-disable-next-line"
+
+// disabledRules holds the rules disabled by `// lint-disable-next-line` comments,
+// keyed by the line following the comment. An empty set disables all rules.
+type disabledRules map[int]map[string]struct{}
+
+func parseDisabledRules(code []byte) disabledRules {
+	var disabled disabledRules
+
+	for i, line := range bytes.Split(code, []byte("\n")) {
+		trimmed := string(bytes.TrimSpace(line))
+		if !strings.HasPrefix(trimmed, lintDisableNextLinePrefix) {
+			continue
+		}
+
+		rest := strings.TrimPrefix(trimmed, lintDisableNextLinePrefix)
+		if rest != "" && !strings.HasPrefix(rest, " ") {
+			// e.g. "// lint-disable-next-lines"
+			continue
+		}
+
+		rules := map[string]struct{}{}
+		for _, rule := range strings.FieldsFunc(rest, func(r rune) bool {
+			return r == ',' || r == ' ' || r == '\t'
+		}) {
+			rules[rule] = struct{}{}
+		}
+
+		if disabled == nil {
+			disabled = disabledRules{}
+		}
+		// Lines are 1-based, the comment is on line i+1
+		disabled[i+2] = rules
+	}
+
+	return disabled
+}
+
+func (d disabledRules) isDisabled(line int, rule string) bool {
+	rules, ok := d[line]
+	if !ok {
+		return false
+	}
+	if len(rules) == 0 {
+		return true
+	}
+	_, ok = rules[rule]
+	return ok
+}
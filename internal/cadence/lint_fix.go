@@ -0,0 +1,189 @@
+/*
+ * Flow CLI
+ *
+ * Copyright Flow Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cadence
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/exp/slices"
+
+	"github.com/onflow/cadence/ast"
+	"github.com/onflow/cadence/tools/analysis"
+	"github.com/onflow/flowkit/v2"
+	"github.com/onflow/flowkit/v2/output"
+
+	"github.com/onflow/flow-cli/internal/util"
+)
+
+// fileFix is the code of a file before and after applying the suggested fixes.
+type fileFix struct {
+	FilePath string
+	Code     []byte
+	Fixed    []byte
+	// Fixes is the number of applied fixes
+	Fixes int
+}
+
+// fixFiles applies the suggested fixes of the diagnostics to the code of the linted files,
+// without writing them. Files without fixes are not returned.
+func fixFiles(state *flowkit.State, result *lintResult) ([]fileFix, error) {
+	fixes := make([]fileFix, 0)
+
+	for _, fileResult := range result.Results {
+		if !slices.ContainsFunc(fileResult.Diagnostics, func(diagnostic analysis.Diagnostic) bool {
+			return len(diagnostic.SuggestedFixes) > 0
+		}) {
+			continue
+		}
+
+		code, err := state.ReadFile(fileResult.FilePath)
+		if err != nil {
+			return nil, err
+		}
+
+		fixed, count := applySuggestedFixes(code, fileResult.Diagnostics)
+		if count == 0 {
+			continue
+		}
+
+		fixes = append(fixes, fileFix{
+			FilePath: fileResult.FilePath,
+			Code:     code,
+			Fixed:    fixed,
+			Fixes:    count,
+		})
+	}
+
+	return fixes, nil
+}
+
+// writeFixes writes the fixed code of the files.
+func writeFixes(state *flowkit.State, logger output.Logger, fixes []fileFix) error {
+	total := 0
+	for _, fix := range fixes {
+		err := state.ReaderWriter().WriteFile(fix.FilePath, fix.Fixed, 0644)
+		if err != nil {
+			return fmt.Errorf("failed to write fixes to %s: %w", fix.FilePath, err)
+		}
+		total += fix.Fixes
+	}
+
+	logger.Info(fmt.Sprintf(
+		"%s Applied %d suggested %s to %d %s",
+		output.SuccessEmoji(),
+		total,
+		util.Pluralize("change", total),
+		len(fixes),
+		util.Pluralize("file", len(fixes)),
+	))
+
+	return nil
+}
+
+// fixesDiff returns the changes of the fixes as a unified diff.
+func fixesDiff(fixes []fileFix) string {
+	var sb strings.Builder
+	for _, fix := range fixes {
+		sb.WriteString(util.UnifiedDiff(filepath.ToSlash(fix.FilePath), string(fix.Code), string(fix.Fixed)))
+	}
+	return sb.String()
+}
+
+// applySuggestedFixes applies the first suggested fix of each diagnostic to the code
+// and returns the fixed code and the number of applied fixes.
+//
+// Fixes are applied in the order of the diagnostics. A fix is skipped if any of its edits
+// overlaps with an edit of a fix applied before, the diagnostic is then reported again
+// when linting the fixed code.
+func applySuggestedFixes(code []byte, diagnostics []analysis.Diagnostic) ([]byte, int) {
+	var edits []ast.TextEdit
+	fixes := 0
+
+	for _, diagnostic := range diagnostics {
+		if len(diagnostic.SuggestedFixes) == 0 {
+			continue
+		}
+		fix := diagnostic.SuggestedFixes[0]
+
+		applicable := len(fix.TextEdits) > 0
+		for i, edit := range fix.TextEdits {
+			if !validEdit(code, edit) || overlapsEdits(edit, edits) || overlapsEdits(edit, fix.TextEdits[:i]) {
+				applicable = false
+				break
+			}
+		}
+		if !applicable {
+			continue
+		}
+
+		edits = append(edits, fix.TextEdits...)
+		fixes++
+	}
+
+	// Apply the edits from the end, so the offsets of the remaining edits stay valid
+	sort.Slice(edits, func(i, j int) bool {
+		return edits[i].StartPos.Offset > edits[j].StartPos.Offset
+	})
+
+	fixed := append([]byte(nil), code...)
+	for _, edit := range edits {
+		start, end := editSpan(edit)
+		text := edit.Replacement
+		if edit.Insertion != "" {
+			text = edit.Insertion
+		}
+		fixed = append(fixed[:start], append([]byte(text), fixed[end:]...)...)
+	}
+
+	return fixed, fixes
+}
+
+// editSpan returns the byte offsets of the code replaced by the edit, where the end is exclusive.
+// Insertions replace no code, the range of all other edits is inclusive.
+func editSpan(edit ast.TextEdit) (int, int) {
+	start := edit.StartPos.Offset
+	if edit.Insertion != "" {
+		return start, start
+	}
+	return start, edit.EndPos.Offset + 1
+}
+
+func validEdit(code []byte, edit ast.TextEdit) bool {
+	if edit.Insertion != "" && edit.Replacement != "" {
+		return false
+	}
+	start, end := editSpan(edit)
+	return start >= 0 && start <= end && end <= len(code)
+}
+
+// overlapsEdits reports whether the edit changes code changed by any of the other edits,
+// or inserts at the same position, where the order of the edits would be ambiguous.
+func overlapsEdits(edit ast.TextEdit, others []ast.TextEdit) bool {
+	start, end := editSpan(edit)
+	for _, other := range others {
+		otherStart, otherEnd := editSpan(other)
+		if start == otherStart || (start < otherEnd && otherStart < end) {
+			return true
+		}
+	}
+	return false
+}
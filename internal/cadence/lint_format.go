@@ -0,0 +1,281 @@
+/*
+ * Flow CLI
+ *
+ * Copyright Flow Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cadence
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/onflow/cadence/tools/analysis"
+	"golang.org/x/exp/maps"
+
+	"github.com/onflow/flow-cli/build"
+)
+
+// Output formats of the lint command, in addition to the global ones.
+const (
+	formatSARIF  = "sarif"
+	formatGitHub = "github"
+)
+
+// errorCategoryHelp describes the diagnostic categories not reported by analyzers.
+var errorCategoryHelp = map[string]string{
+	SyntaxErrorCategory:   "The code could not be parsed.",
+	SemanticErrorCategory: "The code is not valid Cadence, e.g. because of a type mismatch or an undeclared name.",
+	ErrorCategory:         "The file could not be linted.",
+}
+
+// categoryHelp returns the help of each category of the diagnostics reported by the rules,
+// from the descriptions of the analyzers of the rules.
+func categoryHelp(analyzers map[string]*analysis.Analyzer, rules map[diagnosticKey]string) map[string]string {
+	descriptions := make(map[string]map[string]struct{})
+	for key, rule := range rules {
+		analyzer, ok := analyzers[rule]
+		if !ok || analyzer.Description == "" {
+			continue
+		}
+		if descriptions[key.category] == nil {
+			descriptions[key.category] = make(map[string]struct{})
+		}
+		descriptions[key.category][strings.TrimSuffix(analyzer.Description, ".")+"."] = struct{}{}
+	}
+	if len(descriptions) == 0 {
+		return nil
+	}
+
+	help := make(map[string]string, len(descriptions))
+	for category, categoryDescriptions := range descriptions {
+		sorted := maps.Keys(categoryDescriptions)
+		sort.Strings(sorted)
+		help[category] = strings.Join(sorted, " ")
+	}
+	return help
+}
+
+// sarifLevels maps the severity of diagnostics to SARIF result levels.
+var sarifLevels = map[severity]string{
+	errorSeverity:   "error",
+	warningSeverity: "warning",
+}
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Version        string      `json:"version"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string             `json:"id"`
+	ShortDescription     sarifMessage       `json:"shortDescription"`
+	Help                 sarifMessage       `json:"help"`
+	HelpURI              string             `json:"helpUri,omitempty"`
+	DefaultConfiguration sarifConfiguration `json:"defaultConfiguration"`
+}
+
+type sarifConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	RuleIndex int             `json:"ruleIndex"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+// sarifRegion holds 1-based lines and columns, the end column is exclusive.
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn"`
+	EndLine     int `json:"endLine"`
+	EndColumn   int `json:"endColumn"`
+}
+
+func diagnosticMessage(diagnostic analysis.Diagnostic) string {
+	if diagnostic.SecondaryMessage == "" {
+		return diagnostic.Message
+	}
+	return fmt.Sprintf("%s %s", diagnostic.Message, diagnostic.SecondaryMessage)
+}
+
+func diagnosticPath(diagnostic analysis.Diagnostic) string {
+	return filepath.ToSlash(diagnostic.Location.String())
+}
+
+// sarif renders the diagnostics as a SARIF log, e.g. for GitHub code scanning.
+func (r *lintResult) sarif() string {
+	var diagnostics []analysis.Diagnostic
+	for _, result := range r.Results {
+		diagnostics = append(diagnostics, result.Diagnostics...)
+	}
+
+	// Each category is reported as a rule
+	categories := make(map[string]analysis.Diagnostic)
+	for _, diagnostic := range diagnostics {
+		// Prefer a diagnostic that links to documentation
+		if existing, ok := categories[diagnostic.Category]; !ok || (existing.URL == "" && diagnostic.URL != "") {
+			categories[diagnostic.Category] = diagnostic
+		}
+	}
+	ruleIDs := make([]string, 0, len(categories))
+	for category := range categories {
+		ruleIDs = append(ruleIDs, category)
+	}
+	sort.Strings(ruleIDs)
+
+	rules := make([]sarifRule, 0, len(ruleIDs))
+	ruleIndices := make(map[string]int, len(ruleIDs))
+	for i, id := range ruleIDs {
+		help, ok := r.categoryHelp[id]
+		if !ok {
+			help, ok = errorCategoryHelp[id]
+		}
+		if !ok {
+			help = fmt.Sprintf("Diagnostics of the %s category.", id)
+		}
+		rules = append(rules, sarifRule{
+			ID:               id,
+			ShortDescription: sarifMessage{Text: help},
+			Help:             sarifMessage{Text: help},
+			HelpURI:          categories[id].URL,
+			DefaultConfiguration: sarifConfiguration{
+				Level: sarifLevels[r.severity(categories[id])],
+			},
+		})
+		ruleIndices[id] = i
+	}
+
+	results := make([]sarifResult, 0, len(diagnostics))
+	for _, diagnostic := range diagnostics {
+		location := sarifPhysicalLocation{
+			ArtifactLocation: sarifArtifactLocation{URI: diagnosticPath(diagnostic)},
+		}
+		if startPos, endPos := diagnostic.Range.StartPos, diagnostic.Range.EndPos; startPos.Line > 0 {
+			location.Region = &sarifRegion{
+				StartLine:   startPos.Line,
+				StartColumn: startPos.Column + 1,
+				EndLine:     endPos.Line,
+				EndColumn:   endPos.Column + 2,
+			}
+		}
+
+		results = append(results, sarifResult{
+			RuleID:    diagnostic.Category,
+			RuleIndex: ruleIndices[diagnostic.Category],
+			Level:     sarifLevels[r.severity(diagnostic)],
+			Message:   sarifMessage{Text: diagnosticMessage(diagnostic)},
+			Locations: []sarifLocation{{PhysicalLocation: location}},
+		})
+	}
+
+	log := sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs: []sarifRun{{
+			Tool: sarifTool{
+				Driver: sarifDriver{
+					Name:           "flow cadence lint",
+					InformationURI: "https://developers.flow.com/tools/flow-cli",
+					Version:        build.Semver(),
+					Rules:          rules,
+				},
+			},
+			Results: results,
+		}},
+	}
+
+	data, _ := json.MarshalIndent(log, "", "  ")
+	return string(data)
+}
+
+var (
+	githubDataEscaper     = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A")
+	githubPropertyEscaper = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C")
+)
+
+// githubAnnotations renders the diagnostics as GitHub Actions workflow commands,
+// which show up as annotations on the changed lines of pull requests.
+func (r *lintResult) githubAnnotations() string {
+	var sb strings.Builder
+
+	for _, result := range r.Results {
+		for _, diagnostic := range result.Diagnostics {
+			properties := []string{
+				fmt.Sprintf("file=%s", githubPropertyEscaper.Replace(diagnosticPath(diagnostic))),
+			}
+			if startPos, endPos := diagnostic.Range.StartPos, diagnostic.Range.EndPos; startPos.Line > 0 {
+				properties = append(properties,
+					fmt.Sprintf("line=%d", startPos.Line),
+					fmt.Sprintf("col=%d", startPos.Column+1),
+					fmt.Sprintf("endLine=%d", endPos.Line),
+					fmt.Sprintf("endColumn=%d", endPos.Column+1),
+				)
+			}
+			properties = append(properties, fmt.Sprintf("title=%s", githubPropertyEscaper.Replace(diagnostic.Category)))
+
+			sb.WriteString(fmt.Sprintf(
+				"::%s %s::%s\n",
+				sarifLevels[r.severity(diagnostic)],
+				strings.Join(properties, ","),
+				githubDataEscaper.Replace(diagnosticMessage(diagnostic)),
+			))
+		}
+	}
+
+	sb.WriteString(r.Oneliner())
+
+	return sb.String()
+}
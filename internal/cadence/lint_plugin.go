@@ -0,0 +1,352 @@
+/*
+ * Flow CLI
+ *
+ * Copyright Flow Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cadence
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/onflow/cadence/ast"
+	"github.com/onflow/cadence/tools/analysis"
+	"github.com/onflow/flowkit/v2"
+)
+
+const pluginRuleExt = ".json"
+
+// pluginRule is a custom lint rule, loaded from a file in the plugins directory
+// configured in the lint section of flow.json, e.g. no-storage-access.json:
+//
+//	{
+//	  "description": "Transactions must not request storage access",
+//	  "message": "transactions should not request auth(Storage) references",
+//	  "severity": "error",
+//	  "match": [
+//	    {"element": "TransactionDeclaration"},
+//	    {"element": "ReferenceType", "text": "auth\\(.*Storage.*\\)"}
+//	  ]
+//	}
+//
+// The name of the rule is the name of the file, without the extension.
+//
+// Each pattern of match selects the elements of the program that match it and are
+// contained in an element selected by the previous pattern. A diagnostic is reported
+// for every element selected by the last pattern, unless require is set. Then a
+// diagnostic is only reported if the element does not contain an element selected by
+// the patterns of require, e.g. for a function that must emit an event:
+//
+//	{
+//	  "message": "withdraw must emit a Withdrawn event",
+//	  "match": [
+//	    {"element": "CompositeDeclaration", "conforms": "FungibleToken.Vault"},
+//	    {"element": "FunctionDeclaration", "identifier": "withdraw"}
+//	  ],
+//	  "require": [
+//	    {"element": "EmitStatement", "text": "Withdrawn"}
+//	  ]
+//	}
+type pluginRule struct {
+	Name        string `json:"-"`
+	Description string `json:"description,omitempty"`
+	Message     string `json:"message"`
+	// Severity is "warning" or "error", defaults to "warning"
+	Severity string           `json:"severity,omitempty"`
+	URL      string           `json:"url,omitempty"`
+	Match    []*pluginPattern `json:"match"`
+	Require  []*pluginPattern `json:"require,omitempty"`
+}
+
+// pluginPattern matches elements of the program, all set fields must match.
+type pluginPattern struct {
+	// Element is the type of the element, e.g. "FunctionDeclaration"
+	Element string `json:"element,omitempty"`
+	// Identifier is the name of the declaration, or of the identifier or member expression
+	Identifier string `json:"identifier,omitempty"`
+	// Conforms is a type the declaration conforms to, e.g. "FungibleToken.Vault"
+	Conforms string `json:"conforms,omitempty"`
+	// Text is a regular expression that must match the code of the element
+	Text string `json:"text,omitempty"`
+
+	elementType ast.ElementType
+	text        *regexp.Regexp
+}
+
+// elementTypes maps the names of all element types to the types, e.g. "FunctionDeclaration".
+var elementTypes = func() map[string]ast.ElementType {
+	elementTypes := make(map[string]ast.ElementType)
+	for elementType := ast.ElementTypeUnknown + 1; ; elementType++ {
+		name, ok := strings.CutPrefix(elementType.String(), "ElementType")
+		if !ok || strings.HasPrefix(name, "(") {
+			return elementTypes
+		}
+		elementTypes[name] = elementType
+	}
+}()
+
+// dirReader is implemented by file systems that can list directories, like the afero file system of the state.
+type dirReader interface {
+	ReadDir(dirname string) ([]os.FileInfo, error)
+}
+
+// loadPluginRules reads the rules of all files in the plugins directory through the file system of the state.
+func loadPluginRules(state *flowkit.State, dir string) ([]*pluginRule, error) {
+	rw, ok := state.ReaderWriter().(dirReader)
+	if !ok {
+		return nil, fmt.Errorf("failed to read lint plugins directory: listing directories is not supported")
+	}
+
+	entries, err := rw.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read lint plugins directory: %w", err)
+	}
+
+	rules := make([]*pluginRule, 0)
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != pluginRuleExt {
+			continue
+		}
+
+		data, err := state.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		rule, err := parsePluginRule(strings.TrimSuffix(entry.Name(), pluginRuleExt), data)
+		if err != nil {
+			return nil, fmt.Errorf("invalid lint plugin %s: %w", filepath.Join(dir, entry.Name()), err)
+		}
+		rules = append(rules, rule)
+	}
+
+	sort.Slice(rules, func(i, j int) bool {
+		return rules[i].Name < rules[j].Name
+	})
+
+	return rules, nil
+}
+
+func parsePluginRule(name string, data []byte) (*pluginRule, error) {
+	rule := &pluginRule{}
+	err := json.Unmarshal(data, rule)
+	if err != nil {
+		return nil, err
+	}
+	rule.Name = name
+
+	if rule.Message == "" {
+		return nil, fmt.Errorf("missing message")
+	}
+	switch rule.Severity {
+	case "", string(warningSeverity), string(errorSeverity):
+	default:
+		return nil, fmt.Errorf("invalid severity %q, expected %q or %q", rule.Severity, warningSeverity, errorSeverity)
+	}
+	if len(rule.Match) == 0 {
+		return nil, fmt.Errorf("missing match patterns")
+	}
+
+	for _, pattern := range append(append([]*pluginPattern{}, rule.Match...), rule.Require...) {
+		err := pattern.compile()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return rule, nil
+}
+
+func (p *pluginPattern) compile() error {
+	if p.Element != "" {
+		elementType, ok := elementTypes[p.Element]
+		if !ok {
+			return fmt.Errorf("unknown element %q", p.Element)
+		}
+		p.elementType = elementType
+	}
+
+	if p.Text != "" {
+		text, err := regexp.Compile(p.Text)
+		if err != nil {
+			return fmt.Errorf("invalid text pattern %q: %w", p.Text, err)
+		}
+		p.text = text
+	}
+
+	return nil
+}
+
+// elementCode returns the code of the element and its offset.
+func elementCode(element ast.Element, code []byte) ([]byte, int) {
+	start := element.StartPosition().Offset
+	end := element.EndPosition(nil).Offset + 1
+	if start < 0 || start > end || end > len(code) {
+		return nil, 0
+	}
+	return code[start:end], start
+}
+
+func (p *pluginPattern) matches(element ast.Element, code []byte) bool {
+	if p.Element != "" && element.ElementType() != p.elementType {
+		return false
+	}
+
+	if p.Identifier != "" {
+		var identifier string
+		switch element := element.(type) {
+		case ast.Declaration:
+			if declarationIdentifier := element.DeclarationIdentifier(); declarationIdentifier != nil {
+				identifier = declarationIdentifier.Identifier
+			}
+		case *ast.IdentifierExpression:
+			identifier = element.Identifier.Identifier
+		case *ast.MemberExpression:
+			identifier = element.Identifier.Identifier
+		}
+		if identifier != p.Identifier {
+			return false
+		}
+	}
+
+	if p.Conforms != "" {
+		declaration, ok := element.(ast.ConformingDeclaration)
+		if !ok {
+			return false
+		}
+		conforms := false
+		for _, conformance := range declaration.ConformanceList() {
+			if conformance.String() == p.Conforms {
+				conforms = true
+				break
+			}
+		}
+		if !conforms {
+			return false
+		}
+	}
+
+	if p.text != nil {
+		elementCode, _ := elementCode(element, code)
+		if !p.text.Match(elementCode) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// findElements calls found for every element contained in the given element,
+// which is selected by the patterns.
+func findElements(element ast.Element, patterns []*pluginPattern, code []byte, found func(ast.Element)) {
+	element.Walk(func(child ast.Element) {
+		if child == nil {
+			return
+		}
+
+		if patterns[0].matches(child, code) {
+			if len(patterns) == 1 {
+				found(child)
+			} else {
+				findElements(child, patterns[1:], code, found)
+			}
+		}
+
+		// Elements matching the pattern may be nested
+		findElements(child, patterns, code, found)
+	})
+}
+
+// analyzer returns an analyzer which reports the elements selected by the rule.
+func (r *pluginRule) analyzer() *analysis.Analyzer {
+	return &analysis.Analyzer{
+		Description: r.Description,
+		Run: func(pass *analysis.Pass) interface{} {
+			program := pass.Program
+			code := program.Code
+
+			reported := make(map[ast.Element]struct{})
+			findElements(program.Program, r.Match, code, func(element ast.Element) {
+				if _, ok := reported[element]; ok {
+					return
+				}
+				reported[element] = struct{}{}
+
+				if len(r.Require) > 0 {
+					satisfied := false
+					findElements(element, r.Require, code, func(ast.Element) {
+						satisfied = true
+					})
+					if satisfied {
+						return
+					}
+				}
+
+				pass.Report(analysis.Diagnostic{
+					Location: program.Location,
+					Category: r.Name,
+					Message:  r.Message,
+					URL:      r.URL,
+					Range:    r.diagnosticRange(element, code),
+				})
+			})
+
+			return nil
+		},
+	}
+}
+
+// diagnosticRange returns the range of the declared identifier of the element, or the range
+// of the code matched by the text pattern of the last match pattern, or the element otherwise.
+func (r *pluginRule) diagnosticRange(element ast.Element, code []byte) ast.Range {
+	if len(r.Require) > 0 {
+		if declaration, ok := element.(ast.Declaration); ok {
+			if identifier := declaration.DeclarationIdentifier(); identifier != nil && identifier.Identifier != "" {
+				return ast.NewRangeFromPositioned(nil, identifier)
+			}
+		}
+	}
+
+	pattern := r.Match[len(r.Match)-1]
+	if len(r.Require) == 0 && pattern.text != nil {
+		elementCode, offset := elementCode(element, code)
+		if match := pattern.text.FindIndex(elementCode); match != nil && match[1] > match[0] {
+			return ast.Range{
+				StartPos: positionAt(code, offset+match[0]),
+				EndPos:   positionAt(code, offset+match[1]-1),
+			}
+		}
+	}
+
+	return ast.NewRangeFromPositioned(nil, element)
+}
+
+// positionAt returns the position of the byte offset in the code.
+func positionAt(code []byte, offset int) ast.Position {
+	line := 1 + strings.Count(string(code[:offset]), "\n")
+	lineStart := strings.LastIndexByte(string(code[:offset]), '\n') + 1
+	return ast.Position{
+		Offset: offset,
+		Line:   line,
+		Column: utf8.RuneCount(code[lineStart:offset]),
+	}
+}
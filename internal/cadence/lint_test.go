@@ -19,14 +19,17 @@
 package cadence
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/onflow/cadence/ast"
 	"github.com/onflow/cadence/common"
+	"github.com/onflow/cadence/errors"
 	"github.com/onflow/cadence/tools/analysis"
 	flowsdk "github.com/onflow/flow-go-sdk"
 	"github.com/onflow/flowkit/v2"
 	"github.com/onflow/flowkit/v2/config"
+	"github.com/onflow/flowkit/v2/output"
 	"github.com/spf13/afero"
 	"github.com/stretchr/testify/require"
 )
@@ -141,6 +144,9 @@ func Test_Lint(t *testing.T) {
 						},
 					},
 				},
+				categoryHelp: map[string]string{
+					"removal-hint": "Detects unnecessary uses of the force operator.",
+				},
 				exitCode: 0,
 			},
 			results,
@@ -197,6 +203,9 @@ func Test_Lint(t *testing.T) {
 						},
 					},
 				},
+				categoryHelp: map[string]string{
+					"removal-hint": "Detects unnecessary uses of the force operator.",
+				},
 				exitCode: 1,
 			},
 			results,
@@ -230,6 +239,48 @@ func Test_Lint(t *testing.T) {
 		require.Equal(t, "1.0", diagnostic.SuggestedFixes[0].TextEdits[0].Replacement)
 	})
 
+	t.Run("disables rules by configuration", func(t *testing.T) {
+		t.Parallel()
+
+		state := setupMockState(t)
+
+		config := &lintConfig{Rules: map[string]string{"unnecessary-force": "off"}}
+		results, err := lintFilesWithConfig(state, config, nil, true, "LintWarning.cdc")
+		require.NoError(t, err)
+
+		require.Empty(t, results.Results[0].Diagnostics)
+		require.Equal(t, 0, results.exitCode)
+	})
+
+	t.Run("configures severity of rules", func(t *testing.T) {
+		t.Parallel()
+
+		state := setupMockState(t)
+
+		config := &lintConfig{Rules: map[string]string{"unnecessary-force": "error"}}
+		results, err := lintFilesWithConfig(state, config, nil, false, "LintWarning.cdc")
+		require.NoError(t, err)
+
+		require.Len(t, results.Results[0].Diagnostics, 1)
+		require.Equal(t, errorSeverity, results.severity(results.Results[0].Diagnostics[0]))
+		require.Equal(t, 1, results.exitCode)
+		require.Equal(t, "1 problem (1 error, 0 warnings)", results.Oneliner())
+	})
+
+	t.Run("honours lint-disable-next-line comments", func(t *testing.T) {
+		t.Parallel()
+
+		state := setupMockState(t)
+
+		results, err := lintFiles(state, false, "LintDisabled.cdc")
+		require.NoError(t, err)
+
+		// Only the comment for another rule does not disable the diagnostic
+		require.Len(t, results.Results[0].Diagnostics, 1)
+		require.Equal(t, "unnecessary force operator", results.Results[0].Diagnostics[0].Message)
+		require.Equal(t, 7, results.Results[0].Diagnostics[0].Range.StartPos.Line)
+	})
+
 	t.Run("linter resolves imports from flowkit state", func(t *testing.T) {
 		t.Parallel()
 
@@ -346,6 +397,9 @@ func Test_Lint(t *testing.T) {
 						},
 					},
 				},
+				categoryHelp: map[string]string{
+					"security": "Detects hardcoded address literals — consider using named address imports for portability.",
+				},
 				exitCode: 0,
 			},
 			results,
@@ -491,6 +545,592 @@ func Test_Lint(t *testing.T) {
 	})
 }
 
+func Test_LintFormats(t *testing.T) {
+	t.Parallel()
+
+	result := &lintResult{
+		Results: []fileResult{
+			{
+				FilePath: "contracts/Foo.cdc",
+				Diagnostics: []analysis.Diagnostic{
+					{
+						Category: "removal-hint",
+						Message:  "unnecessary force operator",
+						Location: common.StringLocation("contracts/Foo.cdc"),
+						Range: ast.Range{
+							StartPos: ast.Position{Line: 4, Column: 11, Offset: 59},
+							EndPos:   ast.Position{Line: 4, Column: 12, Offset: 60},
+						},
+					},
+					{
+						Category:         "semantic-error",
+						Message:          "cannot find variable in this scope: `qqq`",
+						SecondaryMessage: "not found in this scope; check for typos or declare it",
+						Location:         common.StringLocation("contracts/Foo.cdc"),
+						Range: ast.Range{
+							StartPos: ast.Position{Line: 6, Column: 3, Offset: 73},
+							EndPos:   ast.Position{Line: 6, Column: 5, Offset: 75},
+						},
+					},
+				},
+			},
+		},
+		categoryHelp: map[string]string{
+			"removal-hint": "Detects unnecessary uses of the force operator.",
+		},
+		exitCode: 1,
+	}
+
+	t.Run("sarif", func(t *testing.T) {
+		t.Parallel()
+
+		r := *result
+		r.format = formatSARIF
+
+		var log sarifLog
+		require.NoError(t, json.Unmarshal([]byte(r.String()), &log))
+		require.Equal(t, "2.1.0", log.Version)
+		require.Len(t, log.Runs, 1)
+
+		run := log.Runs[0]
+		require.Equal(t,
+			[]sarifRule{
+				{
+					ID:                   "removal-hint",
+					ShortDescription:     sarifMessage{Text: "Detects unnecessary uses of the force operator."},
+					Help:                 sarifMessage{Text: "Detects unnecessary uses of the force operator."},
+					DefaultConfiguration: sarifConfiguration{Level: "warning"},
+				},
+				{
+					ID:                   "semantic-error",
+					ShortDescription:     sarifMessage{Text: errorCategoryHelp["semantic-error"]},
+					Help:                 sarifMessage{Text: errorCategoryHelp["semantic-error"]},
+					DefaultConfiguration: sarifConfiguration{Level: "error"},
+				},
+			},
+			run.Tool.Driver.Rules,
+		)
+		require.Equal(t,
+			[]sarifResult{
+				{
+					RuleID:    "removal-hint",
+					RuleIndex: 0,
+					Level:     "warning",
+					Message:   sarifMessage{Text: "unnecessary force operator"},
+					Locations: []sarifLocation{{
+						PhysicalLocation: sarifPhysicalLocation{
+							ArtifactLocation: sarifArtifactLocation{URI: "contracts/Foo.cdc"},
+							Region:           &sarifRegion{StartLine: 4, StartColumn: 12, EndLine: 4, EndColumn: 14},
+						},
+					}},
+				},
+				{
+					RuleID:    "semantic-error",
+					RuleIndex: 1,
+					Level:     "error",
+					Message:   sarifMessage{Text: "cannot find variable in this scope: `qqq` not found in this scope; check for typos or declare it"},
+					Locations: []sarifLocation{{
+						PhysicalLocation: sarifPhysicalLocation{
+							ArtifactLocation: sarifArtifactLocation{URI: "contracts/Foo.cdc"},
+							Region:           &sarifRegion{StartLine: 6, StartColumn: 4, EndLine: 6, EndColumn: 7},
+						},
+					}},
+				},
+			},
+			run.Results,
+		)
+	})
+
+	t.Run("github", func(t *testing.T) {
+		t.Parallel()
+
+		r := *result
+		r.format = formatGitHub
+
+		require.Equal(t,
+			"::warning file=contracts/Foo.cdc,line=4,col=12,endLine=4,endColumn=13,title=removal-hint::unnecessary force operator\n"+
+				"::error file=contracts/Foo.cdc,line=6,col=4,endLine=6,endColumn=6,title=semantic-error::cannot find variable in this scope: `qqq` not found in this scope; check for typos or declare it\n"+
+				"2 problems (1 error, 1 warning)",
+			r.String(),
+		)
+		require.Equal(t, 1, r.ExitCode())
+	})
+}
+
+func Test_LintConfig(t *testing.T) {
+	t.Parallel()
+
+	t.Run("loads lint section of flow.json", func(t *testing.T) {
+		t.Parallel()
+
+		state := setupMockState(t)
+		require.NoError(t, state.ReaderWriter().WriteFile("flow.json", []byte(`{
+			"lint": {
+				"rules": {"unused-variable": "off", "hardcoded-address": "error"},
+				"exclude": ["imports/**"]
+			}
+		}`), 0644))
+		require.NoError(t, state.ReaderWriter().WriteFile(lintConfigFile, []byte(`{"rules": {"redundant-cast": "off"}}`), 0644))
+
+		config, err := loadLintConfig(state, []string{"flow.json"})
+		require.NoError(t, err)
+		require.Equal(t,
+			&lintConfig{
+				Rules:   map[string]string{"unused-variable": "off", "hardcoded-address": "error"},
+				Exclude: []string{"imports/**"},
+			},
+			config,
+		)
+	})
+
+	t.Run("falls back to .cadencelint", func(t *testing.T) {
+		t.Parallel()
+
+		state := setupMockState(t)
+		require.NoError(t, state.ReaderWriter().WriteFile("flow.json", []byte(`{}`), 0644))
+		require.NoError(t, state.ReaderWriter().WriteFile(lintConfigFile, []byte(`{"rules": {"redundant-cast": "off"}}`), 0644))
+
+		config, err := loadLintConfig(state, []string{"flow.json"})
+		require.NoError(t, err)
+		require.Equal(t, &lintConfig{Rules: map[string]string{"redundant-cast": "off"}}, config)
+	})
+
+	t.Run("defaults without configuration", func(t *testing.T) {
+		t.Parallel()
+
+		state := setupMockState(t)
+
+		config, err := loadLintConfig(state, []string{"flow.json"})
+		require.NoError(t, err)
+		require.Equal(t, &lintConfig{}, config)
+	})
+
+	t.Run("rejects unknown rules and values", func(t *testing.T) {
+		t.Parallel()
+
+		state := setupMockState(t)
+
+		require.NoError(t, state.ReaderWriter().WriteFile("unknown.json", []byte(`{"lint": {"rules": {"no-such-rule": "off"}}}`), 0644))
+		_, err := loadLintConfig(state, []string{"unknown.json"})
+		require.ErrorContains(t, err, `unknown lint rule "no-such-rule"`)
+
+		require.NoError(t, state.ReaderWriter().WriteFile("invalid.json", []byte(`{"lint": {"rules": {"unused-variable": "warn"}}}`), 0644))
+		_, err = loadLintConfig(state, []string{"invalid.json"})
+		require.ErrorContains(t, err, `invalid value "warn" for lint rule "unused-variable"`)
+	})
+
+	t.Run("excludes paths", func(t *testing.T) {
+		t.Parallel()
+
+		config := &lintConfig{Exclude: []string{"imports/**", "*_test.cdc", "cadence/scripts"}}
+
+		require.True(t, config.isExcluded("imports/0x1/Foo.cdc"))
+		require.True(t, config.isExcluded("./cadence/tests/Foo_test.cdc"))
+		require.True(t, config.isExcluded("cadence/scripts/get.cdc"))
+		require.False(t, config.isExcluded("cadence/contracts/Foo.cdc"))
+		require.False(t, config.isExcluded("cadence/imports/Foo.cdc"))
+	})
+}
+
+func Test_LintBaseline(t *testing.T) {
+	t.Parallel()
+
+	logger := output.NewStdoutLogger(output.NoneLog)
+
+	t.Run("records the baseline if it does not exist", func(t *testing.T) {
+		t.Parallel()
+
+		state := setupMockState(t)
+
+		results, err := lintFiles(state, true, "LintWarning.cdc")
+		require.NoError(t, err)
+		require.Equal(t, 1, results.exitCode)
+
+		err = applyBaseline(state, logger, results, "lint-baseline.json", false)
+		require.NoError(t, err)
+		require.Empty(t, results.Results[0].Diagnostics)
+		require.Equal(t, 0, results.computeExitCode(true))
+
+		baseline, err := loadLintBaseline(state, "lint-baseline.json")
+		require.NoError(t, err)
+		require.Len(t, baseline.Issues, 1)
+		require.Equal(t, "LintWarning.cdc", baseline.Issues[0].File)
+		require.Equal(t, "removal-hint", baseline.Issues[0].Rule)
+		require.Equal(t, "unnecessary force operator", baseline.Issues[0].Message)
+		require.Len(t, baseline.Issues[0].Fingerprint, 16)
+	})
+
+	t.Run("reports only new problems", func(t *testing.T) {
+		t.Parallel()
+
+		state := setupMockState(t)
+
+		results, err := lintFiles(state, true, "LintWarning.cdc")
+		require.NoError(t, err)
+		require.NoError(t, newLintBaseline(state, results).save(state, "lint-baseline.json"))
+
+		// The known problem moves to another line, and a new problem is added
+		require.NoError(t, state.ReaderWriter().WriteFile("LintWarning.cdc", []byte(`
+	access(all) contract LintWarning {
+
+		init() {
+			let y = 2!
+			let x = 1!
+			log(x)
+			log(y)
+		}
+	}`), 0644))
+
+		results, err = lintFiles(state, true, "LintWarning.cdc")
+		require.NoError(t, err)
+		require.Len(t, results.Results[0].Diagnostics, 2)
+
+		err = applyBaseline(state, logger, results, "lint-baseline.json", false)
+		require.NoError(t, err)
+		require.Len(t, results.Results[0].Diagnostics, 1)
+		require.Equal(t, 5, results.Results[0].Diagnostics[0].Range.StartPos.Line)
+		require.Equal(t, 1, results.computeExitCode(true))
+	})
+
+	t.Run("counts fixed problems", func(t *testing.T) {
+		t.Parallel()
+
+		state := setupMockState(t)
+
+		results, err := lintFiles(state, false, "LintWarning.cdc")
+		require.NoError(t, err)
+		baseline := newLintBaseline(state, results)
+
+		results, err = lintFiles(state, false, "NoError.cdc")
+		require.NoError(t, err)
+
+		known, fixed := baseline.filter(state, results)
+		require.Equal(t, 0, known)
+		require.Equal(t, 1, fixed)
+	})
+
+	t.Run("rejects invalid baseline", func(t *testing.T) {
+		t.Parallel()
+
+		state := setupMockState(t)
+		require.NoError(t, state.ReaderWriter().WriteFile("lint-baseline.json", []byte(`[]`), 0644))
+
+		_, err := loadLintBaseline(state, "lint-baseline.json")
+		require.ErrorContains(t, err, "invalid lint baseline lint-baseline.json")
+	})
+}
+
+func Test_LintFix(t *testing.T) {
+	t.Parallel()
+
+	t.Run("applies suggested fixes", func(t *testing.T) {
+		t.Parallel()
+
+		state := setupMockState(t)
+
+		results, err := lintFiles(state, false, "NoError.cdc", "ReplacementHint.cdc")
+		require.NoError(t, err)
+
+		fixes, err := fixFiles(state, results)
+		require.NoError(t, err)
+		require.Len(t, fixes, 1)
+		require.Equal(t, "ReplacementHint.cdc", fixes[0].FilePath)
+		require.Equal(t, 1, fixes[0].Fixes)
+
+		require.Equal(t,
+			"--- a/ReplacementHint.cdc\n"+
+				"+++ b/ReplacementHint.cdc\n"+
+				"@@ -1,7 +1,7 @@\n"+
+				" \n"+
+				" \taccess(all) contract ReplacementHint {\n"+
+				" \t\taccess(all) fun test() {\n"+
+				"-\t\t\tlet x = UFix64(1)\n"+
+				"+\t\t\tlet x = 1.0\n"+
+				" \t\t\tlog(x)\n"+
+				" \t\t}\n"+
+				" \t}\n"+
+				"\\ No newline at end of file\n",
+			fixesDiff(fixes),
+		)
+
+		// Fixes are only written by writeFixes
+		code, err := state.ReadFile("ReplacementHint.cdc")
+		require.NoError(t, err)
+		require.Contains(t, string(code), "UFix64(1)")
+
+		err = writeFixes(state, output.NewStdoutLogger(output.NoneLog), fixes)
+		require.NoError(t, err)
+
+		results, err = lintFiles(state, false, "ReplacementHint.cdc")
+		require.NoError(t, err)
+		require.Empty(t, results.Results[0].Diagnostics)
+	})
+
+	t.Run("skips overlapping fixes", func(t *testing.T) {
+		t.Parallel()
+
+		code := []byte("let x = a + b")
+
+		edit := func(start, end int, replacement string) ast.TextEdit {
+			return ast.TextEdit{
+				Replacement: replacement,
+				Range: ast.Range{
+					StartPos: ast.Position{Offset: start},
+					EndPos:   ast.Position{Offset: end},
+				},
+			}
+		}
+		diagnostic := func(edits ...ast.TextEdit) analysis.Diagnostic {
+			return analysis.Diagnostic{
+				SuggestedFixes: []errors.SuggestedFix[ast.TextEdit]{
+					{TextEdits: edits},
+				},
+			}
+		}
+
+		fixed, count := applySuggestedFixes(code, []analysis.Diagnostic{
+			diagnostic(edit(8, 8, "c")),
+			// Overlaps with the first fix
+			diagnostic(edit(8, 12, "d")),
+			diagnostic(edit(12, 12, "e")),
+			diagnostic(ast.TextEdit{
+				Insertion: "var ",
+				Range: ast.Range{
+					StartPos: ast.Position{Offset: 0},
+					EndPos:   ast.Position{Offset: 0},
+				},
+			}),
+			// Out of range
+			diagnostic(edit(12, 13, "f")),
+			{},
+		})
+
+		require.Equal(t, "var let x = c + e", string(fixed))
+		require.Equal(t, 3, count)
+	})
+}
+
+func Test_LintCache(t *testing.T) {
+	t.Parallel()
+
+	t.Run("lints files in parallel", func(t *testing.T) {
+		t.Parallel()
+
+		state := setupMockState(t)
+
+		filePaths := []string{
+			"NoError.cdc",
+			"foo/WithImports.cdc",
+			"LintWarning.cdc",
+			"LintError.cdc",
+			"ReplacementHint.cdc",
+			"ContractWithNestedImports.cdc",
+			"TransactionImportingContractWithNestedImports.cdc",
+		}
+
+		results, err := lintFiles(state, false, filePaths...)
+		require.NoError(t, err)
+		require.Len(t, results.Results, len(filePaths))
+
+		// Results are in the order of the files, and the same as when linting each file alone
+		for i, filePath := range filePaths {
+			result, err := lintFiles(state, false, filePath)
+			require.NoError(t, err)
+			require.Equal(t, result.Results[0], results.Results[i])
+		}
+		require.Equal(t, 1, results.exitCode)
+	})
+
+	t.Run("reuses diagnostics of unchanged files", func(t *testing.T) {
+		t.Parallel()
+
+		state := setupMockState(t)
+		cache := &lintCache{dir: t.TempDir(), salt: "test"}
+		config := &lintConfig{Rules: map[string]string{"unnecessary-force": "error"}}
+
+		results, err := lintFilesWithConfig(state, config, cache, false, "LintWarning.cdc", "ReplacementHint.cdc")
+		require.NoError(t, err)
+
+		cached, err := lintFilesWithConfig(state, config, cache, false, "LintWarning.cdc", "ReplacementHint.cdc")
+		require.NoError(t, err)
+		require.Equal(t, results, cached)
+
+		code, err := state.ReadFile("LintWarning.cdc")
+		require.NoError(t, err)
+		diagnostics, severities, rules, ok := cache.load(state, "LintWarning.cdc", code)
+		require.True(t, ok)
+		require.Equal(t, results.Results[0].Diagnostics, diagnostics)
+		require.Equal(t, map[int]severity{0: errorSeverity}, severities)
+		require.Equal(t, map[int]string{0: "unnecessary-force"}, rules)
+
+		// Changed files are linted again
+		_, _, _, ok = cache.load(state, "LintWarning.cdc", []byte("access(all) contract LintWarning {}"))
+		require.False(t, ok)
+	})
+
+	t.Run("invalidates diagnostics when imported files change", func(t *testing.T) {
+		t.Parallel()
+
+		state := setupMockState(t)
+		cache := &lintCache{dir: t.TempDir(), salt: "test"}
+		filePath := "TransactionImportingContractWithNestedImports.cdc"
+
+		_, err := lintFilesWithConfig(state, &lintConfig{}, cache, false, filePath)
+		require.NoError(t, err)
+
+		code, err := state.ReadFile(filePath)
+		require.NoError(t, err)
+		_, _, _, ok := cache.load(state, filePath, code)
+		require.True(t, ok)
+
+		// Helper.cdc is imported indirectly, through ContractWithNestedImports
+		require.NoError(t, state.ReaderWriter().WriteFile("Helper.cdc", []byte(`
+	access(all) contract Helper {
+		access(all) fun greet(): String {
+			return "Hello"
+		}
+	}
+	`), 0644))
+
+		_, _, _, ok = cache.load(state, filePath, code)
+		require.False(t, ok)
+	})
+}
+
+func Test_LintPlugins(t *testing.T) {
+	t.Parallel()
+
+	noStorage, err := parsePluginRule("no-storage", []byte(`{
+		"message": "transactions must not request storage access",
+		"severity": "error",
+		"match": [
+			{"element": "TransactionDeclaration"},
+			{"element": "ReferenceType", "text": "auth\\([^)]*Storage[^)]*\\)"}
+		]
+	}`))
+	require.NoError(t, err)
+
+	withdrawnEvent, err := parsePluginRule("withdrawn-event", []byte(`{
+		"message": "withdraw must emit a Withdrawn event",
+		"match": [
+			{"element": "CompositeDeclaration", "conforms": "Provider"},
+			{"element": "FunctionDeclaration", "identifier": "withdraw"}
+		],
+		"require": [
+			{"element": "EmitStatement", "text": "Withdrawn"}
+		]
+	}`))
+	require.NoError(t, err)
+
+	config := &lintConfig{pluginRules: []*pluginRule{noStorage, withdrawnEvent}}
+
+	t.Run("reports matched elements", func(t *testing.T) {
+		t.Parallel()
+
+		state := setupMockState(t)
+
+		results, err := lintFilesWithConfig(state, config, nil, false, "PluginTransaction.cdc")
+		require.NoError(t, err)
+
+		require.Equal(t,
+			[]analysis.Diagnostic{
+				{
+					Location: common.StringLocation("PluginTransaction.cdc"),
+					Category: "no-storage",
+					Message:  "transactions must not request storage access",
+					Range: ast.Range{
+						StartPos: ast.Position{Offset: 34, Line: 3, Column: 18},
+						EndPos:   ast.Position{Offset: 46, Line: 3, Column: 30},
+					},
+				},
+			},
+			results.Results[0].Diagnostics,
+		)
+		require.Equal(t, errorSeverity, results.severity(results.Results[0].Diagnostics[0]))
+		require.Equal(t, 1, results.exitCode)
+	})
+
+	t.Run("reports elements without required elements", func(t *testing.T) {
+		t.Parallel()
+
+		state := setupMockState(t)
+
+		results, err := lintFilesWithConfig(state, config, nil, false, "PluginContract.cdc")
+		require.NoError(t, err)
+
+		require.Equal(t,
+			[]analysis.Diagnostic{
+				{
+					Location: common.StringLocation("PluginContract.cdc"),
+					Category: "withdrawn-event",
+					Message:  "withdraw must emit a Withdrawn event",
+					Range: ast.Range{
+						StartPos: ast.Position{Offset: 322, Line: 14, Column: 19},
+						EndPos:   ast.Position{Offset: 329, Line: 14, Column: 26},
+					},
+				},
+			},
+			results.Results[0].Diagnostics,
+		)
+		require.Equal(t, warningSeverity, results.severity(results.Results[0].Diagnostics[0]))
+	})
+
+	t.Run("rules can be disabled", func(t *testing.T) {
+		t.Parallel()
+
+		state := setupMockState(t)
+
+		disabled := &lintConfig{
+			Rules:       map[string]string{"no-storage": "off"},
+			pluginRules: config.pluginRules,
+		}
+		require.NoError(t, disabled.validate())
+
+		results, err := lintFilesWithConfig(state, disabled, nil, false, "PluginTransaction.cdc")
+		require.NoError(t, err)
+		require.Empty(t, results.Results[0].Diagnostics)
+	})
+
+	t.Run("loads plugins directory relative to the configuration", func(t *testing.T) {
+		t.Parallel()
+
+		state := setupMockState(t)
+		rw := state.ReaderWriter()
+		require.NoError(t, rw.WriteFile("project/lint-rules/no-storage.json", []byte(`{
+			"message": "transactions must not request storage access",
+			"match": [{"element": "ReferenceType", "text": "Storage"}]
+		}`), 0644))
+		require.NoError(t, rw.WriteFile("project/lint-rules/README.md", []byte(`Custom lint rules`), 0644))
+		require.NoError(t, rw.WriteFile("project/flow.json", []byte(`{
+			"lint": {"plugins": "lint-rules", "rules": {"no-storage": "error"}}
+		}`), 0644))
+
+		config, err := loadLintConfig(state, []string{"project/flow.json"})
+		require.NoError(t, err)
+		require.Len(t, config.pluginRules, 1)
+		require.Equal(t, "no-storage", config.pluginRules[0].Name)
+	})
+
+	t.Run("rejects invalid rules", func(t *testing.T) {
+		t.Parallel()
+
+		for rule, expectedErr := range map[string]string{
+			`{"match": [{"element": "FunctionDeclaration"}]}`: "missing message",
+			`{"message": "m"}`: "missing match patterns",
+			`{"message": "m", "match": [{"element": "Function"}]}`:                 `unknown element "Function"`,
+			`{"message": "m", "match": [{"text": "("}]}`:                           `invalid text pattern "("`,
+			`{"message": "m", "severity": "info", "match": [{"text": "a"}]}`:       `invalid severity "info"`,
+			`{"message": "m", "match": [{}], "require": [{"element": "Unknown"}]}`: `unknown element "Unknown"`,
+		} {
+			_, err := parsePluginRule("rule", []byte(rule))
+			require.ErrorContains(t, err, expectedErr, rule)
+		}
+
+		config := &lintConfig{pluginRules: []*pluginRule{{Name: "unused-variable"}}}
+		require.ErrorContains(t, config.validate(), "same name as a built-in rule")
+	})
+}
+
 func setupMockState(t *testing.T) *flowkit.State {
 	// Mock file system
 	mockFs := afero.NewMemMapFs()
@@ -522,6 +1162,46 @@ func setupMockState(t *testing.T) *flowkit.State {
 			log(x)
 		}
 	}`), 0644)
+	_ = afero.WriteFile(mockFs, "LintDisabled.cdc", []byte(`
+	access(all) contract LintDisabled {
+		init() {
+			// lint-disable-next-line unnecessary-force
+			let x = 1!
+			// lint-disable-next-line redundant-cast
+			let y = 2!
+			// lint-disable-next-line
+			let z = 3!
+			log(x)
+			log(y)
+			log(z)
+		}
+	}`), 0644)
+	_ = afero.WriteFile(mockFs, "PluginTransaction.cdc", []byte(`
+	transaction {
+		prepare(signer: auth(Storage) &Account) {
+			log(signer.address)
+		}
+	}`), 0644)
+	_ = afero.WriteFile(mockFs, "PluginContract.cdc", []byte(`
+	access(all) contract PluginContract {
+		access(all) event Withdrawn(amount: UFix64)
+
+		access(all) resource interface Provider {}
+
+		access(all) resource Good: Provider {
+			access(all) fun withdraw(amount: UFix64) {
+				emit Withdrawn(amount: amount)
+			}
+		}
+
+		access(all) resource Bad: Provider {
+			access(all) fun withdraw(amount: UFix64) {
+				log(amount)
+			}
+		}
+
+		init() {}
+	}`), 0644)
 	_ = afero.WriteFile(mockFs, "LintError.cdc", []byte(`
 	access(all) contract LintError {
 		init() {
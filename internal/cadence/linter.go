@@ -23,6 +23,7 @@ import (
 	"fmt"
 	"path/filepath"
 	"strings"
+	"sync"
 
 	"github.com/onflow/flow-cli/internal/util"
 
@@ -38,14 +39,41 @@ import (
 	"github.com/onflow/flow-core-contracts/lib/go/contracts"
 	flowGo "github.com/onflow/flow-go-sdk"
 	"github.com/onflow/flowkit/v2"
-	"golang.org/x/exp/maps"
+	"github.com/onflow/flowkit/v2/config"
 )
 
 type linter struct {
-	checkers              map[string]*sema.Checker
+	// checkers holds the checkers of the imported programs, including those still being checked
+	checkers map[string]*sema.Checker
+	// checked holds the checked programs, and may be shared with other linters
+	checked               *checkerCache
 	state                 *flowkit.State
 	checkerStandardConfig *sema.Config
 	checkerScriptConfig   *sema.Config
+	config                *lintConfig
+	// severities holds the severity configured for the rule that reported a diagnostic, if any.
+	severities map[diagnosticKey]severity
+	// rules holds the rule that reported a diagnostic, for the diagnostics reported by analyzers.
+	rules map[diagnosticKey]string
+}
+
+// diagnosticKey identifies a diagnostic of a linted file.
+type diagnosticKey struct {
+	location string
+	category string
+	message  string
+	start    int
+	end      int
+}
+
+func newDiagnosticKey(diagnostic analysis.Diagnostic) diagnosticKey {
+	return diagnosticKey{
+		location: diagnostic.Location.String(),
+		category: diagnostic.Category,
+		message:  diagnostic.Message,
+		start:    diagnostic.Range.StartPos.Offset,
+		end:      diagnostic.Range.EndPos.Offset,
+	}
 }
 
 type positionedError interface {
@@ -60,12 +88,16 @@ const (
 	ErrorCategory         = "error"
 )
 
-var analyzers = maps.Values(cdclint.Analyzers)
-
 func newLinter(state *flowkit.State) *linter {
+	return newLinterWithConfig(state, &lintConfig{})
+}
+
+func newLinterWithConfig(state *flowkit.State, config *lintConfig) *linter {
 	l := &linter{
 		checkers: make(map[string]*sema.Checker),
+		checked:  newCheckerCache(),
 		state:    state,
+		config:   config,
 	}
 
 	// Create checker configs for both standard and script
@@ -78,13 +110,60 @@ func newLinter(state *flowkit.State) *linter {
 
 func (l *linter) lintFile(
 	filePath string,
+	cache *lintCache,
 ) (diagnostics []analysis.Diagnostic, err error) {
 	code, readErr := l.state.ReadFile(filePath)
 	if readErr != nil {
 		return nil, readErr
 	}
 
-	return l.lintCode(code, common.StringLocation(filePath))
+	if cache != nil {
+		diagnostics, severities, rules, ok := cache.load(l.state, filePath, code)
+		if ok {
+			for i, diagnosticSeverity := range severities {
+				l.setSeverity(diagnostics[i], diagnosticSeverity)
+			}
+			for i, rule := range rules {
+				l.setRule(diagnostics[i], rule)
+			}
+			return diagnostics, nil
+		}
+	}
+
+	diagnostics, err = l.lintCode(code, common.StringLocation(filePath))
+	if err != nil {
+		return nil, err
+	}
+
+	if cache != nil {
+		severities := make(map[int]severity)
+		rules := make(map[int]string)
+		for i, diagnostic := range diagnostics {
+			if diagnosticSeverity, ok := l.severities[newDiagnosticKey(diagnostic)]; ok {
+				severities[i] = diagnosticSeverity
+			}
+			if rule, ok := l.rules[newDiagnosticKey(diagnostic)]; ok {
+				rules[i] = rule
+			}
+		}
+		cache.store(l.state, filePath, code, diagnostics, severities, rules, l.checked.dependencies(filePath))
+	}
+
+	return diagnostics, nil
+}
+
+func (l *linter) setSeverity(diagnostic analysis.Diagnostic, diagnosticSeverity severity) {
+	if l.severities == nil {
+		l.severities = make(map[diagnosticKey]severity)
+	}
+	l.severities[newDiagnosticKey(diagnostic)] = diagnosticSeverity
+}
+
+func (l *linter) setRule(diagnostic analysis.Diagnostic, rule string) {
+	if l.rules == nil {
+		l.rules = make(map[diagnosticKey]string)
+	}
+	l.rules[newDiagnosticKey(diagnostic)] = rule
 }
 
 func (l *linter) lintCode(
@@ -157,10 +236,24 @@ func (l *linter) lintCode(
 		Location: checker.Location,
 		Code:     []byte(code),
 	}
-	report := func(diagnostic analysis.Diagnostic) {
+	disabled := parseDisabledRules(code)
+	var mu sync.Mutex
+	report := func(rule string, diagnostic analysis.Diagnostic) {
+		if disabled.isDisabled(diagnostic.Range.StartPos.Line, rule) {
+			return
+		}
+
+		// Analyzers run concurrently
+		mu.Lock()
+		defer mu.Unlock()
+
 		diagnostics = append(diagnostics, diagnostic)
+		l.setRule(diagnostic, rule)
+		if ruleSeverity, ok := l.config.ruleSeverity(rule); ok {
+			l.setSeverity(diagnostic, ruleSeverity)
+		}
 	}
-	analysisProgram.Run(analyzers, report)
+	analysisProgram.Run(l.config.ruleAnalyzers(report), func(analysis.Diagnostic) {})
 
 	// Generate synthetic replacements for replacement category diagnostics
 	// that don't have suggested fixes
@@ -361,12 +454,12 @@ func (l *linter) handleImport(
 			Elaboration: testChecker.Elaboration,
 		}, nil
 	case cdctests.BlockchainHelpersLocation:
-		helpersChecker := cdctests.BlockchainHelpersChecker()
+		helpersChecker := l.checked.blockchainHelpersChecker()
 		return sema.ElaborationImport{
 			Elaboration: helpersChecker.Elaboration,
 		}, nil
 	case stdlib.CryptoContractLocation:
-		cryptoChecker, ok := l.checkers[stdlib.CryptoContractLocation.String()]
+		cryptoChecker, ok := l.checked.get(stdlib.CryptoContractLocation.String())
 		if !ok {
 			cryptoCode := contracts.Crypto()
 			cryptoProgram, err := parser.ParseProgram(nil, cryptoCode, parser.Config{})
@@ -394,7 +487,7 @@ func (l *linter) handleImport(
 				return nil, err
 			}
 
-			l.checkers[stdlib.CryptoContractLocation.String()] = cryptoChecker
+			l.checked.add(stdlib.CryptoContractLocation.String(), cryptoChecker)
 		}
 
 		return sema.ElaborationImport{
@@ -406,14 +499,18 @@ func (l *linter) handleImport(
 			importedLocation = util.NormalizePathLocation(checker.Location, importedLocation)
 		}
 
-		filepath, err := l.resolveImportFilepath(importedLocation, checker.Location)
+		filepath, err := resolveImportFilepath(l.state.Contracts(), importedLocation)
 		if err != nil {
 			return nil, err
 		}
 
 		fileLocation := common.StringLocation(filepath)
+		l.checked.addImport(checker.Location.String(), filepath)
 
 		importedChecker, ok := l.checkers[filepath]
+		if !ok {
+			importedChecker, ok = l.checked.get(filepath)
+		}
 		if !ok {
 			code, err := l.state.ReadFile(filepath)
 			if err != nil {
@@ -441,6 +538,7 @@ func (l *linter) handleImport(
 			if err != nil {
 				return nil, err
 			}
+			l.checked.add(filepath, importedChecker)
 		}
 
 		return sema.ElaborationImport{
@@ -449,18 +547,14 @@ func (l *linter) handleImport(
 	}
 }
 
-func (l *linter) resolveImportFilepath(
-	location common.Location,
-	parentLocation common.Location,
-) (
-	string,
-	error,
-) {
+// resolveImportFilepath returns the file of the imported location:
+// the location of the contract for imports by name, or the path for path imports.
+func resolveImportFilepath(contracts *config.Contracts, location common.Location) (string, error) {
 	switch location := location.(type) {
 	case common.StringLocation:
 		// Resolve by contract name from flowkit config
 		if !strings.Contains(location.String(), ".cdc") {
-			contract, err := l.state.Contracts().ByName(location.String())
+			contract, err := contracts.ByName(location.String())
 			if err != nil {
 				return "", err
 			}
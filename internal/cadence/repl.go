@@ -0,0 +1,500 @@
+/*
+ * Flow CLI
+ *
+ * Copyright Flow Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cadence
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/c-bata/go-prompt"
+	"github.com/logrusorgru/aurora/v4"
+	"github.com/spf13/cobra"
+
+	"github.com/onflow/cadence"
+	"github.com/onflow/cadence/ast"
+	"github.com/onflow/cadence/parser"
+	"github.com/onflow/cadence/parser/lexer"
+	"github.com/onflow/flowkit/v2"
+	"github.com/onflow/flowkit/v2/output"
+
+	"github.com/onflow/flow-cli/internal/command"
+)
+
+var replCommand = &command.Command{
+	Cmd: &cobra.Command{
+		Use:   "repl",
+		Short: "Start a Cadence REPL which runs code against a network of the project",
+		Example: `# Start a REPL which runs code against the emulator
+flow cadence repl
+
+# Run code against a fork of mainnet, e.g. the mainnet-fork network of flow.json
+flow cadence repl --network mainnet-fork
+
+# Imports are resolved through the contracts and dependencies of the project:
+#   1> import "Counter"
+#   2> Counter.count`,
+		Args: cobra.NoArgs,
+	},
+	Flags: &struct{}{},
+	RunS:  startREPL,
+}
+
+// replScriptLocation is the location of the scripts run by the REPL,
+// so imports of files are resolved relative to the project directory.
+const replScriptLocation = "repl.cdc"
+
+// maxReplHistory is the number of inputs kept in the history file.
+const maxReplHistory = 1000
+
+func startREPL(
+	_ []string,
+	_ command.GlobalFlags,
+	logger output.Logger,
+	flow flowkit.Services,
+	state *flowkit.State,
+) (command.Result, error) {
+	projectDir, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+
+	history := newReplHistory(projectDir)
+	entries, err := history.load()
+	if err != nil {
+		logger.Info(fmt.Sprintf("%s Failed to read REPL history: %s", output.WarningEmoji(), err))
+	}
+
+	console := &replConsole{
+		session: newReplSession(flow),
+		network: flow.Network().Name,
+		history: history,
+	}
+	for _, contract := range *state.Contracts() {
+		console.contracts = append(console.contracts, contract.Name)
+	}
+
+	fmt.Printf(
+		"Welcome to the Cadence REPL, running code against the %s network.\n%s\n\n",
+		aurora.Bold(console.network).String(),
+		replAssistanceMessage,
+	)
+
+	p := prompt.New(
+		console.execute,
+		console.suggest,
+		prompt.OptionLivePrefix(console.prefix),
+		prompt.OptionHistory(entries),
+		prompt.OptionSetExitCheckerOnInput(console.exitChecker),
+	)
+	p.Run()
+
+	return nil, nil
+}
+
+// replSession holds the code entered in the REPL.
+//
+// Scripts can not keep state between executions, so the statements of the session
+// are run again by every script, followed by the new input.
+type replSession struct {
+	imports      []string
+	declarations []string
+	statements   []string
+
+	executeScript func(code []byte) (cadence.Value, error)
+}
+
+func newReplSession(flow flowkit.Services) *replSession {
+	return &replSession{
+		executeScript: func(code []byte) (cadence.Value, error) {
+			return flow.ExecuteScript(
+				context.Background(),
+				flowkit.Script{
+					Code:     code,
+					Location: replScriptLocation,
+				},
+				flowkit.ScriptQuery{Latest: true},
+			)
+		},
+	}
+}
+
+// eval runs the input, which holds complete imports and declarations, or statements.
+// The value of the last statement is returned, if it is an expression.
+//
+// The input is only added to the session if it runs successfully.
+func (s *replSession) eval(input string) (cadence.Value, error) {
+	code := []byte(input)
+
+	program, err := parser.ParseProgram(nil, code, parser.Config{})
+	if err == nil && len(program.Declarations()) > 0 && len(program.VariableDeclarations()) == 0 {
+		return nil, s.declare(program, code)
+	}
+
+	statements, errs := parser.ParseStatements(nil, code, parser.Config{})
+	if len(errs) > 0 {
+		return nil, parser.Error{Code: code, Errors: errs}
+	}
+	if len(statements) == 0 {
+		return nil, nil
+	}
+
+	var result string
+	recordResult := false
+	last := statements[len(statements)-1]
+	if expressionStatement, ok := last.(*ast.ExpressionStatement); ok {
+		statements = statements[:len(statements)-1]
+		result = elementSource(code, expressionStatement.Expression)
+
+		// Invocations may change variables of the session, e.g. `numbers.append(1)`
+		_, recordResult = expressionStatement.Expression.(*ast.InvocationExpression)
+	}
+
+	newStatements := make([]string, 0, len(statements)+1)
+	for _, statement := range statements {
+		newStatements = append(newStatements, elementSource(code, statement))
+	}
+
+	value, err := s.executeScript([]byte(s.script(s.imports, s.declarations, append(s.statements, newStatements...), result)))
+	if err != nil {
+		return nil, err
+	}
+
+	if recordResult {
+		newStatements = append(newStatements, result)
+	}
+	s.statements = append(s.statements, newStatements...)
+
+	return value, nil
+}
+
+// declare adds the imports and declarations of the program to the session,
+// if they can be imported and checked on the network.
+func (s *replSession) declare(program *ast.Program, code []byte) error {
+	if len(program.TransactionDeclarations()) > 0 {
+		return fmt.Errorf("transactions can not be run in the REPL, use flow transactions send instead")
+	}
+
+	imports := s.imports
+	declarations := s.declarations
+	for _, declaration := range program.Declarations() {
+		switch declaration.(type) {
+		case *ast.ImportDeclaration:
+			imports = append(imports, elementSource(code, declaration))
+		case *ast.PragmaDeclaration:
+			continue
+		default:
+			declarations = append(declarations, elementSource(code, declaration))
+		}
+	}
+
+	_, err := s.executeScript([]byte(s.script(imports, declarations, s.statements, "")))
+	if err != nil {
+		return err
+	}
+
+	s.imports = imports
+	s.declarations = declarations
+
+	return nil
+}
+
+// script returns the code of a script that runs the statements and returns the result,
+// or nil if there is none.
+func (s *replSession) script(imports []string, declarations []string, statements []string, result string) string {
+	var sb strings.Builder
+
+	for _, importDeclaration := range imports {
+		sb.WriteString(importDeclaration)
+		sb.WriteString("\n")
+	}
+	if len(imports) > 0 {
+		sb.WriteString("\n")
+	}
+
+	for _, declaration := range declarations {
+		sb.WriteString(declaration)
+		sb.WriteString("\n\n")
+	}
+
+	sb.WriteString("access(all) fun main(): AnyStruct {\n")
+	for _, statement := range statements {
+		sb.WriteString(statement)
+		sb.WriteString("\n")
+	}
+	if result == "" {
+		result = "nil"
+	}
+	sb.WriteString(fmt.Sprintf("return %s\n}\n", result))
+
+	return sb.String()
+}
+
+func (s *replSession) reset() {
+	s.imports = nil
+	s.declarations = nil
+	s.statements = nil
+}
+
+// elementSource returns the code of the element.
+func elementSource(code []byte, element ast.HasPosition) string {
+	return string(code[element.StartPosition().Offset : element.EndPosition(nil).Offset+1])
+}
+
+// isInputComplete reports whether all brackets, parentheses and braces of the input are closed.
+func isInputComplete(input string) bool {
+	tokens, err := lexer.Lex([]byte(input), nil)
+	if err != nil {
+		return true
+	}
+	defer tokens.Reclaim()
+
+	unmatched := 0
+	for {
+		token := tokens.Next()
+		switch token.Type {
+		case lexer.TokenEOF:
+			return unmatched <= 0
+		case lexer.TokenBracketOpen, lexer.TokenParenOpen, lexer.TokenBraceOpen:
+			unmatched++
+		case lexer.TokenBracketClose, lexer.TokenParenClose, lexer.TokenBraceClose:
+			unmatched--
+		}
+	}
+}
+
+const replAssistanceMessage = `Type '.help' for assistance.`
+
+var replCommands = []prompt.Suggest{
+	{Text: ".help", Description: "Show help"},
+	{Text: ".code", Description: "Show the script run for the session"},
+	{Text: ".reset", Description: "Remove all imports, declarations and statements"},
+	{Text: ".exit", Description: "Exit the REPL"},
+}
+
+// replConsole reads the input of the REPL and prints the results.
+type replConsole struct {
+	session   *replSession
+	network   string
+	history   *replHistory
+	contracts []string
+	// input holds the lines of incomplete input
+	input string
+	line  int
+}
+
+func (c *replConsole) execute(line string) {
+	if c.input == "" && strings.HasPrefix(strings.TrimSpace(line), ".") {
+		c.handleCommand(strings.TrimSpace(line))
+		return
+	}
+
+	c.input += line + "\n"
+	c.line++
+	if !isInputComplete(c.input) {
+		return
+	}
+
+	input := strings.TrimSpace(c.input)
+	c.input = ""
+	if input == "" {
+		return
+	}
+
+	err := c.history.append(input)
+	if err != nil {
+		printREPLError(fmt.Sprintf("Failed to write REPL history: %s", err))
+	}
+
+	value, err := c.session.eval(input)
+	if err != nil {
+		printREPLError(err.Error())
+		return
+	}
+	if value != nil {
+		if _, ok := value.(cadence.Void); !ok {
+			fmt.Println(aurora.Cyan(value.String()).String())
+		}
+	}
+}
+
+func (c *replConsole) handleCommand(command string) {
+	switch command {
+	case ".help":
+		fmt.Println("Enter imports, declarations and statements to run them against the network.")
+		fmt.Println("Commands are prefixed with a dot. Valid commands are:")
+		for _, replCommand := range replCommands {
+			fmt.Printf("%s\t%s\n", replCommand.Text, replCommand.Description)
+		}
+		fmt.Println("\nPress ^D to exit")
+	case ".code":
+		fmt.Print(c.session.script(c.session.imports, c.session.declarations, c.session.statements, ""))
+	case ".reset":
+		c.session.reset()
+	case ".exit":
+		// Handled by exitChecker
+	default:
+		printREPLError(fmt.Sprintf("Unknown command. %s", replAssistanceMessage))
+	}
+}
+
+func (c *replConsole) exitChecker(input string, breakline bool) bool {
+	return breakline && c.input == "" && strings.TrimSpace(input) == ".exit"
+}
+
+func (c *replConsole) prefix() (string, bool) {
+	separator := '>'
+	if c.input != "" {
+		separator = '.'
+	}
+	return fmt.Sprintf("%s %d%c ", c.network, c.line+1, separator), true
+}
+
+// suggest completes the commands and the names of the contracts in string imports.
+func (c *replConsole) suggest(d prompt.Document) []prompt.Suggest {
+	word := d.GetWordBeforeCursor()
+
+	switch {
+	case strings.HasPrefix(word, ".") && c.input == "":
+		return prompt.FilterHasPrefix(replCommands, word, false)
+
+	case strings.HasPrefix(word, `"`) && strings.HasPrefix(strings.TrimSpace(d.TextBeforeCursor()), "import"):
+		suggests := make([]prompt.Suggest, 0, len(c.contracts))
+		for _, contract := range c.contracts {
+			suggests = append(suggests, prompt.Suggest{Text: fmt.Sprintf("%q", contract)})
+		}
+		return prompt.FilterHasPrefix(suggests, word, false)
+	}
+
+	return nil
+}
+
+func printREPLError(message string) {
+	fmt.Fprintln(os.Stderr, aurora.Red(message).String())
+}
+
+// replHistory stores the inputs of the REPL for a project, as one JSON string per line,
+// in the user cache directory.
+type replHistory struct {
+	path string
+}
+
+func newReplHistory(projectDir string) *replHistory {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return &replHistory{}
+	}
+
+	absDir, err := filepath.Abs(projectDir)
+	if err != nil {
+		absDir = projectDir
+	}
+	hash := sha256.Sum256([]byte(absDir))
+
+	return &replHistory{
+		path: filepath.Join(cacheDir, "flow-cli", "repl", hex.EncodeToString(hash[:8])+".history"),
+	}
+}
+
+// load returns the most recent inputs, oldest first.
+func (h *replHistory) load() ([]string, error) {
+	if h.path == "" {
+		return nil, nil
+	}
+
+	file, err := os.Open(h.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var entries []string
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, 1024*1024)
+	for scanner.Scan() {
+		var entry string
+		if json.Unmarshal(scanner.Bytes(), &entry) != nil {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(entries) > maxReplHistory {
+		entries = entries[len(entries)-maxReplHistory:]
+		h.compact(entries)
+	}
+
+	return entries, nil
+}
+
+// compact rewrites the history file with only the given entries. Failing to compact is
+// not an error, the file is compacted again when the history is loaded next time.
+func (h *replHistory) compact(entries []string) {
+	var sb strings.Builder
+	for _, entry := range entries {
+		data, err := json.Marshal(entry)
+		if err != nil {
+			return
+		}
+		sb.Write(data)
+		sb.WriteString("\n")
+	}
+
+	_ = os.WriteFile(h.path, []byte(sb.String()), 0600)
+}
+
+func (h *replHistory) append(input string) error {
+	if h.path == "" {
+		return nil
+	}
+
+	err := os.MkdirAll(filepath.Dir(h.path), 0700)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(h.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+
+	entry, err := json.Marshal(input)
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+	_, err = file.Write(append(entry, '\n'))
+	closeErr := file.Close()
+	if err != nil {
+		return err
+	}
+	return closeErr
+}
@@ -0,0 +1,174 @@
+/*
+ * Flow CLI
+ *
+ * Copyright Flow Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cadence
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/onflow/cadence"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestReplSession returns a session which records the executed scripts,
+// and fails scripts containing "Unknown".
+func newTestReplSession() (*replSession, *[]string) {
+	var scripts []string
+	session := &replSession{
+		executeScript: func(code []byte) (cadence.Value, error) {
+			scripts = append(scripts, string(code))
+			if strings.Contains(string(code), "Unknown") {
+				return nil, fmt.Errorf("cannot find declaration `Unknown`")
+			}
+			return cadence.NewInt(42), nil
+		},
+	}
+	return session, &scripts
+}
+
+func Test_REPL(t *testing.T) {
+	t.Parallel()
+
+	t.Run("evaluates expressions", func(t *testing.T) {
+		t.Parallel()
+
+		session, scripts := newTestReplSession()
+
+		value, err := session.eval("40 + 2")
+		require.NoError(t, err)
+		require.Equal(t, cadence.NewInt(42), value)
+		require.Equal(t, []string{"access(all) fun main(): AnyStruct {\nreturn 40 + 2\n}\n"}, *scripts)
+		require.Empty(t, session.statements)
+	})
+
+	t.Run("runs statements of the session again", func(t *testing.T) {
+		t.Parallel()
+
+		session, scripts := newTestReplSession()
+
+		_, err := session.eval("let numbers: [Int] = []")
+		require.NoError(t, err)
+		_, err = session.eval("numbers.append(1)")
+		require.NoError(t, err)
+		_, err = session.eval("numbers.length")
+		require.NoError(t, err)
+
+		require.Equal(t,
+			"access(all) fun main(): AnyStruct {\nlet numbers: [Int] = []\nnumbers.append(1)\nreturn numbers.length\n}\n",
+			(*scripts)[2],
+		)
+		require.Equal(t, []string{"let numbers: [Int] = []", "numbers.append(1)"}, session.statements)
+	})
+
+	t.Run("keeps imports and declarations", func(t *testing.T) {
+		t.Parallel()
+
+		session, scripts := newTestReplSession()
+
+		_, err := session.eval(`import "Counter"`)
+		require.NoError(t, err)
+		_, err = session.eval("access(all) fun double(_ n: Int): Int {\n    return n * 2\n}")
+		require.NoError(t, err)
+		_, err = session.eval("double(Counter.count)")
+		require.NoError(t, err)
+
+		require.Equal(t,
+			"import \"Counter\"\n\n"+
+				"access(all) fun double(_ n: Int): Int {\n    return n * 2\n}\n\n"+
+				"access(all) fun main(): AnyStruct {\nreturn double(Counter.count)\n}\n",
+			(*scripts)[2],
+		)
+	})
+
+	t.Run("does not keep failing input", func(t *testing.T) {
+		t.Parallel()
+
+		session, scripts := newTestReplSession()
+
+		_, err := session.eval(`import "Unknown"`)
+		require.ErrorContains(t, err, "cannot find declaration")
+		require.Empty(t, session.imports)
+
+		_, err = session.eval("let x = Unknown()")
+		require.ErrorContains(t, err, "cannot find declaration")
+		require.Empty(t, session.statements)
+
+		_, err = session.eval("let x = ")
+		require.Error(t, err)
+		require.Len(t, *scripts, 2)
+
+		_, err = session.eval("transaction { execute {} }")
+		require.ErrorContains(t, err, "transactions can not be run in the REPL")
+		require.Len(t, *scripts, 2)
+	})
+
+	t.Run("detects incomplete input", func(t *testing.T) {
+		t.Parallel()
+
+		require.True(t, isInputComplete("1 + 2"))
+		require.False(t, isInputComplete("access(all) fun foo() {"))
+		require.False(t, isInputComplete("[1, 2,"))
+		require.True(t, isInputComplete("access(all) fun foo() {\n}"))
+	})
+
+	t.Run("stores history", func(t *testing.T) {
+		t.Parallel()
+
+		history := &replHistory{path: filepath.Join(t.TempDir(), "repl", "project.history")}
+
+		entries, err := history.load()
+		require.NoError(t, err)
+		require.Empty(t, entries)
+
+		require.NoError(t, history.append("let x = 1"))
+		require.NoError(t, history.append("access(all) fun foo() {\n}"))
+
+		entries, err = history.load()
+		require.NoError(t, err)
+		require.Equal(t, []string{"let x = 1", "access(all) fun foo() {\n}"}, entries)
+	})
+
+	t.Run("compacts history", func(t *testing.T) {
+		t.Parallel()
+
+		history := &replHistory{path: filepath.Join(t.TempDir(), "project.history")}
+		for i := range maxReplHistory + 10 {
+			require.NoError(t, history.append(fmt.Sprintf("%d", i)))
+		}
+
+		entries, err := history.load()
+		require.NoError(t, err)
+		require.Len(t, entries, maxReplHistory)
+		require.Equal(t, "10", entries[0])
+
+		data, err := os.ReadFile(history.path)
+		require.NoError(t, err)
+		require.Equal(t, maxReplHistory, strings.Count(string(data), "\n"))
+	})
+
+	t.Run("uses a history file per project", func(t *testing.T) {
+		t.Parallel()
+
+		require.NotEqual(t, newReplHistory("project-a").path, newReplHistory("project-b").path)
+		require.Equal(t, newReplHistory("project-a").path, newReplHistory("project-a").path)
+	})
+}
@@ -119,7 +119,7 @@ func (c Command) AddToParent(parent *cobra.Command) {
 		network, err := resolveHost(state, Flags.Host, Flags.HostNetworkKey, Flags.Network)
 		handleError("Host Error", err)
 
-		clientGateway, err := createGateway(*network)
+		clientGateway, err := CreateGateway(*network)
 		handleError("Gateway Error", err)
 
 		logger := createLogger(Flags.Log, Flags.Format)
@@ -186,8 +186,9 @@ func (c Command) AddToParent(parent *cobra.Command) {
 	parent.AddCommand(c.Cmd)
 }
 
-// createGateway creates a gateway to be used, defaults to grpc but can support others.
-func createGateway(network config.Network) (gateway.Gateway, error) {
+// CreateGateway creates a gateway to be used, defaults to grpc but can support others.
+// The gateway is secure if the network has a key.
+func CreateGateway(network config.Network) (gateway.Gateway, error) {
 	// create secure grpc client if hostNetworkKey provided
 	if network.Key != "" {
 		return gateway.NewSecureGrpcGateway(network)
@@ -718,6 +718,11 @@ func (di *DependencyInstaller) fetchDependenciesWithDepth(dependency config.Depe
 }
 
 func (di *DependencyInstaller) getContractFilePath(address, contractName string) string {
+	return ContractFilePath(address, contractName)
+}
+
+// ContractFilePath returns the path relative to the project at which the contract of a dependency is installed.
+func ContractFilePath(address, contractName string) string {
 	fileName := fmt.Sprintf("%s.cdc", contractName)
 	return filepath.Join("imports", address, fileName)
 }
@@ -0,0 +1,431 @@
+/*
+ * Flow CLI
+ *
+ * Copyright Flow Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mcp
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/onflow/cadence/ast"
+	"github.com/onflow/cadence/parser"
+	"github.com/onflow/cadence/tools/analysis"
+
+	"github.com/onflow/flow-cli/internal/cadence"
+)
+
+// Review rule names for the Cadence pitfall checks.
+// Findings produced by the cadence-tools analyzers use the diagnostic category instead.
+const (
+	ruleAccessAllField              = "access-all-field"
+	ruleEntitledCapabilityPublished = "entitled-capability-published"
+	ruleForceUnwrap                 = "force-unwrap"
+)
+
+const (
+	reviewSeverityError   = "error"
+	reviewSeverityWarning = "warning"
+	reviewSeverityInfo    = "info"
+)
+
+// reviewPosition is a position in the reviewed code.
+// Line is 1-based, column and offset are 0-based.
+type reviewPosition struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+	Offset int `json:"offset"`
+}
+
+type reviewRange struct {
+	Start reviewPosition `json:"start"`
+	End   reviewPosition `json:"end"`
+}
+
+type reviewEdit struct {
+	Range       reviewRange `json:"range"`
+	Replacement string      `json:"replacement,omitempty"`
+	Insertion   string      `json:"insertion,omitempty"`
+}
+
+type reviewFix struct {
+	Message string       `json:"message"`
+	Edits   []reviewEdit `json:"edits"`
+}
+
+type reviewFinding struct {
+	Rule     string      `json:"rule"`
+	Severity string      `json:"severity"`
+	Message  string      `json:"message"`
+	Detail   string      `json:"detail,omitempty"`
+	Range    reviewRange `json:"range"`
+	Fixes    []reviewFix `json:"suggestedFixes,omitempty"`
+}
+
+type reviewResult struct {
+	Findings []reviewFinding `json:"findings"`
+	Errors   int             `json:"errors"`
+	Warnings int             `json:"warnings"`
+	Infos    int             `json:"infos"`
+}
+
+// reviewCode runs the cadence-tools lint analyzers and the pitfall checks on the given code.
+func (m *mcpContext) reviewCode(code string) (*reviewResult, error) {
+	diagnostics, err := cadence.LintCode(code, m.state)
+	if err != nil {
+		return nil, err
+	}
+
+	findings := make([]reviewFinding, 0, len(diagnostics))
+	for _, diagnostic := range diagnostics {
+		findings = append(findings, findingFromDiagnostic(diagnostic))
+	}
+
+	// The pitfall checks only need the AST, so they still run on code that does not type-check.
+	program, _ := parser.ParseProgram(nil, []byte(code), parser.Config{})
+	if program != nil {
+		for _, finding := range reviewProgram(program, code) {
+			if !hasFindingAt(findings, finding.Range) {
+				findings = append(findings, finding)
+			}
+		}
+	}
+
+	return newReviewResult(findings), nil
+}
+
+func newReviewResult(findings []reviewFinding) *reviewResult {
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].Range.Start.Offset != findings[j].Range.Start.Offset {
+			return findings[i].Range.Start.Offset < findings[j].Range.Start.Offset
+		}
+		return findings[i].Rule < findings[j].Rule
+	})
+
+	result := &reviewResult{Findings: findings}
+	for _, finding := range findings {
+		switch finding.Severity {
+		case reviewSeverityError:
+			result.Errors++
+		case reviewSeverityWarning:
+			result.Warnings++
+		default:
+			result.Infos++
+		}
+	}
+	return result
+}
+
+func findingFromDiagnostic(diagnostic analysis.Diagnostic) reviewFinding {
+	severity := reviewSeverityWarning
+	switch diagnostic.Category {
+	case cadence.ErrorCategory, cadence.SemanticErrorCategory, cadence.SyntaxErrorCategory:
+		severity = reviewSeverityError
+	}
+
+	finding := reviewFinding{
+		Rule:     diagnostic.Category,
+		Severity: severity,
+		Message:  diagnostic.Message,
+		Detail:   diagnostic.SecondaryMessage,
+		Range:    newReviewRange(diagnostic.Range),
+	}
+
+	for _, fix := range diagnostic.SuggestedFixes {
+		edits := make([]reviewEdit, 0, len(fix.TextEdits))
+		for _, edit := range fix.TextEdits {
+			edits = append(edits, reviewEdit{
+				Range:       newReviewRange(edit.Range),
+				Replacement: edit.Replacement,
+				Insertion:   edit.Insertion,
+			})
+		}
+		finding.Fixes = append(finding.Fixes, reviewFix{
+			Message: fix.Message,
+			Edits:   edits,
+		})
+	}
+
+	return finding
+}
+
+func hasFindingAt(findings []reviewFinding, r reviewRange) bool {
+	for _, finding := range findings {
+		if finding.Range == r {
+			return true
+		}
+	}
+	return false
+}
+
+func newReviewRange(r ast.Range) reviewRange {
+	return reviewRange{
+		Start: newReviewPosition(r.StartPos),
+		End:   newReviewPosition(r.EndPos),
+	}
+}
+
+func newReviewPosition(pos ast.Position) reviewPosition {
+	return reviewPosition{
+		Line:   pos.Line,
+		Column: pos.Column,
+		Offset: pos.Offset,
+	}
+}
+
+// reviewProgram checks the program for common Cadence pitfalls
+// that are not covered by the cadence-tools analyzers.
+func reviewProgram(program *ast.Program, code string) []reviewFinding {
+	var findings []reviewFinding
+
+	// Capabilities issued for authorized references, keyed by the name of the variable holding them.
+	entitledCapabilities := map[string]*ast.ReferenceType{}
+
+	inspector := ast.NewInspector(program)
+	inspector.WithStack(
+		nil,
+		func(element ast.Element, push bool, stack []ast.Element) bool {
+			if !push {
+				return true
+			}
+
+			switch element := element.(type) {
+			case *ast.FieldDeclaration:
+				if finding := reviewField(element, code); finding != nil {
+					findings = append(findings, *finding)
+				}
+
+			case *ast.VariableDeclaration:
+				if invocation, ok := element.Value.(*ast.InvocationExpression); ok {
+					if referenceType := issuedAuthorizedReference(invocation); referenceType != nil {
+						entitledCapabilities[element.Identifier.Identifier] = referenceType
+					}
+				}
+
+			case *ast.InvocationExpression:
+				if finding := reviewPublish(element, entitledCapabilities, code); finding != nil {
+					findings = append(findings, *finding)
+				}
+
+			case *ast.ForceExpression:
+				var parent ast.Element
+				if len(stack) > 1 {
+					parent = stack[len(stack)-2]
+				}
+				findings = append(findings, reviewForce(element, parent, code))
+			}
+
+			return true
+		},
+	)
+
+	return findings
+}
+
+func reviewField(field *ast.FieldDeclaration, code string) *reviewFinding {
+	if field.Access != ast.AccessAll {
+		return nil
+	}
+
+	name := field.Identifier.Identifier
+	var message, detail string
+
+	switch {
+	case field.VariableKind == ast.VariableKindVariable:
+		message = fmt.Sprintf("field `%s` is declared `access(all) var`", name)
+		detail = "Anyone can read this mutable state, and any function of the type can reassign it. " +
+			"Restrict the field and expose a getter, or guard writes with an entitlement."
+
+	case isCapabilityOrAuthReference(field.TypeAnnotation):
+		message = fmt.Sprintf("field `%s` exposes a capability or authorized reference with `access(all)`", name)
+		detail = "Anyone who can reference the enclosing value can use it. " +
+			"Restrict the field to `access(self)` or `access(contract)`, or require an entitlement."
+
+	default:
+		return nil
+	}
+
+	finding := &reviewFinding{
+		Rule:     ruleAccessAllField,
+		Severity: reviewSeverityWarning,
+		Message:  message,
+		Detail:   detail,
+		Range:    newReviewRange(field.Range),
+	}
+
+	// Only offer a fix when the modifier is spelled out where the declaration starts
+	const accessAll = "access(all)"
+	start := field.StartPos.Offset
+	if start >= 0 && strings.HasPrefix(code[start:], accessAll) {
+		startPos := field.StartPos
+		endPos := startPos.Shifted(nil, len(accessAll)-1)
+		finding.Fixes = []reviewFix{
+			{
+				Message: "Restrict the field to `access(self)`",
+				Edits: []reviewEdit{
+					{
+						Range:       newReviewRange(ast.NewUnmeteredRange(startPos, endPos)),
+						Replacement: "access(self)",
+					},
+				},
+			},
+		}
+	}
+
+	return finding
+}
+
+func isCapabilityOrAuthReference(annotation *ast.TypeAnnotation) bool {
+	if annotation == nil {
+		return false
+	}
+
+	ty := annotation.Type
+	if optional, ok := ty.(*ast.OptionalType); ok {
+		ty = optional.Type
+	}
+
+	switch ty := ty.(type) {
+	case *ast.ReferenceType:
+		return ty.Authorization != nil
+	case *ast.InstantiationType:
+		nominal, ok := ty.Type.(*ast.NominalType)
+		return ok && nominal.Identifier.Identifier == "Capability"
+	case *ast.NominalType:
+		return ty.Identifier.Identifier == "Capability"
+	}
+	return false
+}
+
+// issuedAuthorizedReference returns the borrow type of a `capabilities.*.issue<T>(...)` invocation,
+// if it is an authorized reference type.
+func issuedAuthorizedReference(invocation *ast.InvocationExpression) *ast.ReferenceType {
+	member, ok := invocation.InvokedExpression.(*ast.MemberExpression)
+	if !ok || member.Identifier.Identifier != "issue" || len(invocation.TypeArguments) != 1 {
+		return nil
+	}
+
+	annotation := invocation.TypeArguments[0]
+	if annotation == nil {
+		return nil
+	}
+
+	referenceType, ok := annotation.Type.(*ast.ReferenceType)
+	if !ok || referenceType.Authorization == nil {
+		return nil
+	}
+	return referenceType
+}
+
+func reviewPublish(
+	invocation *ast.InvocationExpression,
+	entitledCapabilities map[string]*ast.ReferenceType,
+	code string,
+) *reviewFinding {
+	member, ok := invocation.InvokedExpression.(*ast.MemberExpression)
+	if !ok || member.Identifier.Identifier != "publish" || len(invocation.Arguments) == 0 {
+		return nil
+	}
+
+	var referenceType *ast.ReferenceType
+	switch argument := invocation.Arguments[0].Expression.(type) {
+	case *ast.IdentifierExpression:
+		referenceType = entitledCapabilities[argument.Identifier.Identifier]
+	case *ast.InvocationExpression:
+		referenceType = issuedAuthorizedReference(argument)
+	}
+	if referenceType == nil {
+		return nil
+	}
+
+	finding := &reviewFinding{
+		Rule:     ruleEntitledCapabilityPublished,
+		Severity: reviewSeverityError,
+		Message:  "published capability carries entitlements",
+		Detail: fmt.Sprintf(
+			"Public capabilities can be borrowed by anyone. Issue the published capability for an unauthorized "+
+				"reference and keep `%s` capabilities private.",
+			sourceText(code, referenceType),
+		),
+		Range: newReviewRange(ast.NewRangeFromPositioned(nil, invocation)),
+	}
+
+	if referenceType.Type != nil {
+		finding.Fixes = []reviewFix{
+			{
+				Message: "Issue the capability without entitlements",
+				Edits: []reviewEdit{
+					{
+						Range:       newReviewRange(ast.NewRangeFromPositioned(nil, referenceType)),
+						Replacement: "&" + sourceText(code, referenceType.Type),
+					},
+				},
+			},
+		}
+	}
+
+	return finding
+}
+
+func reviewForce(force *ast.ForceExpression, parent ast.Element, code string) reviewFinding {
+	operand := sourceText(code, force.Expression)
+
+	panicMessage := "unexpected nil value"
+	if !strings.ContainsAny(operand, "\"\\\n") {
+		panicMessage = fmt.Sprintf("%s is nil", operand)
+	}
+	replacement := fmt.Sprintf(`%s ?? panic("%s")`, operand, panicMessage)
+
+	// The nil-coalescing operator binds weaker than most expressions,
+	// so parenthesize unless the force expression stands on its own.
+	switch parent.(type) {
+	case *ast.VariableDeclaration, *ast.ReturnStatement, *ast.ExpressionStatement, *ast.AssignmentStatement:
+	default:
+		replacement = "(" + replacement + ")"
+	}
+
+	r := ast.NewRangeFromPositioned(nil, force)
+	return reviewFinding{
+		Rule:     ruleForceUnwrap,
+		Severity: reviewSeverityInfo,
+		Message:  "force-unwrap aborts with a generic error when the value is nil",
+		Detail:   "Use the nil-coalescing operator with an explicit panic message, or handle the nil case with `if let`.",
+		Range:    newReviewRange(r),
+		Fixes: []reviewFix{
+			{
+				Message: "Replace with a nil-coalescing panic",
+				Edits: []reviewEdit{
+					{
+						Range:       newReviewRange(r),
+						Replacement: replacement,
+					},
+				},
+			},
+		},
+	}
+}
+
+// sourceText returns the source code of the given positioned element.
+func sourceText(code string, element ast.HasPosition) string {
+	start := element.StartPosition().Offset
+	end := element.EndPosition(nil).Offset + 1
+	if start < 0 || end > len(code) || start >= end {
+		return ""
+	}
+	return code[start:end]
+}
@@ -108,7 +108,15 @@ func registerTools(s *mcpserver.MCPServer, mctx *mcpContext) {
 		)
 	}
 
-	// Audit / network tools — always registered.
+	// Review / audit / network tools — always registered.
+	s.AddTool(
+		mcplib.NewTool("cadence_code_review",
+			mcplib.WithDescription("Review Cadence code for common issues. Runs the Cadence lint analyzers and checks for pitfalls such as access(all) fields, published entitled capabilities and force-unwraps. Returns JSON findings with ranges (1-based lines, 0-based columns) and suggested fixes."),
+			mcplib.WithString("code", mcplib.Required(), mcplib.Description("Cadence source code to review")),
+		),
+		mctx.cadenceCodeReview,
+	)
+
 	s.AddTool(
 		mcplib.NewTool("get_contract_source",
 			mcplib.WithDescription("Fetch on-chain contract manifest (names and sizes) for a Flow account"),
@@ -249,6 +257,28 @@ func (m *mcpContext) cadenceCompletion(_ context.Context, req mcplib.CallToolReq
 	return mcplib.NewToolResultText(b.String()), nil
 }
 
+// ---------------------------------------------------------------------------
+// Review tool handlers
+// ---------------------------------------------------------------------------
+
+func (m *mcpContext) cadenceCodeReview(_ context.Context, req mcplib.CallToolRequest) (*mcplib.CallToolResult, error) {
+	code, err := resolveCode(req)
+	if err != nil {
+		return mcplib.NewToolResultError(err.Error()), nil
+	}
+
+	result, err := m.reviewCode(code)
+	if err != nil {
+		return mcplib.NewToolResultError(fmt.Sprintf("code review failed: %v", err)), nil
+	}
+
+	data, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return mcplib.NewToolResultError(fmt.Sprintf("failed to marshal result: %v", err)), nil
+	}
+	return mcplib.NewToolResultText(string(data)), nil
+}
+
 // ---------------------------------------------------------------------------
 // Audit / network tool handlers
 // ---------------------------------------------------------------------------
@@ -20,6 +20,7 @@ package mcp
 
 import (
 	"context"
+	"encoding/json"
 	"testing"
 
 	mcplib "github.com/mark3labs/mcp-go/mcp"
@@ -105,3 +106,90 @@ access(all) contract MyContract {
 	textContent := result.Content[0].(mcplib.TextContent)
 	assert.Contains(t, textContent.Text, "MyContract")
 }
+
+func TestTool_CadenceCodeReview(t *testing.T) {
+	t.Parallel()
+	mctx := &mcpContext{}
+
+	req := mcplib.CallToolRequest{}
+	req.Params.Arguments = map[string]any{
+		"code": `
+access(all) contract Counter {
+	access(all) var count: Int
+
+	access(all) fun get(_ value: Int?): Int {
+		return value!
+	}
+
+	init() {
+		self.count = 0
+	}
+}
+`,
+	}
+
+	result, err := mctx.cadenceCodeReview(context.Background(), req)
+	require.NoError(t, err)
+	require.NotNil(t, result)
+	assert.False(t, result.IsError)
+
+	var review reviewResult
+	textContent := result.Content[0].(mcplib.TextContent)
+	require.NoError(t, json.Unmarshal([]byte(textContent.Text), &review))
+
+	rules := make([]string, 0, len(review.Findings))
+	for _, finding := range review.Findings {
+		rules = append(rules, finding.Rule)
+	}
+	assert.Equal(t, []string{ruleAccessAllField, ruleForceUnwrap}, rules)
+
+	field := review.Findings[0]
+	assert.Equal(t, 3, field.Range.Start.Line)
+	require.Len(t, field.Fixes, 1)
+	assert.Equal(t, "access(self)", field.Fixes[0].Edits[0].Replacement)
+
+	force := review.Findings[1]
+	require.Len(t, force.Fixes, 1)
+	assert.Equal(t, `value ?? panic("value is nil")`, force.Fixes[0].Edits[0].Replacement)
+}
+
+func TestTool_CadenceCodeReview_EntitledCapabilityPublished(t *testing.T) {
+	t.Parallel()
+	mctx := &mcpContext{}
+
+	code := `
+transaction {
+	prepare(signer: auth(Capabilities) &Account) {
+		let cap = signer.capabilities.storage.issue<auth(Mutate) &[Int]>(/storage/numbers)
+		signer.capabilities.publish(cap, at: /public/numbers)
+	}
+}
+`
+	review, err := mctx.reviewCode(code)
+	require.NoError(t, err)
+
+	var published *reviewFinding
+	for i, finding := range review.Findings {
+		if finding.Rule == ruleEntitledCapabilityPublished {
+			published = &review.Findings[i]
+		}
+	}
+	require.NotNil(t, published)
+	assert.Equal(t, reviewSeverityError, published.Severity)
+	assert.Equal(t, 5, published.Range.Start.Line)
+	require.Len(t, published.Fixes, 1)
+	assert.Equal(t, "&[Int]", published.Fixes[0].Edits[0].Replacement)
+}
+
+func TestTool_CadenceCodeReview_MissingCode(t *testing.T) {
+	t.Parallel()
+	mctx := &mcpContext{}
+
+	req := mcplib.CallToolRequest{}
+	req.Params.Arguments = map[string]any{}
+
+	result, err := mctx.cadenceCodeReview(context.Background(), req)
+	require.NoError(t, err)
+	require.NotNil(t, result)
+	assert.True(t, result.IsError)
+}
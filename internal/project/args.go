@@ -0,0 +1,628 @@
+/*
+ * Flow CLI
+ *
+ * Copyright Flow Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package project
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/onflow/cadence"
+	"github.com/onflow/cadence/ast"
+	"github.com/onflow/cadence/common"
+	jsoncdc "github.com/onflow/cadence/encoding/json"
+	"github.com/onflow/cadence/parser"
+	flowsdk "github.com/onflow/flow-go-sdk"
+	"golang.org/x/exp/slices"
+	"gopkg.in/yaml.v3"
+
+	"github.com/onflow/flowkit/v2"
+	"github.com/onflow/flowkit/v2/arguments"
+	"github.com/onflow/flowkit/v2/output"
+
+	"github.com/onflow/flow-cli/internal/util"
+)
+
+// argsDir is the directory of the project with the initializer arguments of contracts,
+// in files named after the contracts, optionally in a directory named after the network.
+var argsDir = filepath.Join(".flow", "args")
+
+var argsFileExtensions = []string{".json", ".yaml", ".yml"}
+
+var envVariable = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)}`)
+
+// initParameter is a parameter of the initializer of a contract.
+type initParameter struct {
+	Name string
+	Type ast.Type
+}
+
+func (p initParameter) String() string {
+	return fmt.Sprintf("%s: %s", p.Name, p.Type.String())
+}
+
+// resolveInitArguments sets the initializer arguments of the contracts deployed on the network
+// from their args files, and validates the arguments of every contract against its initializer.
+//
+// Invalid arguments only fail contracts which are added to their accounts,
+// as the initializer of contracts which are deployed already is not run again.
+//
+// The deployments of the state are changed in memory only, for the rest of the command.
+func resolveInitArguments(
+	ctx context.Context,
+	flow flowkit.Services,
+	state *flowkit.State,
+	logger output.Logger,
+	lookupEnv func(string) (string, bool),
+) error {
+	network := flow.Network()
+	contracts, err := state.DeploymentContractsByNetwork(network)
+	if err != nil {
+		return err
+	}
+
+	rw := state.ReaderWriter()
+	deployed := make(map[flowsdk.Address]map[string][]byte)
+	var problems []string
+	for _, contract := range contracts {
+		params, err := contractInitParameters(contract.Code())
+		if err != nil {
+			continue // the deployment reports the invalid contract
+		}
+
+		args := contract.Args
+		path := findArgsFile(rw, network.Name, contract.Name)
+		if path != "" {
+			if len(args) > 0 {
+				return fmt.Errorf("contract %s has arguments in the deployment and in %s, remove one of them", contract.Name, path)
+			}
+			args, err = loadArgsFile(rw, path, network.Name, params, lookupEnv)
+			if err != nil {
+				return err
+			}
+		}
+
+		args, errs := checkInitArguments(params, args)
+		setDeploymentArgs(state, network.Name, contract.AccountName, contract.Name, args)
+		if len(errs) == 0 {
+			continue
+		}
+
+		if isDeployed(ctx, flow, deployed, contract.AccountAddress, contract.Name) {
+			for _, err := range errs {
+				logger.Info(fmt.Sprintf(
+					"%s %s (%s): %s, ignored as the contract is deployed already",
+					output.WarningEmoji(),
+					contract.Name,
+					contract.Location(),
+					err,
+				))
+			}
+			continue
+		}
+		for _, err := range errs {
+			problems = append(problems, fmt.Sprintf("%s (%s): %s", contract.Name, contract.Location(), err))
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf(
+			"invalid initializer arguments, nothing has been deployed:\n  - %s",
+			strings.Join(problems, "\n  - "),
+		)
+	}
+	return nil
+}
+
+// isDeployed returns whether the contract is deployed on the account, keeping the deployed contracts of the accounts.
+// Contracts are not deployed on accounts which can not be fetched.
+func isDeployed(
+	ctx context.Context,
+	flow flowkit.Services,
+	deployed map[flowsdk.Address]map[string][]byte,
+	address flowsdk.Address,
+	contract string,
+) bool {
+	contracts, ok := deployed[address]
+	if !ok {
+		account, err := flow.GetAccount(ctx, address)
+		if err == nil {
+			contracts = account.Contracts
+		}
+		deployed[address] = contracts
+	}
+	_, ok = contracts[contract]
+	return ok
+}
+
+func setDeploymentArgs(state *flowkit.State, network string, account string, contract string, args []cadence.Value) {
+	for _, d := range state.Deployments().ByNetwork(network) {
+		if d.Account != account {
+			continue
+		}
+		for i, c := range d.Contracts {
+			if c.Name == contract {
+				d.Contracts[i].Args = args
+			}
+		}
+		state.Deployments().AddOrUpdate(d)
+	}
+}
+
+// contractInitParameters returns the parameters of the initializer of the contract declared in the code,
+// which has none if the code declares a contract interface.
+func contractInitParameters(code []byte) ([]initParameter, error) {
+	program, err := parser.ParseProgram(nil, code, parser.Config{})
+	if err != nil {
+		return nil, err
+	}
+
+	var params []initParameter
+	for _, declaration := range program.CompositeDeclarations() {
+		if declaration.CompositeKind != common.CompositeKindContract {
+			continue
+		}
+		for _, initializer := range declaration.Members.Initializers() {
+			for _, param := range initializer.FunctionDeclaration.ParameterList.Parameters {
+				params = append(params, initParameter{
+					Name: param.Identifier.Identifier,
+					Type: param.TypeAnnotation.Type,
+				})
+			}
+		}
+	}
+	return params, nil
+}
+
+// findArgsFile returns the args file of the contract for the network, preferring the file
+// of the network to the file shared by all networks, or nothing if the contract has no args file.
+func findArgsFile(rw flowkit.ReaderWriter, network string, contract string) string {
+	for _, dir := range []string{filepath.Join(argsDir, network), argsDir} {
+		for _, extension := range argsFileExtensions {
+			path := filepath.Join(dir, contract+extension)
+			if _, err := rw.Stat(path); err == nil {
+				return path
+			}
+		}
+	}
+	return ""
+}
+
+// loadArgsFile reads the arguments of the args file, after substituting the environment variables.
+//
+// JSON files contain the arguments in JSON-Cadence, YAML files map the parameter names to their values.
+func loadArgsFile(
+	rw flowkit.ReaderWriter,
+	path string,
+	network string,
+	params []initParameter,
+	lookupEnv func(string) (string, bool),
+) ([]cadence.Value, error) {
+	data, err := rw.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read args file %s: %w", path, err)
+	}
+
+	data, err = substituteEnv(data, network, lookupEnv)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load args file %s: %w", path, err)
+	}
+
+	var args []cadence.Value
+	if filepath.Ext(path) == ".json" {
+		args, err = arguments.ParseJSON(string(data))
+	} else {
+		args, err = parseYAMLArgs(data, params)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse args file %s: %w", path, err)
+	}
+	return args, nil
+}
+
+// substituteEnv replaces the ${NAME} references with the value of the environment variable
+// NAME_<NETWORK> if it is set, for values which differ by network, and NAME otherwise.
+func substituteEnv(data []byte, network string, lookupEnv func(string) (string, bool)) ([]byte, error) {
+	suffix := "_" + strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(network))
+
+	var missing []string
+	substituted := envVariable.ReplaceAllFunc(data, func(reference []byte) []byte {
+		name := string(envVariable.FindSubmatch(reference)[1])
+		if value, ok := lookupEnv(name + suffix); ok {
+			return []byte(value)
+		}
+		if value, ok := lookupEnv(name); ok {
+			return []byte(value)
+		}
+		missing = append(missing, fmt.Sprintf("%s or %s", name+suffix, name))
+		return reference
+	})
+
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("environment variables are not set: %s", strings.Join(missing, ", "))
+	}
+	return substituted, nil
+}
+
+// parseYAMLArgs converts the values of the YAML mapping of parameter names to values
+// to the types of the parameters.
+func parseYAMLArgs(data []byte, params []initParameter) ([]cadence.Value, error) {
+	var document yaml.Node
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, err
+	}
+	if len(document.Content) == 0 {
+		return nil, nil
+	}
+
+	mapping := document.Content[0]
+	if mapping.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("expected a mapping of parameter names to values")
+	}
+
+	values := make(map[string]*yaml.Node)
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		values[mapping.Content[i].Value] = mapping.Content[i+1]
+	}
+
+	args := make([]cadence.Value, 0, len(params))
+	for _, param := range params {
+		node, ok := values[param.Name]
+		if !ok {
+			return nil, fmt.Errorf("missing value for parameter `%s`", param.Name)
+		}
+		delete(values, param.Name)
+
+		value, err := yamlValue(node, param.Type)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for parameter `%s`: %w", param.Name, err)
+		}
+		args = append(args, value)
+	}
+
+	for name := range values {
+		return nil, fmt.Errorf("unknown parameter `%s`", name)
+	}
+	return args, nil
+}
+
+// yamlValue converts the YAML value to the Cadence type.
+func yamlValue(node *yaml.Node, typ ast.Type) (cadence.Value, error) {
+	switch t := typ.(type) {
+	case *ast.OptionalType:
+		if node.Tag == "!!null" {
+			return cadence.NewOptional(nil), nil
+		}
+		value, err := yamlValue(node, t.Type)
+		if err != nil {
+			return nil, err
+		}
+		return cadence.NewOptional(value), nil
+
+	case *ast.VariableSizedType, *ast.ConstantSizedType:
+		if node.Kind != yaml.SequenceNode {
+			return nil, fmt.Errorf("expected a list for %s", typ.String())
+		}
+		values := make([]cadence.Value, 0, len(node.Content))
+		for _, element := range node.Content {
+			value, err := yamlValue(element, arrayElementType(t))
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+		}
+		return cadence.NewArray(values), nil
+
+	case *ast.DictionaryType:
+		if node.Kind != yaml.MappingNode {
+			return nil, fmt.Errorf("expected a mapping for %s", typ.String())
+		}
+		pairs := make([]cadence.KeyValuePair, 0, len(node.Content)/2)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, err := yamlValue(node.Content[i], t.KeyType)
+			if err != nil {
+				return nil, err
+			}
+			value, err := yamlValue(node.Content[i+1], t.ValueType)
+			if err != nil {
+				return nil, err
+			}
+			pairs = append(pairs, cadence.KeyValuePair{Key: key, Value: value})
+		}
+		return cadence.NewDictionary(pairs), nil
+
+	case *ast.NominalType:
+		if node.Kind != yaml.ScalarNode {
+			return nil, fmt.Errorf("expected a value for %s", typ.String())
+		}
+		return scalarValue(node.Value, t.String())
+	}
+
+	return nil, fmt.Errorf("values of type %s can only be given in JSON-Cadence", typ.String())
+}
+
+// scalarValue converts the text to a value of the primitive type.
+func scalarValue(text string, typeName string) (cadence.Value, error) {
+	switch typeName {
+	case "String":
+		return cadence.NewString(text)
+	case "Bool":
+		value, err := strconv.ParseBool(text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid Bool %q", text)
+		}
+		return cadence.NewBool(value), nil
+	case "StoragePath", "PublicPath", "PrivatePath", "Path", "CapabilityPath":
+		parts := strings.Split(strings.TrimPrefix(text, "/"), "/")
+		if !strings.HasPrefix(text, "/") || len(parts) != 2 || common.PathDomainFromIdentifier(parts[0]) == common.PathDomainUnknown {
+			return nil, fmt.Errorf("invalid path %q", text)
+		}
+		return cadence.Path{Domain: common.PathDomainFromIdentifier(parts[0]), Identifier: parts[1]}, nil
+	}
+
+	if _, ok := numericTypes[typeName]; ok || typeName == "Address" || typeName == "Character" {
+		encoded := text
+		switch {
+		case typeName == "Address" && !strings.HasPrefix(encoded, "0x"):
+			encoded = "0x" + encoded
+		case slices.Contains(abstractTypes["FixedPoint"], typeName) && !strings.Contains(encoded, "."):
+			encoded += ".0"
+		}
+
+		// the text is decoded as JSON-Cadence, which parses every number type
+		data, err := json.Marshal(map[string]string{"type": typeName, "value": encoded})
+		if err != nil {
+			return nil, err
+		}
+		value, err := jsoncdc.Decode(nil, data)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s %q", typeName, text)
+		}
+		return value, nil
+	}
+
+	return nil, fmt.Errorf("values of type %s can only be given in JSON-Cadence", typeName)
+}
+
+var numericTypes = map[string]cadence.Type{
+	"Int": cadence.IntType, "Int8": cadence.Int8Type, "Int16": cadence.Int16Type, "Int32": cadence.Int32Type,
+	"Int64": cadence.Int64Type, "Int128": cadence.Int128Type, "Int256": cadence.Int256Type,
+	"UInt": cadence.UIntType, "UInt8": cadence.UInt8Type, "UInt16": cadence.UInt16Type, "UInt32": cadence.UInt32Type,
+	"UInt64": cadence.UInt64Type, "UInt128": cadence.UInt128Type, "UInt256": cadence.UInt256Type,
+	"Word8": cadence.Word8Type, "Word16": cadence.Word16Type, "Word32": cadence.Word32Type,
+	"Word64": cadence.Word64Type, "Word128": cadence.Word128Type, "Word256": cadence.Word256Type,
+	"Fix64": cadence.Fix64Type, "Fix128": cadence.Fix128Type, "UFix64": cadence.UFix64Type, "UFix128": cadence.UFix128Type,
+}
+
+var primitiveTypes = map[string]cadence.Type{
+	"String": cadence.StringType, "Character": cadence.CharacterType, "Bool": cadence.BoolType, "Address": cadence.AddressType,
+	"Path": cadence.PathType, "CapabilityPath": cadence.CapabilityPathType, "StoragePath": cadence.StoragePathType,
+	"PublicPath": cadence.PublicPathType, "PrivatePath": cadence.PrivatePathType,
+	"AnyStruct": cadence.AnyStructType, "HashableStruct": cadence.HashableStructType,
+	"Number": cadence.NumberType, "SignedNumber": cadence.SignedNumberType, "Integer": cadence.IntegerType,
+	"SignedInteger": cadence.SignedIntegerType, "FixedSizeUnsignedInteger": cadence.FixedSizeUnsignedIntegerType,
+	"FixedPoint": cadence.FixedPointType, "SignedFixedPoint": cadence.SignedFixedPointType,
+}
+
+// abstractTypes are the primitive types of the values of abstract types.
+var abstractTypes = map[string][]string{
+	"Number":                   {"Int", "Int8", "Int16", "Int32", "Int64", "Int128", "Int256", "UInt", "UInt8", "UInt16", "UInt32", "UInt64", "UInt128", "UInt256", "Word8", "Word16", "Word32", "Word64", "Word128", "Word256", "Fix64", "Fix128", "UFix64", "UFix128"},
+	"SignedNumber":             {"Int", "Int8", "Int16", "Int32", "Int64", "Int128", "Int256", "Fix64", "Fix128"},
+	"Integer":                  {"Int", "Int8", "Int16", "Int32", "Int64", "Int128", "Int256", "UInt", "UInt8", "UInt16", "UInt32", "UInt64", "UInt128", "UInt256", "Word8", "Word16", "Word32", "Word64", "Word128", "Word256"},
+	"SignedInteger":            {"Int", "Int8", "Int16", "Int32", "Int64", "Int128", "Int256"},
+	"FixedSizeUnsignedInteger": {"UInt8", "UInt16", "UInt32", "UInt64", "UInt128", "UInt256", "Word8", "Word16", "Word32", "Word64", "Word128", "Word256"},
+	"FixedPoint":               {"Fix64", "Fix128", "UFix64", "UFix128"},
+	"SignedFixedPoint":         {"Fix64", "Fix128"},
+	"Path":                     {"StoragePath", "PublicPath", "PrivatePath"},
+	"CapabilityPath":           {"PublicPath", "PrivatePath"},
+}
+
+// cadenceType returns the Cadence type of the parameter type, if it is built from primitive types.
+func cadenceType(typ ast.Type) (cadence.Type, bool) {
+	switch t := typ.(type) {
+	case *ast.OptionalType:
+		inner, ok := cadenceType(t.Type)
+		return cadence.NewOptionalType(inner), ok
+	case *ast.VariableSizedType:
+		element, ok := cadenceType(t.Type)
+		return cadence.NewVariableSizedArrayType(element), ok
+	case *ast.ConstantSizedType:
+		element, ok := cadenceType(t.Type)
+		return cadence.NewConstantSizedArrayType(uint(t.Size.Value.Uint64()), element), ok
+	case *ast.DictionaryType:
+		key, keyOk := cadenceType(t.KeyType)
+		value, valueOk := cadenceType(t.ValueType)
+		return cadence.NewDictionaryType(key, value), keyOk && valueOk
+	case *ast.NominalType:
+		if primitive, ok := numericTypes[t.String()]; ok {
+			return primitive, true
+		}
+		primitive, ok := primitiveTypes[t.String()]
+		return primitive, ok
+	}
+	return nil, false
+}
+
+// checkInitArguments checks the arguments against the parameters of the initializer,
+// returning the arguments with the types of their arrays and dictionaries set from the parameters.
+func checkInitArguments(params []initParameter, args []cadence.Value) ([]cadence.Value, []error) {
+	if len(args) != len(params) {
+		signature := make([]string, 0, len(params))
+		for _, param := range params {
+			signature = append(signature, param.String())
+		}
+		return args, []error{fmt.Errorf(
+			"the initializer init(%s) takes %d %s, but %d %s given",
+			strings.Join(signature, ", "),
+			len(params),
+			util.Pluralize("argument", len(params)),
+			len(args),
+			wasOrWere(len(args)),
+		)}
+	}
+
+	checked := make([]cadence.Value, 0, len(args))
+	var errs []error
+	for i, param := range params {
+		value, err := checkInitArgument(args[i], param.Type)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("parameter `%s`: %w", param.Name, err))
+		}
+		checked = append(checked, value)
+	}
+	return checked, errs
+}
+
+func wasOrWere(count int) string {
+	if count == 1 {
+		return "was"
+	}
+	return "were"
+}
+
+func arrayElementType(typ ast.Type) ast.Type {
+	switch t := typ.(type) {
+	case *ast.VariableSizedType:
+		return t.Type
+	case *ast.ConstantSizedType:
+		return t.Type
+	}
+	return nil
+}
+
+// checkInitArgument checks the value has the parameter type, as far as it can be checked
+// without the imported types, and sets the types of arrays and dictionaries without one.
+func checkInitArgument(value cadence.Value, typ ast.Type) (cadence.Value, error) {
+	mismatch := fmt.Errorf("expected %s, found %s", typ.String(), valueTypeName(value))
+
+	switch t := typ.(type) {
+	case *ast.OptionalType:
+		optional, ok := value.(cadence.Optional)
+		if !ok {
+			return checkInitArgument(value, t.Type) // values are implicitly optional
+		}
+		if optional.Value == nil {
+			return optional, nil
+		}
+		inner, err := checkInitArgument(optional.Value, t.Type)
+		if err != nil {
+			return value, err
+		}
+		return cadence.NewOptional(inner), nil
+
+	case *ast.VariableSizedType, *ast.ConstantSizedType:
+		array, ok := value.(cadence.Array)
+		if !ok {
+			return value, mismatch
+		}
+		if constant, ok := t.(*ast.ConstantSizedType); ok && uint64(len(array.Values)) != constant.Size.Value.Uint64() {
+			return value, fmt.Errorf("expected %s, found %d elements", typ.String(), len(array.Values))
+		}
+		for i, element := range array.Values {
+			checked, err := checkInitArgument(element, arrayElementType(t))
+			if err != nil {
+				return value, fmt.Errorf("element %d: %w", i, err)
+			}
+			array.Values[i] = checked
+		}
+		if array.ArrayType == nil {
+			if arrayType, ok := cadenceType(typ); ok {
+				array = array.WithType(arrayType.(cadence.ArrayType))
+			}
+		}
+		return array, nil
+
+	case *ast.DictionaryType:
+		dictionary, ok := value.(cadence.Dictionary)
+		if !ok {
+			return value, mismatch
+		}
+		for i, pair := range dictionary.Pairs {
+			key, err := checkInitArgument(pair.Key, t.KeyType)
+			if err != nil {
+				return value, fmt.Errorf("key %d: %w", i, err)
+			}
+			element, err := checkInitArgument(pair.Value, t.ValueType)
+			if err != nil {
+				return value, fmt.Errorf("value %d: %w", i, err)
+			}
+			dictionary.Pairs[i] = cadence.KeyValuePair{Key: key, Value: element}
+		}
+		if dictionary.DictionaryType == nil {
+			if dictionaryType, ok := cadenceType(typ); ok {
+				dictionary = dictionary.WithType(dictionaryType.(*cadence.DictionaryType))
+			}
+		}
+		return dictionary, nil
+
+	case *ast.NominalType:
+		name := t.String()
+		found := valueTypeName(value)
+		switch {
+		case name == "AnyStruct" || name == "HashableStruct":
+			return value, nil
+		case abstractTypes[name] != nil:
+			for _, concrete := range abstractTypes[name] {
+				if found == concrete {
+					return value, nil
+				}
+			}
+			return value, mismatch
+		case numericTypes[name] != nil || primitiveTypes[name] != nil:
+			if found != name {
+				return value, mismatch
+			}
+			return value, nil
+		default: // composite types are identified by their location
+			if found != name && !strings.HasSuffix(found, "."+name) {
+				return value, mismatch
+			}
+			return value, nil
+		}
+	}
+
+	// references, intersections and capabilities are checked on deployment
+	return value, nil
+}
+
+func valueTypeName(value cadence.Value) string {
+	switch v := value.(type) {
+	case cadence.Array:
+		if v.ArrayType == nil {
+			return "array"
+		}
+	case cadence.Dictionary:
+		if v.DictionaryType == nil {
+			return "dictionary"
+		}
+	case cadence.Optional:
+		if v.Value == nil {
+			return "nil"
+		}
+		return valueTypeName(v.Value) + "?"
+	}
+	if value == nil || value.Type() == nil {
+		return "unknown"
+	}
+	return value.Type().ID()
+}
@@ -0,0 +1,135 @@
+/*
+ * Flow CLI
+ *
+ * Copyright Flow Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package project
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/onflow/flowkit/v2"
+	"github.com/onflow/flowkit/v2/output"
+
+	"github.com/onflow/flow-cli/internal/command"
+)
+
+var CheckUpgradeCommand = &command.Command{
+	Cmd: &cobra.Command{
+		Use:     "check-upgrade",
+		Short:   "Check updates of deployed contracts against the Cadence contract updatability rules",
+		Example: "flow project check-upgrade --network testnet",
+		Args:    cobra.NoArgs,
+	},
+	Flags: &struct{}{},
+	RunS:  checkUpgrade,
+}
+
+func checkUpgrade(
+	_ []string,
+	_ command.GlobalFlags,
+	logger output.Logger,
+	flow flowkit.Services,
+	state *flowkit.State,
+) (command.Result, error) {
+	logger.StartProgress(fmt.Sprintf("Checking contract updates on network %s...", flow.Network().Name))
+	defer logger.StopProgress()
+
+	result, err := checkContractUpdates(context.Background(), flow, state)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// checkContractUpdates fetches the code of the contracts deployed on the network
+// and validates the update to the local code of each of them, without sending anything.
+func checkContractUpdates(ctx context.Context, flow flowkit.Services, state *flowkit.State) (*upgradeCheckResult, error) {
+	contracts, err := compareDeployment(ctx, flow, state)
+	if err != nil {
+		return nil, err
+	}
+
+	return &upgradeCheckResult{
+		network:   flow.Network().Name,
+		contracts: contracts,
+	}, nil
+}
+
+type upgradeCheckResult struct {
+	network   string
+	contracts []*contractPlan
+}
+
+var _ command.ResultWithExitCode = &upgradeCheckResult{}
+
+func (r *upgradeCheckResult) JSON() any {
+	result := make(map[string]any)
+	for _, contract := range r.contracts {
+		errs := contract.Reasons
+		if errs == nil {
+			errs = []string{}
+		}
+		result[contract.Name] = map[string]any{
+			"address": contract.Address.HexWithPrefix(),
+			"status":  contract.Status,
+			"errors":  errs,
+		}
+	}
+	return result
+}
+
+func (r *upgradeCheckResult) String() string {
+	var b strings.Builder
+
+	_, _ = fmt.Fprintf(&b, "Contract updates on network %s checked against the Cadence updatability rules:\n\n", r.network)
+	for _, contract := range r.contracts {
+		switch contract.Status {
+		case planStatusNew:
+			_, _ = fmt.Fprintf(&b, "   %s is not deployed on %s yet\n", contract.Name, contract.Address.HexWithPrefix())
+		case planStatusUnchanged:
+			_, _ = fmt.Fprintf(&b, "   %s on %s is unchanged\n", contract.Name, contract.Address.HexWithPrefix())
+		case planStatusUpdate:
+			_, _ = fmt.Fprintf(&b, "%s %s on %s can be updated\n", output.OkEmoji(), contract.Name, contract.Address.HexWithPrefix())
+		case planStatusBlocked:
+			_, _ = fmt.Fprintf(&b, "%s %s on %s can not be updated:\n", output.ErrorEmoji(), contract.Name, contract.Address.HexWithPrefix())
+			for _, reason := range contract.Reasons {
+				_, _ = fmt.Fprintf(&b, "    - %s\n", reason)
+			}
+		}
+	}
+
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+func (r *upgradeCheckResult) Oneliner() string {
+	return fmt.Sprintf(
+		"%d can be updated, %d can not be updated",
+		countStatus(r.contracts, planStatusUpdate),
+		countStatus(r.contracts, planStatusBlocked),
+	)
+}
+
+func (r *upgradeCheckResult) ExitCode() int {
+	if countStatus(r.contracts, planStatusBlocked) > 0 {
+		return 1
+	}
+	return 0
+}
@@ -22,6 +22,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"os"
 	"strings"
 
 	"github.com/onflow/flow-cli/internal/prompt"
@@ -39,11 +40,18 @@ import (
 	"github.com/onflow/flowkit/v2/project"
 
 	"github.com/onflow/flow-cli/internal/command"
+	"github.com/onflow/flow-cli/internal/util"
 )
 
 type flagsDeploy struct {
-	Update   bool `flag:"update" default:"false" info:"use update flag to update existing contracts"`
-	ShowDiff bool `flag:"show-diff" default:"false" info:"use show-diff flag to show diff between existing and new contracts on update"`
+	Update    bool     `flag:"update" default:"false" info:"use update flag to update existing contracts, after checking the updates against the contract updatability rules"`
+	ShowDiff  bool     `flag:"show-diff" default:"false" info:"use show-diff flag to show diff between existing and new contracts on update"`
+	Plan      bool     `flag:"plan" default:"false" info:"show what the deployment would do on the network without sending any transaction"`
+	Contracts []string `flag:"contract" default:"" info:"deploy only the named contracts and the contracts they import, can be repeated"`
+	Accounts  []string `flag:"account" default:"" info:"deploy only the contracts of the named accounts and the contracts they import, can be repeated"`
+	BuildOnly bool     `flag:"build-only" default:"false" info:"write the unsigned deployment transactions to files to be signed offline, without sending them"`
+	BuildDir  string   `flag:"build-dir" default:"" info:"directory to write the unsigned deployment transactions to, defaults to .flow/transactions/<network>"`
+	Submit    string   `flag:"submit" default:"" info:"send the signed deployment transactions built in the directory, in order"`
 }
 
 var deployFlags = flagsDeploy{}
@@ -52,7 +60,8 @@ var DeployCommand = &command.Command{
 	Cmd: &cobra.Command{
 		Use:     "deploy",
 		Short:   "Deploy Cadence contracts",
-		Example: "flow project deploy --network testnet",
+		Long:    "Deploy Cadence contracts.\n\nContract initializer arguments are read from the deployment or from an args file .flow/args/[<network>/]<contract>.(json|yaml), in JSON-Cadence or as a YAML mapping of parameter names to values. References to environment variables like ${NAME} are replaced with NAME_<NETWORK> or NAME.",
+		Example: "flow project deploy --network testnet\nflow project deploy --network testnet --update --plan\nflow project deploy --network testnet --update --contract Counter\nflow project deploy --network mainnet --build-only\nflow project deploy --network mainnet --submit .flow/transactions/mainnet",
 	},
 	Flags: &deployFlags,
 	RunS:  deploy,
@@ -66,6 +75,37 @@ func deploy(
 	state *flowkit.State,
 ) (command.Result, error) {
 
+	if deployFlags.Submit != "" {
+		if deployFlags.BuildOnly {
+			return nil, fmt.Errorf("the --build-only and --submit flags can not be used together")
+		}
+		result, err := submitDeployment(context.Background(), logger, flow, state, deployFlags.Submit)
+		if err != nil {
+			return nil, err
+		}
+		return result, nil
+	}
+
+	if len(deployFlags.Contracts) > 0 || len(deployFlags.Accounts) > 0 {
+		selection, err := selectDeployment(state, flow.Network(), deployFlags.Contracts, deployFlags.Accounts)
+		if err != nil {
+			return nil, err
+		}
+		logger.Info(selection.String())
+	}
+
+	if err := resolveInitArguments(context.Background(), flow, state, logger, os.LookupEnv); err != nil {
+		return nil, err
+	}
+
+	if deployFlags.Plan {
+		plan, err := planDeployment(context.Background(), logger, flow, state, deployFlags.Update)
+		if err != nil {
+			return nil, err
+		}
+		return plan, nil
+	}
+
 	if flow.Network() == config.MainnetNetwork { // if using mainnet check for standard contract usage
 		err := checkForStandardContractUsageOnMainnet(state, logger, global.Yes)
 		if err != nil {
@@ -73,12 +113,64 @@ func deploy(
 		}
 	}
 
-	deployFunc := flowkit.UpdateExistingContract(deployFlags.Update)
-	if deployFlags.ShowDiff {
+	if deployFlags.BuildOnly {
+		dir := deployFlags.BuildDir
+		if dir == "" {
+			dir = defaultBuildDir(flow.Network().Name)
+		}
+		result, err := buildDeployment(context.Background(), flow, state, dir, deployFlags.Update)
+		if err != nil {
+			return nil, err
+		}
+		return result, nil
+	}
+
+	result, err := deployContracts(
+		context.Background(),
+		logger,
+		flow,
+		state,
+		deployFlags.Update,
+		deployFlags.ShowDiff,
+		"flow project deploy --update",
+	)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// deployContracts deploys the contracts of the network and records the deployment history,
+// checking the updates of existing contracts first if they are updated.
+func deployContracts(
+	ctx context.Context,
+	logger output.Logger,
+	flow flowkit.Services,
+	state *flowkit.State,
+	update bool,
+	showDiff bool,
+	updateCommand string,
+) (*deployResult, error) {
+	if update { // check the updates before paying for transactions the network would reject
+		check, err := checkContractUpdates(ctx, flow, state)
+		if err != nil {
+			return nil, err
+		}
+		if check.ExitCode() != 0 {
+			logger.Info(check.String())
+			return nil, fmt.Errorf("contract updates are not compatible with the deployed contracts, nothing has been deployed")
+		}
+	}
+
+	deployFunc := flowkit.UpdateExistingContract(update)
+	if showDiff {
 		deployFunc = prompt.ShowContractDiffPrompt(logger)
 	}
 
-	c, err := flow.DeployProject(context.Background(), deployFunc)
+	// the deployment is recorded from the contract events emitted after the latest block
+	startHeight, heightErr := latestBlockHeight(ctx, flow)
+
+	c, err := flow.DeployProject(ctx, deployFunc)
 	if err != nil {
 		var projectErr *flowkit.ProjectDeploymentError
 		if errors.As(err, &projectErr) {
@@ -90,23 +182,74 @@ func deploy(
 					name,
 					err.Error(),
 				))
-				if !deployFlags.Update && (strings.Contains(err.Error(), "exists in account") || strings.Contains(err.Error(), "already exists")) {
+				if !update && (strings.Contains(err.Error(), "exists in account") || strings.Contains(err.Error(), "already exists")) {
 					showUpdateHint = true
 				}
 			}
 			if showUpdateHint {
-				logger.Info(fmt.Sprintf("%s Contract already exists. To update it, run: flow project deploy --update", output.TryEmoji()))
+				logger.Info(fmt.Sprintf("%s Contract already exists. To update it, run: %s", output.TryEmoji(), updateCommand))
 			}
+			recordPartialDeployment(ctx, logger, flow, state, startHeight, heightErr)
 			return nil, fmt.Errorf("failed deploying all contracts")
 		}
 		return nil, err
 	}
 
-	return &deployResult{c}, nil
+	return newDeployResult(ctx, logger, flow, state, startHeight, heightErr, c), nil
+}
+
+// newDeployResult records the contracts deployed since the block height in the deployment history,
+// warning instead of failing if they can not be recorded.
+func newDeployResult(
+	ctx context.Context,
+	logger output.Logger,
+	flow flowkit.Services,
+	state *flowkit.State,
+	startHeight uint64,
+	heightErr error,
+	contracts []*project.Contract,
+) *deployResult {
+	result := &deployResult{contracts: contracts}
+
+	err := heightErr
+	if err == nil {
+		result.records, err = recordDeployment(ctx, flow, state.ReaderWriter(), startHeight+1, contracts)
+	}
+	if err != nil {
+		logger.Info(fmt.Sprintf("%s Failed to record the deployment history: %s", output.WarningEmoji(), err))
+	} else if len(result.records) > 0 {
+		result.historyPath = deploymentHistoryPath(flow.Network().Name)
+	}
+
+	return result
+}
+
+// recordPartialDeployment records the contracts which were deployed before the deployment of the project failed,
+// as the deployment result is not shown.
+func recordPartialDeployment(
+	ctx context.Context,
+	logger output.Logger,
+	flow flowkit.Services,
+	state *flowkit.State,
+	startHeight uint64,
+	heightErr error,
+) {
+	contracts, err := state.DeploymentContractsByNetwork(flow.Network())
+	if err != nil {
+		logger.Info(fmt.Sprintf("%s Failed to record the deployment history: %s", output.WarningEmoji(), err))
+		return
+	}
+
+	result := newDeployResult(ctx, logger, flow, state, startHeight, heightErr, contracts)
+	if len(result.records) > 0 {
+		logger.Info(result.String())
+	}
 }
 
 type deployResult struct {
-	contracts []*project.Contract
+	contracts   []*project.Contract
+	records     []deploymentRecord
+	historyPath string
 }
 
 func (r *deployResult) JSON() any {
@@ -120,7 +263,16 @@ func (r *deployResult) JSON() any {
 }
 
 func (r *deployResult) String() string {
-	return ""
+	if len(r.records) == 0 {
+		return ""
+	}
+	return fmt.Sprintf(
+		"%s Recorded %d %s in %s",
+		output.SaveEmoji(),
+		len(r.records),
+		util.Pluralize("deployment", len(r.records)),
+		r.historyPath,
+	)
 }
 
 func (r *deployResult) Oneliner() string {
@@ -0,0 +1,219 @@
+/*
+ * Flow CLI
+ *
+ * Copyright Flow Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package project
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/onflow/cadence"
+	flowsdk "github.com/onflow/flow-go-sdk"
+
+	"github.com/onflow/flowkit/v2"
+	"github.com/onflow/flowkit/v2/project"
+)
+
+// deploymentsDir is the directory of the project the deployments on each network are recorded in.
+var deploymentsDir = filepath.Join(".flow", "deployments")
+
+// deploymentRecord is a deployment of a contract by a transaction.
+type deploymentRecord struct {
+	Contract      string    `json:"contract"`
+	Address       string    `json:"address"`
+	CodeHash      string    `json:"codeHash"`
+	TransactionID string    `json:"transactionId"`
+	BlockHeight   uint64    `json:"blockHeight"`
+	Timestamp     time.Time `json:"timestamp"`
+}
+
+// deploymentHistory is every deployment recorded on a network, oldest first.
+type deploymentHistory struct {
+	Network     string             `json:"network"`
+	Deployments []deploymentRecord `json:"deployments"`
+}
+
+func deploymentHistoryPath(network string) string {
+	return filepath.Join(deploymentsDir, fmt.Sprintf("%s.json", network))
+}
+
+// codeHash returns the hash of contract code recorded for its deployments.
+func codeHash(code []byte) string {
+	hash := sha256.Sum256(code)
+	return hex.EncodeToString(hash[:])
+}
+
+// loadDeploymentHistory reads the deployments recorded on the network,
+// which are none if the network has no history yet.
+func loadDeploymentHistory(rw flowkit.ReaderWriter, network string) (*deploymentHistory, error) {
+	history := &deploymentHistory{Network: network}
+
+	path := deploymentHistoryPath(network)
+	if _, err := rw.Stat(path); err != nil {
+		return history, nil // the history is created by the first recorded deployment
+	}
+
+	data, err := rw.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read deployment history %s: %w", path, err)
+	}
+
+	if err := json.Unmarshal(data, history); err != nil {
+		return nil, fmt.Errorf("failed to parse deployment history %s: %w", path, err)
+	}
+	return history, nil
+}
+
+func (h *deploymentHistory) save(rw flowkit.ReaderWriter) error {
+	data, err := json.MarshalIndent(h, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := rw.MkdirAll(deploymentsDir, 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", deploymentsDir, err)
+	}
+
+	path := deploymentHistoryPath(h.Network)
+	if err := rw.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write deployment history %s: %w", path, err)
+	}
+	return nil
+}
+
+// latest returns the last recorded deployment of the contract on the address.
+func (h *deploymentHistory) latest(contract string, address flowsdk.Address) (deploymentRecord, bool) {
+	for i := len(h.Deployments) - 1; i >= 0; i-- {
+		record := h.Deployments[i]
+		if record.Contract == contract && flowsdk.HexToAddress(record.Address) == address {
+			return record, true
+		}
+	}
+	return deploymentRecord{}, false
+}
+
+// latestBlockHeight returns the height of the latest sealed block of the network.
+func latestBlockHeight(ctx context.Context, flow flowkit.Services) (uint64, error) {
+	block, err := flow.GetBlock(ctx, flowkit.BlockQuery{Latest: true})
+	if err != nil {
+		return 0, err
+	}
+	return block.Height, nil
+}
+
+type deployedContract struct {
+	address flowsdk.Address
+	name    string
+}
+
+// recordDeployment records the contracts deployed or updated since the block height in the
+// deployment history of the network, from the contract events emitted by their transactions.
+//
+// Contracts without events, which were left unchanged, are not recorded.
+// The events are not matched to the transactions sent by the deployment, which are not known,
+// so a contract deployed to the same account by someone else in the meantime is recorded as well.
+func recordDeployment(
+	ctx context.Context,
+	flow flowkit.Services,
+	rw flowkit.ReaderWriter,
+	startHeight uint64,
+	contracts []*project.Contract,
+) ([]deploymentRecord, error) {
+	endHeight, err := latestBlockHeight(ctx, flow)
+	if err != nil {
+		return nil, err
+	}
+	if endHeight < startHeight {
+		return nil, nil
+	}
+
+	blockEvents, err := flow.GetEvents(
+		ctx,
+		[]string{flowsdk.EventAccountContractAdded, flowsdk.EventAccountContractUpdated},
+		startHeight,
+		endHeight,
+		&flowkit.EventWorker{Count: 1, BlocksPerWorker: 250},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	deployments := make(map[deployedContract]deploymentRecord)
+	for _, block := range blockEvents {
+		for _, event := range block.Events {
+			fields := cadence.FieldsMappedByName(event.Value)
+			address, ok := fields["address"].(cadence.Address)
+			if !ok {
+				continue
+			}
+			name, ok := fields["contract"].(cadence.String)
+			if !ok {
+				continue
+			}
+
+			deployments[deployedContract{flowsdk.Address(address), string(name)}] = deploymentRecord{
+				TransactionID: event.TransactionID.String(),
+				BlockHeight:   block.Height,
+				Timestamp:     block.BlockTimestamp.UTC(),
+			}
+		}
+	}
+
+	history, err := loadDeploymentHistory(rw, flow.Network().Name)
+	if err != nil {
+		return nil, err
+	}
+
+	accounts := make(map[flowsdk.Address]*flowsdk.Account)
+	var records []deploymentRecord
+	for _, contract := range contracts {
+		record, ok := deployments[deployedContract{contract.AccountAddress, contract.Name}]
+		if !ok {
+			continue
+		}
+
+		account, ok := accounts[contract.AccountAddress]
+		if !ok {
+			account, err = flow.GetAccount(ctx, contract.AccountAddress)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get account %s: %w", contract.AccountAddress.HexWithPrefix(), err)
+			}
+			accounts[contract.AccountAddress] = account
+		}
+
+		record.Contract = contract.Name
+		record.Address = contract.AccountAddress.HexWithPrefix()
+		record.CodeHash = codeHash(account.Contracts[contract.Name])
+		records = append(records, record)
+	}
+
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	history.Deployments = append(history.Deployments, records...)
+	if err := history.save(rw); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
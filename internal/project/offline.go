@@ -0,0 +1,399 @@
+/*
+ * Flow CLI
+ *
+ * Copyright Flow Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package project
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/onflow/cadence"
+	flowsdk "github.com/onflow/flow-go-sdk"
+
+	"github.com/onflow/flowkit/v2"
+	"github.com/onflow/flowkit/v2/output"
+	"github.com/onflow/flowkit/v2/project"
+	"github.com/onflow/flowkit/v2/transactions"
+
+	"github.com/onflow/flow-cli/internal/util"
+)
+
+// transactionExpiry is the number of blocks after its reference block a transaction expires.
+const transactionExpiry = 600
+
+// manifestFile is the file listing the built deployment transactions in the order they are sent.
+const manifestFile = "deployment.json"
+
+const addContractTemplate = `transaction(name: String, code: String%s) {
+	prepare(signer: auth(AddContract) &Account) {
+		signer.contracts.add(name: name, code: code.decodeHex()%s)
+	}
+}`
+
+const updateContractTemplate = `transaction(name: String, code: String) {
+	prepare(signer: auth(UpdateContract) &Account) {
+		signer.contracts.update(name: name, code: code.decodeHex())
+	}
+}`
+
+// defaultBuildDir returns the directory the deployment transactions of the network are built in.
+func defaultBuildDir(network string) string {
+	return filepath.Join(".flow", "transactions", network)
+}
+
+// builtTransaction is a deployment transaction written to a file.
+type builtTransaction struct {
+	File     string     `json:"file"`
+	Contract string     `json:"contract"`
+	Address  string     `json:"address"`
+	Action   planStatus `json:"action"`
+}
+
+// deploymentManifest lists the deployment transactions built for a network, in deployment order.
+type deploymentManifest struct {
+	Network        string             `json:"network"`
+	ReferenceBlock uint64             `json:"referenceBlock"`
+	Transactions   []builtTransaction `json:"transactions"`
+}
+
+// deployTransaction returns the unsigned transaction adding or updating the contract,
+// proposed, paid and authorized by the account of the contract.
+func deployTransaction(contract *contractPlan, referenceBlock flowsdk.Identifier, sequenceNumber uint64) (*flowsdk.Transaction, error) {
+	args := []cadence.Value{
+		cadence.String(contract.Name),
+		cadence.String(hex.EncodeToString(contract.Code)),
+	}
+
+	var script string
+	switch contract.Status {
+	case planStatusNew:
+		var params, initArgs strings.Builder
+		for i, arg := range contract.Args {
+			_, _ = fmt.Fprintf(&params, ", arg%d: %s", i, arg.Type().ID())
+			_, _ = fmt.Fprintf(&initArgs, ", arg%d", i)
+		}
+		script = fmt.Sprintf(addContractTemplate, params.String(), initArgs.String())
+		args = append(args, contract.Args...)
+	case planStatusUpdate:
+		script = updateContractTemplate
+	default:
+		return nil, fmt.Errorf("contract %s is %s, it can not be deployed", contract.Name, contract.Status)
+	}
+
+	tx := flowsdk.NewTransaction().
+		SetScript([]byte(script)).
+		SetComputeLimit(deployComputeLimit).
+		SetReferenceBlockID(referenceBlock).
+		SetProposalKey(contract.Address, contract.KeyIndex, sequenceNumber).
+		SetPayer(contract.Address).
+		AddAuthorizer(contract.Address)
+
+	for _, arg := range args {
+		if err := tx.AddArgument(arg); err != nil {
+			return nil, fmt.Errorf("invalid argument for contract %s: %w", contract.Name, err)
+		}
+	}
+	return tx, nil
+}
+
+// buildDeployment writes the unsigned transactions deploying the contracts of the network to the directory,
+// together with a manifest of the order they have to be sent in.
+//
+// The transactions of an account use consecutive sequence numbers of its proposal key,
+// so they have to be sent in order, before they expire.
+func buildDeployment(
+	ctx context.Context,
+	flow flowkit.Services,
+	state *flowkit.State,
+	dir string,
+	update bool,
+) (*buildResult, error) {
+	contracts, err := compareDeployment(ctx, flow, state)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, contract := range contracts {
+		switch contract.Status {
+		case planStatusBlocked:
+			return nil, fmt.Errorf(
+				"contract %s can not be updated, run flow project check-upgrade for details",
+				contract.Name,
+			)
+		case planStatusUpdate:
+			if !update {
+				return nil, fmt.Errorf(
+					"contract %s already exists on %s, to update it run: flow project deploy --build-only --update",
+					contract.Name,
+					contract.Address.HexWithPrefix(),
+				)
+			}
+		}
+	}
+
+	block, err := flow.GetBlock(ctx, flowkit.BlockQuery{Latest: true})
+	if err != nil {
+		return nil, err
+	}
+
+	manifest := &deploymentManifest{
+		Network:        flow.Network().Name,
+		ReferenceBlock: block.Height,
+	}
+
+	type proposalKey struct {
+		address flowsdk.Address
+		index   uint32
+	}
+	sequenceNumbers := make(map[proposalKey]uint64)
+
+	rw := state.ReaderWriter()
+	if err := rw.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+
+	for _, contract := range contracts {
+		if contract.Status == planStatusUnchanged {
+			continue
+		}
+
+		key := proposalKey{contract.Address, contract.KeyIndex}
+		sequenceNumber, ok := sequenceNumbers[key]
+		if !ok {
+			sequenceNumber, err = proposalSequenceNumber(ctx, flow, contract.Address, contract.KeyIndex)
+			if err != nil {
+				return nil, err
+			}
+		}
+		sequenceNumbers[key] = sequenceNumber + 1
+
+		tx, err := deployTransaction(contract, block.ID, sequenceNumber)
+		if err != nil {
+			return nil, err
+		}
+
+		built := builtTransaction{
+			File:     fmt.Sprintf("%03d-%s.rlp", len(manifest.Transactions)+1, contract.Name),
+			Contract: contract.Name,
+			Address:  contract.Address.HexWithPrefix(),
+			Action:   contract.Status,
+		}
+		path := filepath.Join(dir, built.File)
+		if err := rw.WriteFile(path, []byte(hex.EncodeToString(tx.Encode())), 0644); err != nil {
+			return nil, fmt.Errorf("failed to write transaction %s: %w", path, err)
+		}
+		manifest.Transactions = append(manifest.Transactions, built)
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	path := filepath.Join(dir, manifestFile)
+	if err := rw.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return nil, fmt.Errorf("failed to write %s: %w", path, err)
+	}
+
+	return &buildResult{dir: dir, manifest: manifest}, nil
+}
+
+// proposalSequenceNumber returns the current sequence number of the key of the account.
+func proposalSequenceNumber(ctx context.Context, flow flowkit.Services, address flowsdk.Address, keyIndex uint32) (uint64, error) {
+	account, err := flow.GetAccount(ctx, address)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get account %s: %w", address.HexWithPrefix(), err)
+	}
+	for _, key := range account.Keys {
+		if key.Index == keyIndex {
+			return key.SequenceNumber, nil
+		}
+	}
+	return 0, fmt.Errorf("account %s has no key with index %d", address.HexWithPrefix(), keyIndex)
+}
+
+type buildResult struct {
+	dir      string
+	manifest *deploymentManifest
+}
+
+func (r *buildResult) JSON() any {
+	return map[string]any{
+		"directory":      r.dir,
+		"network":        r.manifest.Network,
+		"referenceBlock": r.manifest.ReferenceBlock,
+		"transactions":   r.manifest.Transactions,
+	}
+}
+
+func (r *buildResult) String() string {
+	var b strings.Builder
+
+	transactions := r.manifest.Transactions
+	if len(transactions) == 0 {
+		return fmt.Sprintf("%s All contracts on network %s are up to date, no transaction was built", output.OkEmoji(), r.manifest.Network)
+	}
+
+	_, _ = fmt.Fprintf(
+		&b,
+		"%s Built %d unsigned %s for network %s in %s:\n",
+		output.SaveEmoji(),
+		len(transactions),
+		util.Pluralize("transaction", len(transactions)),
+		r.manifest.Network,
+		r.dir,
+	)
+	for _, tx := range transactions {
+		_, _ = fmt.Fprintf(&b, "    %s  %s %s on %s\n", tx.File, tx.Action, tx.Contract, tx.Address)
+	}
+
+	first := filepath.Join(r.dir, transactions[0].File)
+	_, _ = fmt.Fprintf(
+		&b,
+		"\nThe transactions expire %d blocks after block %d. Sign each of them with the keys of its account, for example:\n",
+		transactionExpiry,
+		r.manifest.ReferenceBlock,
+	)
+	_, _ = fmt.Fprintf(&b, "    flow transactions sign %s --signer <account> --filter payload --save %s\n", first, first)
+	_, _ = fmt.Fprintf(&b, "then send them in order with:\n    flow project deploy --network %s --submit %s", r.manifest.Network, r.dir)
+
+	return b.String()
+}
+
+func (r *buildResult) Oneliner() string {
+	return fmt.Sprintf("built %d transactions in %s", len(r.manifest.Transactions), r.dir)
+}
+
+// loadSignedDeployment reads the manifest and the signed transactions built in the directory.
+func loadSignedDeployment(rw flowkit.ReaderWriter, dir string, network string) (*deploymentManifest, []*transactions.Transaction, error) {
+	path := filepath.Join(dir, manifestFile)
+	data, err := rw.ReadFile(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	manifest := &deploymentManifest{}
+	if err := json.Unmarshal(data, manifest); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if manifest.Network != network {
+		return nil, nil, fmt.Errorf("transactions in %s were built for network %s, not %s", dir, manifest.Network, network)
+	}
+
+	var txs []*transactions.Transaction
+	for _, built := range manifest.Transactions {
+		path := filepath.Join(dir, built.File)
+		payload, err := rw.ReadFile(path)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read transaction %s: %w", path, err)
+		}
+
+		tx, err := transactions.NewFromPayload(payload)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to decode transaction %s: %w", path, err)
+		}
+		if len(tx.FlowTransaction().EnvelopeSignatures) == 0 {
+			return nil, nil, fmt.Errorf("transaction %s is not signed by its payer %s", path, built.Address)
+		}
+		txs = append(txs, tx)
+	}
+	return manifest, txs, nil
+}
+
+// submitDeployment sends the signed deployment transactions built in the directory in order,
+// stopping at the first failed transaction, and records the deployment history.
+func submitDeployment(
+	ctx context.Context,
+	logger output.Logger,
+	flow flowkit.Services,
+	state *flowkit.State,
+	dir string,
+) (*deployResult, error) {
+	manifest, txs, err := loadSignedDeployment(state.ReaderWriter(), dir, flow.Network().Name)
+	if err != nil {
+		return nil, err
+	}
+
+	contracts, err := state.DeploymentContractsByNetwork(flow.Network())
+	if err != nil {
+		return nil, err
+	}
+
+	startHeight, heightErr := latestBlockHeight(ctx, flow)
+
+	var sent []*project.Contract
+	for i, tx := range txs {
+		built := manifest.Transactions[i]
+		logger.StartProgress(fmt.Sprintf(
+			"[%d/%d] Sending %s with ID %s...",
+			i+1,
+			len(txs),
+			built.File,
+			tx.FlowTransaction().ID(),
+		))
+		_, result, err := flow.SendSignedTransaction(ctx, tx)
+		logger.StopProgress()
+		if err == nil && result != nil && result.Error != nil {
+			err = result.Error
+		}
+		if err != nil {
+			// record the contracts of the transactions sent before
+			result := newDeployResult(ctx, logger, flow, state, startHeight, heightErr, sent)
+			if len(result.records) > 0 {
+				logger.Info(result.String())
+			}
+			return nil, fmt.Errorf(
+				"failed sending %s for contract %s, %d of %d transactions were sent: %w",
+				built.File,
+				built.Contract,
+				i,
+				len(txs),
+				err,
+			)
+		}
+
+		logger.Info(fmt.Sprintf(
+			"%s [%d/%d] Contract %s %s on %s",
+			output.OkEmoji(),
+			i+1,
+			len(txs),
+			built.Contract,
+			deployedAction(built.Action),
+			built.Address,
+		))
+
+		for _, contract := range contracts {
+			if contract.Name == built.Contract {
+				sent = append(sent, contract)
+			}
+		}
+	}
+
+	return newDeployResult(ctx, logger, flow, state, startHeight, heightErr, sent), nil
+}
+
+// deployedAction returns what the transaction did to a contract with the status.
+func deployedAction(status planStatus) string {
+	if status == planStatusUpdate {
+		return "updated"
+	}
+	return "deployed"
+}
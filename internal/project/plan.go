@@ -0,0 +1,482 @@
+/*
+ * Flow CLI
+ *
+ * Copyright Flow Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package project
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/onflow/cadence"
+	flowsdk "github.com/onflow/flow-go-sdk"
+	"github.com/onflow/flow-go/fvm/systemcontracts"
+	flowGo "github.com/onflow/flow-go/model/flow"
+	"golang.org/x/exp/maps"
+
+	"github.com/onflow/flowkit/v2"
+	"github.com/onflow/flowkit/v2/output"
+	"github.com/onflow/flowkit/v2/project"
+
+	"github.com/onflow/flow-cli/internal/command"
+	"github.com/onflow/flow-cli/internal/util"
+)
+
+// deployComputeLimit is the compute limit flowkit sets on the transactions deploying contracts,
+// which bounds the fees of each of them.
+const deployComputeLimit = 9999
+
+// deployEstimatesScript returns the fees of a transaction with the given efforts
+// and the storage capacity in megabytes reserved by each FLOW of an account.
+const deployEstimatesScript = `import FlowFees from %s
+import FlowStorageFees from %s
+
+access(all) fun main(inclusionEffort: UFix64, executionEffort: UFix64): [UFix64] {
+    return [
+        FlowFees.computeFees(inclusionEffort: inclusionEffort, executionEffort: executionEffort),
+        FlowStorageFees.storageMegaBytesPerReservedFLOW
+    ]
+}`
+
+type planStatus string
+
+const (
+	planStatusNew       planStatus = "new"
+	planStatusUnchanged planStatus = "unchanged"
+	planStatusUpdate    planStatus = "update"
+	planStatusBlocked   planStatus = "blocked"
+)
+
+// contractPlan is what deploying a contract would do on the network.
+type contractPlan struct {
+	Name     string
+	Location string
+	Account  string
+	Address  flowsdk.Address
+	KeyIndex uint32
+	Status   planStatus
+	// Reasons why the contract can not be updated, if it is blocked
+	Reasons []string
+	// StorageDelta is the change of the size of the contract code in bytes. It only approximates
+	// the change of the storage used by the account, as it is not measured on the network,
+	// and does not include the storage used by the contract initializer.
+	StorageDelta int
+	Diff         string
+	// DeployedCode is the code of the contract on the account, if it is deployed
+	DeployedCode []byte
+	// Code is the code to deploy, with the imports resolved
+	Code []byte
+	// Args are the arguments of the contract initializer
+	Args []cadence.Value
+}
+
+// compare sets the status of the contract by comparing the code to deploy
+// with the contracts deployed on the account.
+func (c *contractPlan) compare(code []byte, deployed map[string][]byte, names contractNamesProvider) error {
+	existing, ok := deployed[c.Name]
+	if !ok {
+		c.Status = planStatusNew
+		c.StorageDelta = len(code)
+		return nil
+	}
+	c.DeployedCode = existing
+
+	if bytes.Equal(existing, code) {
+		c.Status = planStatusUnchanged
+		return nil
+	}
+
+	c.StorageDelta = len(code) - len(existing)
+	c.Diff = util.UnifiedDiff(c.Location, string(existing), string(code))
+
+	errs, err := validateContractUpdate(c.Address, c.Name, existing, code, names)
+	if err != nil {
+		return err
+	}
+	if len(errs) > 0 {
+		c.Status = planStatusBlocked
+		for _, err := range errs {
+			c.Reasons = append(c.Reasons, updateErrorMessage(c.Location, err))
+		}
+		return nil
+	}
+
+	c.Status = planStatusUpdate
+	return nil
+}
+
+// deployPlan is what deploying the project would do on a network, without sending anything.
+type deployPlan struct {
+	network   string
+	update    bool
+	contracts []*contractPlan
+	// fee is the maximum fee of a deployment transaction, if it could be estimated
+	fee *cadence.UFix64
+	// megaBytesPerFLOW is the storage reserved by each FLOW of an account, if it could be estimated
+	megaBytesPerFLOW *cadence.UFix64
+}
+
+// planDeployment compares every contract deployed on the network with its code on chain,
+// in the order the contracts would be deployed in, and estimates the fees and storage.
+func planDeployment(
+	ctx context.Context,
+	logger output.Logger,
+	flow flowkit.Services,
+	state *flowkit.State,
+	update bool,
+) (*deployPlan, error) {
+	contracts, err := compareDeployment(ctx, flow, state)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &deployPlan{
+		network:   flow.Network().Name,
+		update:    update,
+		contracts: contracts,
+	}
+
+	fee, megaBytesPerFLOW, err := estimateDeployment(ctx, flow)
+	if err != nil {
+		logger.Info(fmt.Sprintf("%s Fees and storage reservations could not be estimated: %s", output.WarningEmoji(), err))
+	} else {
+		plan.fee = &fee
+		plan.megaBytesPerFLOW = &megaBytesPerFLOW
+	}
+
+	return plan, nil
+}
+
+// compareDeployment compares every contract deployed on the network with its code on chain,
+// in the order the contracts would be deployed in, with the imports resolved as on deployment.
+func compareDeployment(ctx context.Context, flow flowkit.Services, state *flowkit.State) ([]*contractPlan, error) {
+	network := flow.Network()
+
+	contracts, err := state.DeploymentContractsByNetwork(network)
+	if err != nil {
+		return nil, err
+	}
+
+	aliases := state.AliasesForNetwork(network)
+	deployment, err := project.NewDeployment(contracts, aliases)
+	if err != nil {
+		return nil, err
+	}
+
+	sorted, err := deployment.Sort()
+	if err != nil {
+		return nil, err
+	}
+
+	importReplacer := project.NewImportReplacer(sorted, aliases)
+
+	deployed := make(map[flowsdk.Address]map[string][]byte)
+	getDeployed := func(address flowsdk.Address) (map[string][]byte, error) {
+		if contracts, ok := deployed[address]; ok {
+			return contracts, nil
+		}
+		account, err := flow.GetAccount(ctx, address)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get account %s: %w", address.HexWithPrefix(), err)
+		}
+		deployed[address] = account.Contracts
+		return account.Contracts, nil
+	}
+	names := func(address flowsdk.Address) ([]string, error) {
+		contracts, err := getDeployed(address)
+		if err != nil {
+			return nil, err
+		}
+		names := maps.Keys(contracts)
+		sort.Strings(names)
+		return names, nil
+	}
+
+	planned := make([]*contractPlan, 0, len(sorted))
+	for _, contract := range sorted {
+		program, err := project.NewProgram(contract.Code(), contract.Args, contract.Location())
+		if err != nil {
+			return nil, err
+		}
+
+		program, err = importReplacer.Replace(program)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve imports of contract %s: %w", contract.Name, err)
+		}
+
+		signer, err := state.Accounts().ByName(contract.AccountName)
+		if err != nil {
+			return nil, err
+		}
+
+		existing, err := getDeployed(contract.AccountAddress)
+		if err != nil {
+			return nil, err
+		}
+
+		plan := &contractPlan{
+			Name:     contract.Name,
+			Location: contract.Location(),
+			Account:  signer.Name,
+			Address:  contract.AccountAddress,
+			KeyIndex: signer.Key.Index(),
+			Code:     program.Code(),
+			Args:     contract.Args,
+		}
+		err = plan.compare(program.Code(), existing, names)
+		if err != nil {
+			return nil, err
+		}
+
+		planned = append(planned, plan)
+	}
+
+	return planned, nil
+}
+
+// estimateDeployment returns the maximum fee of a deployment transaction and the storage
+// reserved by each FLOW of an account, from the fee contracts of the network.
+func estimateDeployment(ctx context.Context, flow flowkit.Services) (cadence.UFix64, cadence.UFix64, error) {
+	network := flow.Network()
+
+	// Networks with custom names are identified by the chain of their access node
+	var chainID flowGo.ChainID
+	if networkChainID, err := util.NetworkToChainID(network.Name); err == nil {
+		chainID = flowGo.ChainID(networkChainID)
+	} else {
+		chainID, err = util.GetChainIDFromHost(network.Host)
+		if err != nil {
+			return 0, 0, fmt.Errorf("failed to get chain ID from host %s: %w", network.Host, err)
+		}
+	}
+
+	contracts := systemcontracts.SystemContractsForChain(chainID)
+	value, err := flow.ExecuteScript(
+		ctx,
+		flowkit.Script{
+			Code: []byte(fmt.Sprintf(
+				deployEstimatesScript,
+				contracts.FlowFees.Address.HexWithPrefix(),
+				contracts.FlowStorageFees.Address.HexWithPrefix(),
+			)),
+			Args: []cadence.Value{
+				cadence.UFix64(1_00000000),
+				cadence.UFix64(deployComputeLimit * 1_00000000),
+			},
+		},
+		flowkit.LatestScriptQuery,
+	)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	array, ok := value.(cadence.Array)
+	if !ok || len(array.Values) != 2 {
+		return 0, 0, fmt.Errorf("unexpected result %s", value)
+	}
+	fee, ok := array.Values[0].(cadence.UFix64)
+	if !ok {
+		return 0, 0, fmt.Errorf("unexpected fee %s", array.Values[0])
+	}
+	megaBytesPerFLOW, ok := array.Values[1].(cadence.UFix64)
+	if !ok || megaBytesPerFLOW == 0 {
+		return 0, 0, fmt.Errorf("unexpected storage capacity %s", array.Values[1])
+	}
+
+	return fee, megaBytesPerFLOW, nil
+}
+
+// transactions returns the contracts which would be deployed or updated by a transaction.
+func (p *deployPlan) transactions() []*contractPlan {
+	var contracts []*contractPlan
+	for _, contract := range p.contracts {
+		if contract.Status == planStatusNew || (contract.Status == planStatusUpdate && p.update) {
+			contracts = append(contracts, contract)
+		}
+	}
+	return contracts
+}
+
+// storageDelta returns the change of the size of the contract code of each account in bytes.
+func (p *deployPlan) storageDelta() map[flowsdk.Address]int {
+	deltas := make(map[flowsdk.Address]int)
+	for _, contract := range p.transactions() {
+		deltas[contract.Address] += contract.StorageDelta
+	}
+	return deltas
+}
+
+// storageReservation returns the FLOW reserved by a storage of the given bytes.
+func (p *deployPlan) storageReservation(bytes int) cadence.UFix64 {
+	if p.megaBytesPerFLOW == nil || bytes <= 0 {
+		return 0
+	}
+	// FLOW = bytes / 10^6 / megaBytesPerFLOW, with UFix64 having 8 decimals
+	return cadence.UFix64(uint64(bytes) * 100 * 1_00000000 / uint64(*p.megaBytesPerFLOW))
+}
+
+// maxFees returns the maximum fees of all the transactions to send, if they could be estimated.
+func (p *deployPlan) maxFees() cadence.UFix64 {
+	if p.fee == nil {
+		return 0
+	}
+	return cadence.UFix64(uint64(*p.fee) * uint64(len(p.transactions())))
+}
+
+// countStatus returns the number of contracts with the status.
+func countStatus(contracts []*contractPlan, status planStatus) int {
+	count := 0
+	for _, contract := range contracts {
+		if contract.Status == status {
+			count++
+		}
+	}
+	return count
+}
+
+func (p *deployPlan) JSON() any {
+	contracts := make([]map[string]any, 0, len(p.contracts))
+	for i, contract := range p.contracts {
+		result := map[string]any{
+			"order":         i + 1,
+			"name":          contract.Name,
+			"location":      contract.Location,
+			"account":       contract.Account,
+			"address":       contract.Address.HexWithPrefix(),
+			"keyIndex":      contract.KeyIndex,
+			"status":        contract.Status,
+			"codeSizeDelta": contract.StorageDelta,
+		}
+		if len(contract.Reasons) > 0 {
+			result["reasons"] = contract.Reasons
+		}
+		if contract.Diff != "" {
+			result["diff"] = contract.Diff
+		}
+		contracts = append(contracts, result)
+	}
+
+	storage := make(map[string]int)
+	for address, delta := range p.storageDelta() {
+		storage[address.HexWithPrefix()] = delta
+	}
+
+	result := map[string]any{
+		"network":       p.network,
+		"contracts":     contracts,
+		"transactions":  len(p.transactions()),
+		"codeSizeDelta": storage,
+	}
+	if p.fee != nil {
+		result["maxFees"] = p.maxFees().String()
+	}
+	return result
+}
+
+func (p *deployPlan) String() string {
+	var b bytes.Buffer
+	writer := util.CreateTabWriter(&b)
+
+	_, _ = fmt.Fprintf(writer, "Deployment plan for network %s, nothing has been sent.\n\n", p.network)
+	_, _ = fmt.Fprintf(writer, "#\tContract\tSigner\tStatus\n")
+	for i, contract := range p.contracts {
+		status := string(contract.Status)
+		if contract.Status == planStatusUpdate && !p.update {
+			status += " (skipped, requires --update)"
+		}
+		_, _ = fmt.Fprintf(
+			writer,
+			"%d\t%s\t%s (%s, key %d)\t%s\n",
+			i+1,
+			contract.Name,
+			contract.Account,
+			contract.Address.HexWithPrefix(),
+			contract.KeyIndex,
+			status,
+		)
+	}
+	_ = writer.Flush()
+
+	for _, contract := range p.contracts {
+		if len(contract.Reasons) == 0 {
+			continue
+		}
+		_, _ = fmt.Fprintf(&b, "\n%s Contract %s can not be updated:\n", output.ErrorEmoji(), contract.Name)
+		for _, reason := range contract.Reasons {
+			_, _ = fmt.Fprintf(&b, "  - %s\n", reason)
+		}
+	}
+
+	transactions := p.transactions()
+	_, _ = fmt.Fprintf(&b, "\n%d %s to send\n", len(transactions), util.Pluralize("transaction", len(transactions)))
+
+	deltas := p.storageDelta()
+	addresses := maps.Keys(deltas)
+	sort.Slice(addresses, func(i, j int) bool {
+		return addresses[i].Hex() < addresses[j].Hex()
+	})
+	for _, address := range addresses {
+		delta := deltas[address]
+		_, _ = fmt.Fprintf(&b, "Contract code size change of %s: %+d bytes", address.HexWithPrefix(), delta)
+		if p.megaBytesPerFLOW != nil && delta > 0 {
+			_, _ = fmt.Fprintf(&b, " (reserves about %s FLOW)", p.storageReservation(delta))
+		}
+		_, _ = fmt.Fprintln(&b)
+	}
+	if len(addresses) > 0 {
+		_, _ = fmt.Fprintln(&b, "Storage is estimated from the size of the contract code only, not measured on the network")
+	}
+
+	if p.fee != nil && len(transactions) > 0 {
+		_, _ = fmt.Fprintf(
+			&b,
+			"Estimated fees: at most %s FLOW (compute limit of %d per transaction)\n",
+			p.maxFees().String(),
+			deployComputeLimit,
+		)
+	}
+
+	for _, contract := range p.contracts {
+		if contract.Diff != "" {
+			_, _ = fmt.Fprintf(&b, "\n%s", contract.Diff)
+		}
+	}
+
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+func (p *deployPlan) Oneliner() string {
+	return fmt.Sprintf(
+		"%d new, %d to update, %d unchanged, %d blocked",
+		countStatus(p.contracts, planStatusNew),
+		countStatus(p.contracts, planStatusUpdate),
+		countStatus(p.contracts, planStatusUnchanged),
+		countStatus(p.contracts, planStatusBlocked),
+	)
+}
+
+func (p *deployPlan) ExitCode() int {
+	if countStatus(p.contracts, planStatusBlocked) > 0 {
+		return 1
+	}
+	return 0
+}
+
+var _ command.ResultWithExitCode = &deployPlan{}
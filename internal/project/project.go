@@ -31,4 +31,7 @@ var Cmd = &cobra.Command{
 
 func init() {
 	DeployCommand.AddToParent(Cmd)
+	CheckUpgradeCommand.AddToParent(Cmd)
+	VerifyCommand.AddToParent(Cmd)
+	PromoteCommand.AddToParent(Cmd)
 }
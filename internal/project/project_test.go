@@ -19,15 +19,25 @@
 package project
 
 import (
+	"context"
+	"encoding/hex"
+	"fmt"
 	"testing"
+	"time"
 
+	"github.com/onflow/cadence"
 	"github.com/onflow/flow-go-sdk"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
 	"github.com/onflow/flowkit/v2"
 	"github.com/onflow/flowkit/v2/accounts"
 	"github.com/onflow/flowkit/v2/config"
+	"github.com/onflow/flowkit/v2/mocks"
+	flowkitProject "github.com/onflow/flowkit/v2/project"
+	"github.com/onflow/flowkit/v2/tests"
+	"github.com/onflow/flowkit/v2/transactions"
 
 	"github.com/onflow/flow-cli/internal/command"
 	"github.com/onflow/flow-cli/internal/util"
@@ -37,6 +47,7 @@ func Test_ProjectDeploy(t *testing.T) {
 	srv, state, rw := util.TestMocks(t)
 
 	t.Run("Fail contract errors", func(t *testing.T) {
+		srv.GetBlock.Return(tests.NewBlock(), nil)
 		srv.DeployProject.Return(nil, &flowkit.ProjectDeploymentError{})
 		_, err := deploy([]string{}, command.GlobalFlags{}, util.NoLogger, srv.Mock, state)
 		assert.EqualError(t, err, "failed deploying all contracts")
@@ -77,3 +88,735 @@ func Test_ProjectDeploy(t *testing.T) {
 	})
 
 }
+
+const (
+	deployedCounter     = "access(all) contract Counter {\n    access(all) var count: Int\n    init() { self.count = 0 }\n}\n"
+	updatedCounter      = "access(all) contract Counter {\n    access(all) var count: Int\n    init() { self.count = 0 }\n    access(all) fun get(): Int { return self.count }\n}\n"
+	incompatibleCounter = "access(all) contract Counter {\n    access(all) var count: String\n    access(all) var total: Int\n    init() { self.count = \"\"; self.total = 0 }\n}\n"
+)
+
+func Test_ProjectDeployPlan(t *testing.T) {
+	address := flow.HexToAddress("0x01")
+	names := contractNamesProvider(func(flow.Address) ([]string, error) {
+		return []string{"Counter"}, nil
+	})
+	deployed := map[string][]byte{"Counter": []byte(deployedCounter)}
+
+	t.Run("Compare contracts with deployed code", func(t *testing.T) {
+		plan := &contractPlan{Name: "Counter", Location: "Counter.cdc", Address: address}
+		require.NoError(t, plan.compare([]byte(deployedCounter), deployed, names))
+		assert.Equal(t, planStatusUnchanged, plan.Status)
+		assert.Empty(t, plan.Diff)
+
+		plan = &contractPlan{Name: "Counter", Location: "Counter.cdc", Address: address}
+		require.NoError(t, plan.compare([]byte(updatedCounter), deployed, names))
+		assert.Equal(t, planStatusUpdate, plan.Status)
+		assert.Equal(t, len(updatedCounter)-len(deployedCounter), plan.StorageDelta)
+		assert.Contains(t, plan.Diff, "+    access(all) fun get(): Int { return self.count }\n")
+
+		plan = &contractPlan{Name: "Counter", Location: "Counter.cdc", Address: address}
+		require.NoError(t, plan.compare([]byte(incompatibleCounter), deployed, names))
+		assert.Equal(t, planStatusBlocked, plan.Status)
+		assert.Equal(t, []string{
+			"Counter.cdc:2:27: mismatching field `count` in `Counter`: incompatible types. expected `Int`, found `String`",
+			"Counter.cdc:3:20: found new field `total` in `Counter`",
+		}, plan.Reasons)
+
+		plan = &contractPlan{Name: "Math", Location: "Math.cdc", Address: address}
+		require.NoError(t, plan.compare([]byte("access(all) contract Math {}"), deployed, names))
+		assert.Equal(t, planStatusNew, plan.Status)
+		assert.Equal(t, 28, plan.StorageDelta)
+	})
+
+	t.Run("Summarize plan", func(t *testing.T) {
+		fee := cadence.UFix64(100000)
+		megaBytesPerFLOW := cadence.UFix64(100_00000000)
+		plan := &deployPlan{
+			network: "testnet",
+			contracts: []*contractPlan{
+				{Name: "Math", Account: "testnet-account", Address: address, Status: planStatusNew, StorageDelta: 1000},
+				{Name: "Counter", Account: "testnet-account", Address: address, Status: planStatusUpdate, StorageDelta: 500},
+				{Name: "Token", Account: "testnet-account", Address: address, Status: planStatusBlocked, Reasons: []string{"found new field `total` in `Token`"}},
+			},
+			fee:              &fee,
+			megaBytesPerFLOW: &megaBytesPerFLOW,
+		}
+
+		assert.Equal(t, "1 new, 1 to update, 0 unchanged, 1 blocked", plan.Oneliner())
+		assert.Equal(t, 1, plan.ExitCode())
+		assert.Len(t, plan.transactions(), 1)
+		assert.Contains(t, plan.String(), "update (skipped, requires --update)")
+		assert.Contains(t, plan.String(), "  - found new field `total` in `Token`\n")
+
+		plan.update = true
+		assert.Len(t, plan.transactions(), 2)
+		assert.Equal(t, map[flow.Address]int{address: 1500}, plan.storageDelta())
+		assert.Equal(t, "0.00001500", plan.storageReservation(1500).String())
+		assert.Equal(t, "0.00200000", plan.maxFees().String())
+		assert.Contains(t, plan.String(), "Contract code size change of 0x0000000000000001: +1500 bytes (reserves about 0.00001500 FLOW)\n")
+	})
+
+	t.Run("Plan deployment of project", func(t *testing.T) {
+		srv, state, rw := util.TestMocks(t)
+		srv.Network.Return(config.EmulatorNetwork)
+
+		_ = rw.WriteFile("Counter.cdc", []byte("import \"Math\"\n"+updatedCounter), 0644)
+		_ = rw.WriteFile("Math.cdc", []byte("access(all) contract Math {}"), 0644)
+		state.Contracts().AddOrUpdate(config.Contract{Name: "Counter", Location: "Counter.cdc"})
+		state.Contracts().AddOrUpdate(config.Contract{Name: "Math", Location: "Math.cdc"})
+		state.Deployments().AddOrUpdate(config.Deployment{
+			Network:   config.EmulatorNetwork.Name,
+			Account:   config.DefaultEmulator.ServiceAccount,
+			Contracts: []config.ContractDeployment{{Name: "Counter"}, {Name: "Math"}},
+		})
+
+		srv.GetAccount.Return(&flow.Account{
+			Address:   flow.HexToAddress("f8d6e0586b0a20c7"),
+			Contracts: deployed,
+		}, nil)
+		srv.ExecuteScript.Return(cadence.NewArray([]cadence.Value{
+			cadence.UFix64(100000),
+			cadence.UFix64(100_00000000),
+		}), nil)
+
+		plan, err := planDeployment(context.Background(), util.NoLogger, srv.Mock, state, true)
+		require.NoError(t, err)
+		require.Len(t, plan.contracts, 2)
+		assert.Equal(t, "Math", plan.contracts[0].Name)
+		assert.Equal(t, planStatusNew, plan.contracts[0].Status)
+		assert.Equal(t, "Counter", plan.contracts[1].Name)
+		assert.Equal(t, planStatusUpdate, plan.contracts[1].Status)
+		assert.Equal(t, config.DefaultEmulator.ServiceAccount, plan.contracts[1].Account)
+		assert.Contains(t, plan.contracts[1].Diff, "+import Math from 0xf8d6e0586b0a20c7\n")
+		assert.Equal(t, "0.00200000", plan.maxFees().String())
+	})
+}
+
+func Test_ProjectCheckUpgrade(t *testing.T) {
+	address := flow.HexToAddress("0x01")
+
+	t.Run("Report incompatible updates", func(t *testing.T) {
+		result := &upgradeCheckResult{
+			network: "testnet",
+			contracts: []*contractPlan{
+				{Name: "Math", Address: address, Status: planStatusNew},
+				{Name: "Counter", Address: address, Status: planStatusUpdate},
+				{Name: "Token", Address: address, Status: planStatusBlocked, Reasons: []string{
+					"Token.cdc:3:20: found new field `total` in `Token`",
+				}},
+			},
+		}
+
+		assert.Equal(t, "1 can be updated, 1 can not be updated", result.Oneliner())
+		assert.Equal(t, 1, result.ExitCode())
+		assert.Contains(t, result.String(), "Math is not deployed on 0x0000000000000001 yet\n")
+		assert.Contains(t, result.String(), "Counter on 0x0000000000000001 can be updated\n")
+		assert.Contains(t, result.String(), "Token on 0x0000000000000001 can not be updated:\n    - Token.cdc:3:20: found new field `total` in `Token`")
+	})
+
+	t.Run("Fail deploying incompatible updates", func(t *testing.T) {
+		srv, state, rw := util.TestMocks(t)
+		srv.Network.Return(config.EmulatorNetwork)
+
+		_ = rw.WriteFile("Counter.cdc", []byte(incompatibleCounter), 0644)
+		state.Contracts().AddOrUpdate(config.Contract{Name: "Counter", Location: "Counter.cdc"})
+		state.Deployments().AddOrUpdate(config.Deployment{
+			Network:   config.EmulatorNetwork.Name,
+			Account:   config.DefaultEmulator.ServiceAccount,
+			Contracts: []config.ContractDeployment{{Name: "Counter"}},
+		})
+		srv.GetAccount.Return(&flow.Account{
+			Address:   flow.HexToAddress("f8d6e0586b0a20c7"),
+			Contracts: map[string][]byte{"Counter": []byte(deployedCounter)},
+		}, nil)
+
+		result, err := checkUpgrade([]string{}, command.GlobalFlags{}, util.NoLogger, srv.Mock, state)
+		require.NoError(t, err)
+		assert.Equal(t, 1, result.(*upgradeCheckResult).ExitCode())
+
+		deployFlags.Update = true
+		defer func() { deployFlags.Update = false }()
+
+		_, err = deploy([]string{}, command.GlobalFlags{}, util.NoLogger, srv.Mock, state)
+		assert.EqualError(t, err, "contract updates are not compatible with the deployed contracts, nothing has been deployed")
+		srv.Mock.AssertNotCalled(t, "DeployProject", mock.Anything, mock.Anything)
+	})
+}
+
+func Test_ProjectDeploymentHistory(t *testing.T) {
+	serviceAddress := flow.HexToAddress("f8d6e0586b0a20c7")
+
+	t.Run("Record deployed contracts", func(t *testing.T) {
+		srv, state, rw := util.TestMocks(t)
+		srv.Network.Return(config.EmulatorNetwork)
+
+		block := tests.NewBlock()
+		block.Height = 12
+		srv.GetBlock.Return(block, nil)
+
+		contractEventType := cadence.NewEventType(nil, flow.EventAccountContractAdded, []cadence.Field{
+			{Identifier: "address", Type: cadence.AddressType},
+			{Identifier: "contract", Type: cadence.StringType},
+		}, nil)
+		timestamp := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+		srv.GetEvents.Run(func(args mock.Arguments) {
+			assert.Equal(t, uint64(11), args.Get(2).(uint64))
+			assert.Equal(t, uint64(12), args.Get(3).(uint64))
+		}).Return([]flow.BlockEvents{{
+			Height:         12,
+			BlockTimestamp: timestamp,
+			Events: []flow.Event{{
+				Type:          flow.EventAccountContractAdded,
+				TransactionID: util.TestID,
+				Value: cadence.NewEvent([]cadence.Value{
+					cadence.NewAddress(serviceAddress),
+					cadence.String("Counter"),
+				}).WithType(contractEventType),
+			}},
+		}}, nil)
+		srv.GetAccount.Return(&flow.Account{
+			Address:   serviceAddress,
+			Contracts: map[string][]byte{"Counter": []byte(deployedCounter), "Math": []byte("access(all) contract Math {}")},
+		}, nil)
+
+		contracts := []*flowkitProject.Contract{
+			flowkitProject.NewContract("Counter", "Counter.cdc", []byte(deployedCounter), serviceAddress, "emulator-account", nil),
+			flowkitProject.NewContract("Math", "Math.cdc", []byte("access(all) contract Math {}"), serviceAddress, "emulator-account", nil),
+		}
+		records, err := recordDeployment(context.Background(), srv.Mock, rw, 11, contracts)
+		require.NoError(t, err)
+
+		expected := deploymentRecord{
+			Contract:      "Counter",
+			Address:       "0xf8d6e0586b0a20c7",
+			CodeHash:      codeHash([]byte(deployedCounter)),
+			TransactionID: util.TestID.String(),
+			BlockHeight:   12,
+			Timestamp:     timestamp,
+		}
+		assert.Equal(t, []deploymentRecord{expected}, records)
+
+		history, err := loadDeploymentHistory(state.ReaderWriter(), config.EmulatorNetwork.Name)
+		require.NoError(t, err)
+		assert.Equal(t, "emulator", history.Network)
+		assert.Equal(t, []deploymentRecord{expected}, history.Deployments)
+
+		record, ok := history.latest("Counter", serviceAddress)
+		assert.True(t, ok)
+		assert.Equal(t, expected, record)
+		_, ok = history.latest("Math", serviceAddress)
+		assert.False(t, ok)
+	})
+
+	t.Run("Record contracts deployed before a failure", func(t *testing.T) {
+		srv, state, rw := util.TestMocks(t)
+		srv.Network.Return(config.EmulatorNetwork)
+
+		// the latest block is 11 before the deployment and 12 after it
+		block := tests.NewBlock()
+		heights := []uint64{11, 12}
+		srv.GetBlock.Run(func(mock.Arguments) {
+			block.Height, heights = heights[0], heights[1:]
+		}).Return(block, nil)
+
+		contractEventType := cadence.NewEventType(nil, flow.EventAccountContractAdded, []cadence.Field{
+			{Identifier: "address", Type: cadence.AddressType},
+			{Identifier: "contract", Type: cadence.StringType},
+		}, nil)
+		srv.GetEvents.Run(func(args mock.Arguments) {
+			assert.Equal(t, uint64(12), args.Get(2).(uint64))
+			assert.Equal(t, uint64(12), args.Get(3).(uint64))
+		}).Return([]flow.BlockEvents{{
+			Height: 12,
+			Events: []flow.Event{{
+				Type:          flow.EventAccountContractAdded,
+				TransactionID: util.TestID,
+				Value: cadence.NewEvent([]cadence.Value{
+					cadence.NewAddress(serviceAddress),
+					cadence.String("Counter"),
+				}).WithType(contractEventType),
+			}},
+		}}, nil)
+		srv.GetAccount.Return(&flow.Account{
+			Address:   serviceAddress,
+			Contracts: map[string][]byte{"Counter": []byte(deployedCounter)},
+		}, nil)
+		srv.DeployProject.Return(nil, &flowkit.ProjectDeploymentError{})
+
+		require.NoError(t, rw.WriteFile("Counter.cdc", []byte(deployedCounter), 0644))
+		require.NoError(t, rw.WriteFile("Math.cdc", []byte("access(all) contract Math {}"), 0644))
+		state.Contracts().AddOrUpdate(config.Contract{Name: "Counter", Location: "Counter.cdc"})
+		state.Contracts().AddOrUpdate(config.Contract{Name: "Math", Location: "Math.cdc"})
+		state.Deployments().AddOrUpdate(config.Deployment{
+			Network:   config.EmulatorNetwork.Name,
+			Account:   config.DefaultEmulator.ServiceAccount,
+			Contracts: []config.ContractDeployment{{Name: "Counter"}, {Name: "Math"}},
+		})
+
+		_, err := deployContracts(context.Background(), util.NoLogger, srv.Mock, state, false, false, "")
+		assert.EqualError(t, err, "failed deploying all contracts")
+
+		history, err := loadDeploymentHistory(rw, config.EmulatorNetwork.Name)
+		require.NoError(t, err)
+		require.Len(t, history.Deployments, 1)
+		assert.Equal(t, "Counter", history.Deployments[0].Contract)
+		assert.Equal(t, util.TestID.String(), history.Deployments[0].TransactionID)
+	})
+
+	t.Run("Verify contracts against sources and history", func(t *testing.T) {
+		history := &deploymentHistory{
+			Network: "testnet",
+			Deployments: []deploymentRecord{
+				{Contract: "Counter", Address: "0x0000000000000001", CodeHash: "0123", TransactionID: "aa"},
+				{Contract: "Counter", Address: "0x0000000000000001", CodeHash: codeHash([]byte(deployedCounter)), TransactionID: "bb", BlockHeight: 5},
+				{Contract: "Token", Address: "0x0000000000000001", CodeHash: "0123", TransactionID: "cc"},
+			},
+		}
+		address := flow.HexToAddress("01")
+		deployed := []byte(deployedCounter)
+
+		verified := verifyContract(&contractPlan{Name: "Counter", Address: address, Status: planStatusUnchanged, DeployedCode: deployed}, history)
+		assert.Equal(t, verifyStatusVerified, verified.Status)
+		assert.Equal(t, "bb", verified.Record.TransactionID)
+
+		unrecorded := verifyContract(&contractPlan{Name: "Math", Address: address, Status: planStatusUnchanged, DeployedCode: deployed}, history)
+		assert.Equal(t, verifyStatusUnrecorded, unrecorded.Status)
+
+		changed := verifyContract(&contractPlan{Name: "Counter", Address: address, Status: planStatusUpdate, DeployedCode: deployed, Diff: "diff"}, history)
+		assert.Equal(t, verifyStatusDrifted, changed.Status)
+		assert.Equal(t, []string{"the code on chain differs from the local source"}, changed.Issues)
+
+		tampered := verifyContract(&contractPlan{Name: "Token", Address: address, Status: planStatusUnchanged, DeployedCode: deployed}, history)
+		assert.Equal(t, verifyStatusDrifted, tampered.Status)
+		assert.Equal(t, []string{
+			fmt.Sprintf("the code on chain has hash %s, but 0123 was recorded for transaction cc", codeHash(deployed)),
+		}, tampered.Issues)
+
+		notDeployed := verifyContract(&contractPlan{Name: "Other", Address: address, Status: planStatusNew}, history)
+		assert.Equal(t, verifyStatusNotDeployed, notDeployed.Status)
+
+		result := &verifyResult{network: "testnet", contracts: []contractVerification{verified, unrecorded, changed, tampered, notDeployed}}
+		assert.Equal(t, "1 verified, 1 unrecorded, 2 drifted", result.Oneliner())
+		assert.Equal(t, 1, result.ExitCode())
+		assert.Contains(t, result.String(), "Counter on 0x0000000000000001 matches the local source and the deployment of transaction bb at block 5\n")
+	})
+}
+
+func Test_ProjectDeploySelection(t *testing.T) {
+	address := flow.HexToAddress("0x01")
+	contracts := []*flowkitProject.Contract{
+		flowkitProject.NewContract("Math", "cadence/contracts/utils/Math.cdc", []byte("access(all) contract Math {}"), address, "core-account", nil),
+		flowkitProject.NewContract("Token", "cadence/contracts/Token.cdc", []byte("import Math from \"./utils/Math.cdc\"\naccess(all) contract Token {}"), address, "core-account", nil),
+		flowkitProject.NewContract("Counter", "cadence/contracts/Counter.cdc", []byte("import \"Token\"\nimport \"FungibleToken\"\naccess(all) contract Counter {}"), address, "app-account", nil),
+		flowkitProject.NewContract("Unrelated", "cadence/contracts/Unrelated.cdc", []byte("access(all) contract Unrelated {}"), address, "app-account", nil),
+	}
+
+	// sorting the deployment resolves the imports of the contracts
+	deployment, err := flowkitProject.NewDeployment(contracts, flowkitProject.LocationAliases{"FungibleToken": "9a0766d93b6608b7"})
+	require.NoError(t, err)
+	contracts, err = deployment.Sort()
+	require.NoError(t, err)
+
+	t.Run("Select contracts with their imports", func(t *testing.T) {
+		selection, err := newDeploymentSelection(contracts, []string{"Counter"}, nil, "testnet")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Counter"}, selection.Selected)
+		assert.Equal(t, []string{"Math", "Token"}, selection.Prerequisites)
+		assert.Equal(t, []string{"Unrelated"}, selection.Skipped)
+		assert.Equal(t, "Deploying 1 selected contract and 2 imported by them (Math, Token), skipping 1", selection.String())
+	})
+
+	t.Run("Select contracts of accounts", func(t *testing.T) {
+		selection, err := newDeploymentSelection(contracts, []string{"Math"}, []string{"app-account"}, "testnet")
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"Math", "Counter", "Unrelated"}, selection.Selected)
+		assert.Equal(t, []string{"Token"}, selection.Prerequisites)
+		assert.Empty(t, selection.Skipped)
+	})
+
+	t.Run("Fail unknown selection", func(t *testing.T) {
+		_, err := newDeploymentSelection(contracts, []string{"Missing"}, nil, "testnet")
+		assert.EqualError(t, err, "contract Missing is not deployed on network testnet")
+
+		_, err = newDeploymentSelection(contracts, nil, []string{"other-account"}, "testnet")
+		assert.EqualError(t, err, "account other-account does not deploy any contract on network testnet")
+	})
+
+	t.Run("Restrict deployments of the state", func(t *testing.T) {
+		_, state, rw := util.TestMocks(t)
+
+		_ = rw.WriteFile("Math.cdc", []byte("access(all) contract Math {}"), 0644)
+		_ = rw.WriteFile("Counter.cdc", []byte("import \"Math\"\naccess(all) contract Counter {}"), 0644)
+		_ = rw.WriteFile("Unrelated.cdc", []byte("access(all) contract Unrelated {}"), 0644)
+		for _, name := range []string{"Math", "Counter", "Unrelated"} {
+			state.Contracts().AddOrUpdate(config.Contract{Name: name, Location: name + ".cdc"})
+		}
+		state.Accounts().AddOrUpdate(&accounts.Account{Name: "other-account", Address: flow.HexToAddress("0x02")})
+		state.Deployments().AddOrUpdate(config.Deployment{
+			Network:   config.EmulatorNetwork.Name,
+			Account:   config.DefaultEmulator.ServiceAccount,
+			Contracts: []config.ContractDeployment{{Name: "Math"}, {Name: "Counter"}},
+		})
+		state.Deployments().AddOrUpdate(config.Deployment{
+			Network:   config.EmulatorNetwork.Name,
+			Account:   "other-account",
+			Contracts: []config.ContractDeployment{{Name: "Unrelated"}},
+		})
+
+		selection, err := selectDeployment(state, config.EmulatorNetwork, []string{"Counter"}, nil)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Math"}, selection.Prerequisites)
+
+		deployments := state.Deployments().ByNetwork(config.EmulatorNetwork.Name)
+		require.Len(t, deployments, 1)
+		assert.Equal(t, []config.ContractDeployment{{Name: "Math"}, {Name: "Counter"}}, deployments[0].Contracts)
+	})
+}
+
+func Test_ProjectPromote(t *testing.T) {
+	serviceAddress := flow.HexToAddress("f8d6e0586b0a20c7")
+
+	setup := func(t *testing.T, local string, history *deploymentHistory) (*mocks.MockServices, *mocks.MockServices, *flowkit.State) {
+		source, state, rw := util.TestMocks(t)
+		source.Network.Return(config.TestnetNetwork)
+		source.GetAccount.Return(&flow.Account{
+			Address:   serviceAddress,
+			Contracts: map[string][]byte{"Counter": []byte(deployedCounter)},
+		}, nil)
+
+		target := mocks.DefaultMockServices()
+		target.Network.Return(config.MainnetNetwork)
+
+		_ = rw.WriteFile("Counter.cdc", []byte(local), 0644)
+		state.Contracts().AddOrUpdate(config.Contract{Name: "Counter", Location: "Counter.cdc"})
+		for _, network := range []string{config.TestnetNetwork.Name, config.MainnetNetwork.Name} {
+			state.Deployments().AddOrUpdate(config.Deployment{
+				Network:   network,
+				Account:   config.DefaultEmulator.ServiceAccount,
+				Contracts: []config.ContractDeployment{{Name: "Counter"}},
+			})
+		}
+
+		if history != nil {
+			require.NoError(t, history.save(rw))
+		}
+		return source, target, state
+	}
+
+	recorded := &deploymentHistory{
+		Network: config.TestnetNetwork.Name,
+		Deployments: []deploymentRecord{{
+			Contract:      "Counter",
+			Address:       serviceAddress.HexWithPrefix(),
+			CodeHash:      codeHash([]byte(deployedCounter)),
+			TransactionID: "aa",
+			BlockHeight:   5,
+		}},
+	}
+
+	t.Run("Verify recorded deployments", func(t *testing.T) {
+		source, target, state := setup(t, deployedCounter, recorded)
+
+		promoted, err := verifyPromotion(context.Background(), source.Mock, target.Mock, state)
+		require.NoError(t, err)
+		assert.Equal(t, []promotedContract{{
+			Name:              "Counter",
+			Address:           serviceAddress,
+			SourceTransaction: "aa",
+			CodeHash:          codeHash([]byte(deployedCounter)),
+		}}, promoted)
+		target.Mock.AssertNotCalled(t, "GetAccount", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Fail local source differs", func(t *testing.T) {
+		source, target, state := setup(t, updatedCounter, recorded)
+
+		_, err := verifyPromotion(context.Background(), source.Mock, target.Mock, state)
+		assert.EqualError(t, err, "refusing to promote contracts from testnet to mainnet:\n  - Counter.cdc differs from the code deployed on testnet")
+	})
+
+	t.Run("Fail deployment not recorded", func(t *testing.T) {
+		source, target, state := setup(t, deployedCounter, nil)
+
+		_, err := verifyPromotion(context.Background(), source.Mock, target.Mock, state)
+		assert.EqualError(t, err, "refusing to promote contracts from testnet to mainnet:\n  - no deployment of Counter is recorded on testnet")
+	})
+
+	t.Run("Fail recorded hash differs", func(t *testing.T) {
+		tampered := &deploymentHistory{
+			Network:     config.TestnetNetwork.Name,
+			Deployments: []deploymentRecord{recorded.Deployments[0]},
+		}
+		tampered.Deployments[0].CodeHash = "0123"
+		source, target, state := setup(t, deployedCounter, tampered)
+
+		_, err := verifyPromotion(context.Background(), source.Mock, target.Mock, state)
+		assert.EqualError(t, err, fmt.Sprintf(
+			"refusing to promote contracts from testnet to mainnet:\n  - Counter on testnet has hash %s, but 0123 was recorded for transaction aa",
+			codeHash([]byte(deployedCounter)),
+		))
+	})
+
+	t.Run("Fail same network", func(t *testing.T) {
+		promoteFlags = flagsPromote{From: "testnet", To: "testnet"}
+		defer func() { promoteFlags = flagsPromote{} }()
+
+		_, state, _ := util.TestMocks(t)
+		_, err := promote([]string{}, command.GlobalFlags{}, util.NoLogger, nil, state)
+		assert.EqualError(t, err, "the --from and --to networks must be different")
+	})
+}
+
+func Test_ProjectDeployOffline(t *testing.T) {
+	address := flow.HexToAddress("f8d6e0586b0a20c7")
+
+	t.Run("Build deployment transactions", func(t *testing.T) {
+		contract := &contractPlan{
+			Name:     "Counter",
+			Address:  address,
+			KeyIndex: 1,
+			Status:   planStatusNew,
+			Code:     []byte(deployedCounter),
+			Args:     []cadence.Value{cadence.String("hello"), cadence.UInt64(5)},
+		}
+
+		tx, err := deployTransaction(contract, flow.HexToID("01"), 7)
+		require.NoError(t, err)
+
+		decoded, err := flow.DecodeTransaction(tx.Encode())
+		require.NoError(t, err)
+		assert.Contains(t, string(decoded.Script), "transaction(name: String, code: String, arg0: String, arg1: UInt64)")
+		assert.Contains(t, string(decoded.Script), "signer.contracts.add(name: name, code: code.decodeHex(), arg0, arg1)")
+		assert.Equal(t, flow.ProposalKey{Address: address, KeyIndex: 1, SequenceNumber: 7}, decoded.ProposalKey)
+		assert.Equal(t, address, decoded.Payer)
+		assert.Equal(t, []flow.Address{address}, decoded.Authorizers)
+		assert.Equal(t, uint64(deployComputeLimit), decoded.GasLimit)
+		require.Len(t, decoded.Arguments, 4)
+
+		code, err := decoded.Argument(1)
+		require.NoError(t, err)
+		assert.Equal(t, cadence.String(hex.EncodeToString([]byte(deployedCounter))), code)
+
+		contract.Status = planStatusUpdate
+		tx, err = deployTransaction(contract, flow.HexToID("01"), 8)
+		require.NoError(t, err)
+		assert.Contains(t, string(tx.Script), "signer.contracts.update(name: name, code: code.decodeHex())")
+		assert.Len(t, tx.Arguments, 2)
+
+		contract.Status = planStatusUnchanged
+		_, err = deployTransaction(contract, flow.HexToID("01"), 9)
+		assert.EqualError(t, err, "contract Counter is unchanged, it can not be deployed")
+	})
+
+	t.Run("Build and submit deployment", func(t *testing.T) {
+		srv, state, rw := util.TestMocks(t)
+		srv.Network.Return(config.EmulatorNetwork)
+		srv.GetBlock.Return(tests.NewBlock(), nil)
+		srv.GetAccount.Return(&flow.Account{
+			Address: address,
+			Keys:    []*flow.AccountKey{{Index: 0, SequenceNumber: 7}},
+		}, nil)
+
+		_ = rw.WriteFile("Counter.cdc", []byte("import \"Math\"\n"+deployedCounter), 0644)
+		_ = rw.WriteFile("Math.cdc", []byte("access(all) contract Math {}"), 0644)
+		state.Contracts().AddOrUpdate(config.Contract{Name: "Counter", Location: "Counter.cdc"})
+		state.Contracts().AddOrUpdate(config.Contract{Name: "Math", Location: "Math.cdc"})
+		state.Deployments().AddOrUpdate(config.Deployment{
+			Network:   config.EmulatorNetwork.Name,
+			Account:   config.DefaultEmulator.ServiceAccount,
+			Contracts: []config.ContractDeployment{{Name: "Counter"}, {Name: "Math"}},
+		})
+
+		built, err := buildDeployment(context.Background(), srv.Mock, state, "build", false)
+		require.NoError(t, err)
+		assert.Equal(t, []builtTransaction{
+			{File: "001-Math.rlp", Contract: "Math", Address: "0xf8d6e0586b0a20c7", Action: planStatusNew},
+			{File: "002-Counter.rlp", Contract: "Counter", Address: "0xf8d6e0586b0a20c7", Action: planStatusNew},
+		}, built.manifest.Transactions)
+
+		var sequenceNumbers []uint64
+		for _, file := range []string{"build/001-Math.rlp", "build/002-Counter.rlp"} {
+			payload, err := rw.ReadFile(file)
+			require.NoError(t, err)
+			decoded, err := hex.DecodeString(string(payload))
+			require.NoError(t, err)
+			tx, err := flow.DecodeTransaction(decoded)
+			require.NoError(t, err)
+			sequenceNumbers = append(sequenceNumbers, tx.ProposalKey.SequenceNumber)
+		}
+		assert.Equal(t, []uint64{7, 8}, sequenceNumbers)
+
+		_, err = submitDeployment(context.Background(), util.NoLogger, srv.Mock, state, "build")
+		assert.EqualError(t, err, "transaction build/001-Math.rlp is not signed by its payer 0xf8d6e0586b0a20c7")
+		srv.Mock.AssertNotCalled(t, "SendSignedTransaction", mock.Anything, mock.Anything)
+
+		for _, file := range []string{"build/001-Math.rlp", "build/002-Counter.rlp"} {
+			payload, _ := rw.ReadFile(file)
+			decoded, _ := hex.DecodeString(string(payload))
+			tx, _ := flow.DecodeTransaction(decoded)
+			tx.AddEnvelopeSignature(address, 0, []byte{1})
+			_ = rw.WriteFile(file, []byte(hex.EncodeToString(tx.Encode())), 0644)
+		}
+
+		var sent []string
+		srv.SendSignedTransaction.Run(func(args mock.Arguments) {
+			tx := args.Get(1).(*transactions.Transaction)
+			arg, _ := tx.FlowTransaction().Argument(0)
+			sent = append(sent, string(arg.(cadence.String)))
+		}).Return(nil, &flow.TransactionResult{Status: flow.TransactionStatusSealed}, nil)
+		srv.GetEvents.Return(nil, nil)
+
+		result, err := submitDeployment(context.Background(), util.NoLogger, srv.Mock, state, "build")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Math", "Counter"}, sent)
+		assert.Len(t, result.contracts, 2)
+	})
+
+	t.Run("Fail submitting to another network", func(t *testing.T) {
+		_, state, rw := util.TestMocks(t)
+		_ = rw.WriteFile("build/deployment.json", []byte(`{"network": "testnet", "transactions": []}`), 0644)
+
+		_, _, err := loadSignedDeployment(state.ReaderWriter(), "build", "mainnet")
+		assert.EqualError(t, err, "transactions in build were built for network testnet, not mainnet")
+	})
+}
+
+func Test_ProjectInitArguments(t *testing.T) {
+	const token = `access(all) contract Token {
+    init(name: String, supply: UInt64, admins: [Address], limits: {String: UFix64}, owner: Address?) {}
+}`
+	env := func(values map[string]string) func(string) (string, bool) {
+		return func(name string) (string, bool) {
+			value, ok := values[name]
+			return value, ok
+		}
+	}
+
+	t.Run("Parse initializer parameters", func(t *testing.T) {
+		params, err := contractInitParameters([]byte(token))
+		require.NoError(t, err)
+
+		var signature []string
+		for _, param := range params {
+			signature = append(signature, param.String())
+		}
+		assert.Equal(t, []string{"name: String", "supply: UInt64", "admins: [Address]", "limits: {String: UFix64}", "owner: Address?"}, signature)
+
+		params, err = contractInitParameters([]byte("access(all) contract interface Token {}"))
+		require.NoError(t, err)
+		assert.Empty(t, params)
+	})
+
+	t.Run("Check arguments against parameters", func(t *testing.T) {
+		params, err := contractInitParameters([]byte(token))
+		require.NoError(t, err)
+
+		args := []cadence.Value{
+			cadence.String("Token"),
+			cadence.String("1000"),
+			cadence.NewArray([]cadence.Value{cadence.NewAddress(flow.HexToAddress("01"))}),
+			cadence.NewDictionary([]cadence.KeyValuePair{{Key: cadence.String("daily"), Value: cadence.UInt64(10)}}),
+			cadence.NewOptional(nil),
+		}
+		checked, errs := checkInitArguments(params, args)
+		assert.Equal(t, []string{
+			"parameter `supply`: expected UInt64, found String",
+			"parameter `limits`: value 0: expected UFix64, found UInt64",
+		}, errorMessages(errs))
+
+		admins := checked[2].(cadence.Array)
+		assert.Equal(t, "[Address]", admins.Type().ID())
+
+		_, errs = checkInitArguments(params, args[:1])
+		assert.Equal(t, []string{
+			"the initializer init(name: String, supply: UInt64, admins: [Address], limits: {String: UFix64}, owner: Address?) takes 5 arguments, but 1 was given",
+		}, errorMessages(errs))
+	})
+
+	t.Run("Parse YAML arguments with network environment", func(t *testing.T) {
+		params, err := contractInitParameters([]byte(token))
+		require.NoError(t, err)
+
+		data, err := substituteEnv([]byte(`name: Token
+supply: ${SUPPLY}
+admins: ["${ADMIN}", "0x02"]
+limits:
+  daily: 10
+owner: null
+`), "testnet", env(map[string]string{"SUPPLY": "1000", "ADMIN": "0x01", "ADMIN_TESTNET": "0x03"}))
+		require.NoError(t, err)
+
+		args, err := parseYAMLArgs(data, params)
+		require.NoError(t, err)
+		require.Len(t, args, 5)
+		assert.Equal(t, cadence.String("Token"), args[0])
+		assert.Equal(t, cadence.UInt64(1000), args[1])
+		assert.Equal(t, []cadence.Value{
+			cadence.NewAddress(flow.HexToAddress("03")),
+			cadence.NewAddress(flow.HexToAddress("02")),
+		}, args[2].(cadence.Array).Values)
+		assert.Equal(t, cadence.UFix64(10_00000000), args[3].(cadence.Dictionary).Pairs[0].Value)
+		assert.Equal(t, cadence.NewOptional(nil), args[4])
+
+		_, err = substituteEnv([]byte("supply: ${SUPPLY}"), "mainnet", env(nil))
+		assert.EqualError(t, err, "environment variables are not set: SUPPLY_MAINNET or SUPPLY")
+
+		_, err = parseYAMLArgs([]byte("name: Token\nsupply: many"), params)
+		assert.EqualError(t, err, "invalid value for parameter `supply`: invalid UInt64 \"many\"")
+	})
+
+	t.Run("Resolve args files of deployments", func(t *testing.T) {
+		srv, state, rw := util.TestMocks(t)
+		srv.GetAccount.Return(&flow.Account{Address: flow.HexToAddress("f8d6e0586b0a20c7")}, nil)
+
+		_ = rw.WriteFile("Token.cdc", []byte("access(all) contract Token {\n    init(name: String, supply: UInt64) {}\n}\n"), 0644)
+		state.Contracts().AddOrUpdate(config.Contract{Name: "Token", Location: "Token.cdc"})
+		for _, network := range []string{config.TestnetNetwork.Name, config.MainnetNetwork.Name} {
+			state.Deployments().AddOrUpdate(config.Deployment{
+				Network:   network,
+				Account:   config.DefaultEmulator.ServiceAccount,
+				Contracts: []config.ContractDeployment{{Name: "Token"}},
+			})
+		}
+		_ = rw.WriteFile(".flow/args/Token.yaml", []byte("name: Token\nsupply: ${SUPPLY}\n"), 0644)
+		_ = rw.WriteFile(".flow/args/mainnet/Token.json", []byte(`[{"type": "String", "value": "Token"}, {"type": "String", "value": "${SUPPLY}"}]`), 0644)
+
+		srv.Network.Return(config.TestnetNetwork)
+		err := resolveInitArguments(context.Background(), srv.Mock, state, util.NoLogger, env(map[string]string{"SUPPLY": "1000"}))
+		require.NoError(t, err)
+		deployments := state.Deployments().ByNetwork(config.TestnetNetwork.Name)
+		assert.Equal(t, []cadence.Value{cadence.String("Token"), cadence.UInt64(1000)}, deployments[0].Contracts[0].Args)
+
+		srv.Network.Return(config.MainnetNetwork)
+		err = resolveInitArguments(context.Background(), srv.Mock, state, util.NoLogger, env(map[string]string{"SUPPLY": "1000"}))
+		assert.EqualError(t, err, "invalid initializer arguments, nothing has been deployed:\n  - Token (Token.cdc): parameter `supply`: expected UInt64, found String")
+	})
+
+	t.Run("Ignore arguments of deployed contracts", func(t *testing.T) {
+		srv, state, rw := util.TestMocks(t)
+		srv.Network.Return(config.TestnetNetwork)
+		srv.GetAccount.Return(&flow.Account{
+			Address:   flow.HexToAddress("f8d6e0586b0a20c7"),
+			Contracts: map[string][]byte{"Token": []byte("access(all) contract Token {}")},
+		}, nil)
+
+		_ = rw.WriteFile("Token.cdc", []byte("access(all) contract Token {\n    init(name: String, supply: UInt64) {}\n}\n"), 0644)
+		state.Contracts().AddOrUpdate(config.Contract{Name: "Token", Location: "Token.cdc"})
+		state.Deployments().AddOrUpdate(config.Deployment{
+			Network:   config.TestnetNetwork.Name,
+			Account:   config.DefaultEmulator.ServiceAccount,
+			Contracts: []config.ContractDeployment{{Name: "Token"}},
+		})
+
+		err := resolveInitArguments(context.Background(), srv.Mock, state, util.NoLogger, env(nil))
+		require.NoError(t, err)
+	})
+}
+
+func errorMessages(errs []error) []string {
+	var messages []string
+	for _, err := range errs {
+		messages = append(messages, err.Error())
+	}
+	return messages
+}
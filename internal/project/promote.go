@@ -0,0 +1,284 @@
+/*
+ * Flow CLI
+ *
+ * Copyright Flow Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package project
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	flowsdk "github.com/onflow/flow-go-sdk"
+	"github.com/spf13/cobra"
+
+	"github.com/onflow/flowkit/v2"
+	"github.com/onflow/flowkit/v2/config"
+	"github.com/onflow/flowkit/v2/output"
+
+	"github.com/onflow/flow-cli/internal/command"
+	"github.com/onflow/flow-cli/internal/util"
+)
+
+type flagsPromote struct {
+	From   string `flag:"from" default:"" info:"network the contracts were deployed and tested on"`
+	To     string `flag:"to" default:"" info:"network to deploy the same contracts to"`
+	Update bool   `flag:"update" default:"false" info:"update contracts already deployed on the target network"`
+}
+
+var promoteFlags = flagsPromote{}
+
+var PromoteCommand = &command.Command{
+	Cmd: &cobra.Command{
+		Use:     "promote",
+		Short:   "Deploy the contracts deployed on one network to another network",
+		Long:    "Deploy the contracts deployed on one network to another network, refusing if the local sources differ from the deployments recorded on the source network.",
+		Example: "flow project promote --from testnet --to mainnet",
+		Args:    cobra.NoArgs,
+	},
+	Flags: &promoteFlags,
+	RunS:  promote,
+}
+
+// networkServices returns the services for a network of the project.
+var networkServices = func(state *flowkit.State, name string, logger output.Logger) (flowkit.Services, error) {
+	network, err := state.Networks().ByName(name)
+	if err != nil {
+		return nil, fmt.Errorf("network with name %s does not exist in configuration", name)
+	}
+
+	gw, err := command.CreateGateway(*network)
+	if err != nil {
+		return nil, err
+	}
+
+	return flowkit.NewFlowkit(state, *network, gw, logger), nil
+}
+
+func promote(
+	_ []string,
+	global command.GlobalFlags,
+	logger output.Logger,
+	_ flowkit.Services,
+	state *flowkit.State,
+) (command.Result, error) {
+	if promoteFlags.From == "" || promoteFlags.To == "" {
+		return nil, fmt.Errorf("both the --from and --to networks are required")
+	}
+	if promoteFlags.From == promoteFlags.To {
+		return nil, fmt.Errorf("the --from and --to networks must be different")
+	}
+
+	source, err := networkServices(state, promoteFlags.From, logger)
+	if err != nil {
+		return nil, err
+	}
+	target, err := networkServices(state, promoteFlags.To, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+
+	logger.StartProgress(fmt.Sprintf("Verifying contracts deployed on %s...", promoteFlags.From))
+	promoted, err := verifyPromotion(ctx, source, target, state)
+	logger.StopProgress()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := resolveInitArguments(ctx, target, state, logger, os.LookupEnv); err != nil {
+		return nil, err
+	}
+
+	if target.Network() == config.MainnetNetwork {
+		err := checkForStandardContractUsageOnMainnet(state, logger, global.Yes)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	deployed, err := deployContracts(
+		ctx,
+		logger,
+		target,
+		state,
+		promoteFlags.Update,
+		false,
+		fmt.Sprintf("flow project promote --from %s --to %s --update", promoteFlags.From, promoteFlags.To),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &promoteResult{
+		from:      promoteFlags.From,
+		to:        promoteFlags.To,
+		contracts: promoted,
+		deployed:  deployed,
+	}, nil
+}
+
+// promotedContract is a contract deployed on the target network
+// as it was recorded on the source network.
+type promotedContract struct {
+	Name              string
+	Address           flowsdk.Address
+	SourceTransaction string
+	CodeHash          string
+}
+
+// verifyPromotion checks that every contract deployed on the target network is deployed on the
+// source network from the same local source, as recorded in the deployment history of the source network.
+//
+// The contracts are then byte-identical on both networks, apart from the addresses of their imports.
+func verifyPromotion(
+	ctx context.Context,
+	source flowkit.Services,
+	target flowkit.Services,
+	state *flowkit.State,
+) ([]promotedContract, error) {
+	sourceNetwork := source.Network().Name
+
+	targetContracts, err := state.DeploymentContractsByNetwork(target.Network())
+	if err != nil {
+		return nil, err
+	}
+	if len(targetContracts) == 0 {
+		return nil, fmt.Errorf("no contracts are deployed on network %s", target.Network().Name)
+	}
+
+	sourceContracts, err := compareDeployment(ctx, source, state)
+	if err != nil {
+		return nil, err
+	}
+	bySource := make(map[string]*contractPlan)
+	for _, contract := range sourceContracts {
+		bySource[contract.Name] = contract
+	}
+
+	history, err := loadDeploymentHistory(state.ReaderWriter(), sourceNetwork)
+	if err != nil {
+		return nil, err
+	}
+
+	var promoted []promotedContract
+	var problems []string
+	for _, contract := range targetContracts {
+		deployed, ok := bySource[contract.Name]
+		if !ok || deployed.Status == planStatusNew {
+			problems = append(problems, fmt.Sprintf("%s is not deployed on %s", contract.Name, sourceNetwork))
+			continue
+		}
+		if deployed.Status != planStatusUnchanged {
+			problems = append(problems, fmt.Sprintf(
+				"%s differs from the code deployed on %s",
+				deployed.Location,
+				sourceNetwork,
+			))
+			continue
+		}
+
+		record, ok := history.latest(deployed.Name, deployed.Address)
+		if !ok {
+			problems = append(problems, fmt.Sprintf("no deployment of %s is recorded on %s", deployed.Name, sourceNetwork))
+			continue
+		}
+		if hash := codeHash(deployed.DeployedCode); hash != record.CodeHash {
+			problems = append(problems, fmt.Sprintf(
+				"%s on %s has hash %s, but %s was recorded for transaction %s",
+				deployed.Name,
+				sourceNetwork,
+				hash,
+				record.CodeHash,
+				record.TransactionID,
+			))
+			continue
+		}
+
+		promoted = append(promoted, promotedContract{
+			Name:              contract.Name,
+			Address:           contract.AccountAddress,
+			SourceTransaction: record.TransactionID,
+			CodeHash:          record.CodeHash,
+		})
+	}
+
+	if len(problems) > 0 {
+		return nil, fmt.Errorf(
+			"refusing to promote contracts from %s to %s:\n  - %s",
+			sourceNetwork,
+			target.Network().Name,
+			strings.Join(problems, "\n  - "),
+		)
+	}
+	return promoted, nil
+}
+
+type promoteResult struct {
+	from      string
+	to        string
+	contracts []promotedContract
+	deployed  *deployResult
+}
+
+func (r *promoteResult) JSON() any {
+	result := make(map[string]any)
+	for _, contract := range r.contracts {
+		result[contract.Name] = map[string]any{
+			"address":           contract.Address.HexWithPrefix(),
+			"sourceTransaction": contract.SourceTransaction,
+			"codeHash":          contract.CodeHash,
+		}
+	}
+	return result
+}
+
+func (r *promoteResult) String() string {
+	var b strings.Builder
+
+	_, _ = fmt.Fprintf(
+		&b,
+		"%s Promoted %d %s from %s to %s:\n",
+		output.SuccessEmoji(),
+		len(r.contracts),
+		util.Pluralize("contract", len(r.contracts)),
+		r.from,
+		r.to,
+	)
+	for _, contract := range r.contracts {
+		_, _ = fmt.Fprintf(
+			&b,
+			"    %s on %s, as deployed by transaction %s on %s\n",
+			contract.Name,
+			contract.Address.HexWithPrefix(),
+			contract.SourceTransaction,
+			r.from,
+		)
+	}
+
+	if history := r.deployed.String(); history != "" {
+		_, _ = fmt.Fprintf(&b, "%s\n", history)
+	}
+
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+func (r *promoteResult) Oneliner() string {
+	return fmt.Sprintf("promoted %d contracts from %s to %s", len(r.contracts), r.from, r.to)
+}
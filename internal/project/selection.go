@@ -0,0 +1,168 @@
+/*
+ * Flow CLI
+ *
+ * Copyright Flow Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package project
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/exp/slices"
+
+	"github.com/onflow/flowkit/v2"
+	"github.com/onflow/flowkit/v2/config"
+	"github.com/onflow/flowkit/v2/project"
+
+	"github.com/onflow/flow-cli/internal/util"
+)
+
+// deploymentSelection is the part of the deployment of a network which is deployed,
+// in deployment order.
+type deploymentSelection struct {
+	// Selected are the contracts selected by name or account
+	Selected []string
+	// Prerequisites are the contracts only deployed because selected contracts import them
+	Prerequisites []string
+	// Skipped are the contracts which are not deployed
+	Skipped []string
+}
+
+// selectDeployment restricts the deployment of the network to the contracts selected by name
+// and the contracts deployed on the selected accounts, together with the contracts they import.
+//
+// The deployments of the state are changed in memory only, for the rest of the command.
+func selectDeployment(
+	state *flowkit.State,
+	network config.Network,
+	contractNames []string,
+	accountNames []string,
+) (*deploymentSelection, error) {
+	contracts, err := state.DeploymentContractsByNetwork(network)
+	if err != nil {
+		return nil, err
+	}
+
+	deployment, err := project.NewDeployment(contracts, state.AliasesForNetwork(network))
+	if err != nil {
+		return nil, err
+	}
+
+	sorted, err := deployment.Sort()
+	if err != nil {
+		return nil, err
+	}
+
+	selection, err := newDeploymentSelection(sorted, contractNames, accountNames, network.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	deployed := append(slices.Clone(selection.Selected), selection.Prerequisites...)
+	deployments := state.Deployments().ByNetwork(network.Name)
+	for _, d := range deployments {
+		var kept []config.ContractDeployment
+		for _, c := range d.Contracts {
+			if slices.Contains(deployed, c.Name) {
+				kept = append(kept, c)
+			}
+		}
+
+		if len(kept) == 0 {
+			_ = state.Deployments().Remove(d.Account, d.Network)
+			continue
+		}
+		d.Contracts = kept
+		state.Deployments().AddOrUpdate(d)
+	}
+
+	return selection, nil
+}
+
+// newDeploymentSelection selects the sorted contracts by name and account,
+// and the contracts they import directly or indirectly, as resolved by sorting the deployment.
+func newDeploymentSelection(
+	sorted []*project.Contract,
+	contractNames []string,
+	accountNames []string,
+	network string,
+) (*deploymentSelection, error) {
+	byName := make(map[string]*project.Contract)
+	for _, contract := range sorted {
+		byName[contract.Name] = contract
+	}
+
+	for _, name := range contractNames {
+		if _, ok := byName[name]; !ok {
+			return nil, fmt.Errorf("contract %s is not deployed on network %s", name, network)
+		}
+	}
+	for _, name := range accountNames {
+		if !slices.ContainsFunc(sorted, func(contract *project.Contract) bool {
+			return contract.AccountName == name
+		}) {
+			return nil, fmt.Errorf("account %s does not deploy any contract on network %s", name, network)
+		}
+	}
+
+	selected := make(map[string]bool)
+	required := make(map[string]bool)
+
+	// Contracts imported from addresses or by aliases are already deployed, and are not dependencies
+	var addRequired func(contract *project.Contract)
+	addRequired = func(contract *project.Contract) {
+		if required[contract.Name] {
+			return
+		}
+		required[contract.Name] = true
+
+		for _, dependency := range contract.Dependencies() {
+			if imported, ok := byName[dependency.Name]; ok {
+				addRequired(imported)
+			}
+		}
+	}
+
+	for _, contract := range sorted {
+		if !slices.Contains(contractNames, contract.Name) && !slices.Contains(accountNames, contract.AccountName) {
+			continue
+		}
+		selected[contract.Name] = true
+		addRequired(contract)
+	}
+
+	selection := &deploymentSelection{}
+	for _, contract := range sorted {
+		switch {
+		case selected[contract.Name]:
+			selection.Selected = append(selection.Selected, contract.Name)
+		case required[contract.Name]:
+			selection.Prerequisites = append(selection.Prerequisites, contract.Name)
+		default:
+			selection.Skipped = append(selection.Skipped, contract.Name)
+		}
+	}
+	return selection, nil
+}
+
+func (s *deploymentSelection) String() string {
+	message := fmt.Sprintf("Deploying %d selected %s", len(s.Selected), util.Pluralize("contract", len(s.Selected)))
+	if len(s.Prerequisites) > 0 {
+		message += fmt.Sprintf(" and %d imported by them (%s)", len(s.Prerequisites), strings.Join(s.Prerequisites, ", "))
+	}
+	return fmt.Sprintf("%s, skipping %d", message, len(s.Skipped))
+}
@@ -0,0 +1,94 @@
+/*
+ * Flow CLI
+ *
+ * Copyright Flow Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package project
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/onflow/cadence/ast"
+	"github.com/onflow/cadence/common"
+	cadenceErrors "github.com/onflow/cadence/errors"
+	"github.com/onflow/cadence/parser"
+	"github.com/onflow/cadence/stdlib"
+	flowsdk "github.com/onflow/flow-go-sdk"
+)
+
+// contractNamesProvider provides the contract update validator with the names of
+// the contracts deployed on accounts, which it needs to resolve address imports.
+type contractNamesProvider func(address flowsdk.Address) ([]string, error)
+
+var _ stdlib.AccountContractNamesProvider = contractNamesProvider(nil)
+
+func (p contractNamesProvider) GetAccountContractNames(address common.Address) ([]string, error) {
+	return p(flowsdk.Address(address))
+}
+
+// validateContractUpdate checks the update of the contract deployed on the address from the old
+// to the new code against the Cadence contract updatability rules, the same way the network does.
+//
+// It returns every rule the update violates, or an error if either code can not be parsed.
+func validateContractUpdate(
+	address flowsdk.Address,
+	name string,
+	oldCode []byte,
+	newCode []byte,
+	names contractNamesProvider,
+) ([]error, error) {
+	oldProgram, err := parser.ParseProgram(nil, oldCode, parser.Config{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse deployed code of contract %s: %w", name, err)
+	}
+
+	newProgram, err := parser.ParseProgram(nil, newCode, parser.Config{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse code of contract %s: %w", name, err)
+	}
+
+	location := common.NewAddressLocation(nil, common.Address(address), name)
+	err = stdlib.NewContractUpdateValidator(location, name, names, oldProgram, newProgram).Validate()
+	if err == nil {
+		return nil, nil
+	}
+
+	var updateErr *stdlib.ContractUpdateError
+	if errors.As(err, &updateErr) {
+		return updateErr.Errors, nil
+	}
+	return []error{err}, nil
+}
+
+// updateErrorMessage returns the message of a violation of the contract updatability rules,
+// prefixed with its position in the new code at the location if it has one.
+func updateErrorMessage(location string, err error) string {
+	message := err.Error()
+
+	var secondary cadenceErrors.SecondaryError
+	if errors.As(err, &secondary) && secondary.SecondaryError() != "" {
+		message = fmt.Sprintf("%s: %s", message, secondary.SecondaryError())
+	}
+
+	var positioned ast.HasPosition
+	if errors.As(err, &positioned) {
+		position := positioned.StartPosition()
+		message = fmt.Sprintf("%s:%d:%d: %s", location, position.Line, position.Column, message)
+	}
+
+	return message
+}
@@ -0,0 +1,227 @@
+/*
+ * Flow CLI
+ *
+ * Copyright Flow Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package project
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	flowsdk "github.com/onflow/flow-go-sdk"
+	"github.com/spf13/cobra"
+
+	"github.com/onflow/flowkit/v2"
+	"github.com/onflow/flowkit/v2/output"
+
+	"github.com/onflow/flow-cli/internal/command"
+)
+
+var VerifyCommand = &command.Command{
+	Cmd: &cobra.Command{
+		Use:     "verify",
+		Short:   "Verify deployed contracts match the local sources and the recorded deployments",
+		Example: "flow project verify --network testnet",
+		Args:    cobra.NoArgs,
+	},
+	Flags: &struct{}{},
+	RunS:  verify,
+}
+
+func verify(
+	_ []string,
+	_ command.GlobalFlags,
+	logger output.Logger,
+	flow flowkit.Services,
+	state *flowkit.State,
+) (command.Result, error) {
+	network := flow.Network().Name
+
+	logger.StartProgress(fmt.Sprintf("Verifying contracts on network %s...", network))
+	defer logger.StopProgress()
+
+	contracts, err := compareDeployment(context.Background(), flow, state)
+	if err != nil {
+		return nil, err
+	}
+
+	history, err := loadDeploymentHistory(state.ReaderWriter(), network)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &verifyResult{network: network}
+	for _, contract := range contracts {
+		result.contracts = append(result.contracts, verifyContract(contract, history))
+	}
+	return result, nil
+}
+
+type verifyStatus string
+
+const (
+	verifyStatusVerified    verifyStatus = "verified"
+	verifyStatusUnrecorded  verifyStatus = "unrecorded"
+	verifyStatusDrifted     verifyStatus = "drifted"
+	verifyStatusNotDeployed verifyStatus = "not deployed"
+)
+
+// contractVerification is whether the code of a contract on chain still matches
+// its local source and its last recorded deployment.
+type contractVerification struct {
+	Name     string
+	Address  flowsdk.Address
+	Status   verifyStatus
+	CodeHash string
+	Record   *deploymentRecord
+	Issues   []string
+	Diff     string
+}
+
+func verifyContract(contract *contractPlan, history *deploymentHistory) contractVerification {
+	verification := contractVerification{
+		Name:    contract.Name,
+		Address: contract.Address,
+	}
+
+	if contract.Status == planStatusNew {
+		verification.Status = verifyStatusNotDeployed
+		return verification
+	}
+
+	verification.CodeHash = codeHash(contract.DeployedCode)
+	if contract.Status != planStatusUnchanged {
+		verification.Issues = append(verification.Issues, "the code on chain differs from the local source")
+		verification.Diff = contract.Diff
+	}
+
+	record, recorded := history.latest(contract.Name, contract.Address)
+	if recorded {
+		verification.Record = &record
+		if record.CodeHash != verification.CodeHash {
+			verification.Issues = append(verification.Issues, fmt.Sprintf(
+				"the code on chain has hash %s, but %s was recorded for transaction %s",
+				verification.CodeHash,
+				record.CodeHash,
+				record.TransactionID,
+			))
+		}
+	}
+
+	switch {
+	case len(verification.Issues) > 0:
+		verification.Status = verifyStatusDrifted
+	case !recorded:
+		verification.Status = verifyStatusUnrecorded
+	default:
+		verification.Status = verifyStatusVerified
+	}
+	return verification
+}
+
+type verifyResult struct {
+	network   string
+	contracts []contractVerification
+}
+
+var _ command.ResultWithExitCode = &verifyResult{}
+
+func (r *verifyResult) count(status verifyStatus) int {
+	count := 0
+	for _, contract := range r.contracts {
+		if contract.Status == status {
+			count++
+		}
+	}
+	return count
+}
+
+func (r *verifyResult) JSON() any {
+	result := make(map[string]any)
+	for _, contract := range r.contracts {
+		issues := contract.Issues
+		if issues == nil {
+			issues = []string{}
+		}
+		verification := map[string]any{
+			"address":  contract.Address.HexWithPrefix(),
+			"status":   contract.Status,
+			"codeHash": contract.CodeHash,
+			"issues":   issues,
+		}
+		if contract.Record != nil {
+			verification["deployment"] = contract.Record
+		}
+		result[contract.Name] = verification
+	}
+	return result
+}
+
+func (r *verifyResult) String() string {
+	var b strings.Builder
+
+	_, _ = fmt.Fprintf(&b, "Contracts on network %s:\n\n", r.network)
+	for _, contract := range r.contracts {
+		address := contract.Address.HexWithPrefix()
+		switch contract.Status {
+		case verifyStatusVerified:
+			_, _ = fmt.Fprintf(
+				&b,
+				"%s %s on %s matches the local source and the deployment of transaction %s at block %d\n",
+				output.OkEmoji(),
+				contract.Name,
+				address,
+				contract.Record.TransactionID,
+				contract.Record.BlockHeight,
+			)
+		case verifyStatusUnrecorded:
+			_, _ = fmt.Fprintf(&b, "%s %s on %s matches the local source, but no deployment of it is recorded\n", output.WarningEmoji(), contract.Name, address)
+		case verifyStatusDrifted:
+			_, _ = fmt.Fprintf(&b, "%s %s on %s drifted:\n", output.ErrorEmoji(), contract.Name, address)
+			for _, issue := range contract.Issues {
+				_, _ = fmt.Fprintf(&b, "    - %s\n", issue)
+			}
+		case verifyStatusNotDeployed:
+			_, _ = fmt.Fprintf(&b, "   %s is not deployed on %s\n", contract.Name, address)
+		}
+	}
+
+	for _, contract := range r.contracts {
+		if contract.Diff != "" {
+			_, _ = fmt.Fprintf(&b, "\n%s", contract.Diff)
+		}
+	}
+
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+func (r *verifyResult) Oneliner() string {
+	return fmt.Sprintf(
+		"%d verified, %d unrecorded, %d drifted",
+		r.count(verifyStatusVerified),
+		r.count(verifyStatusUnrecorded),
+		r.count(verifyStatusDrifted),
+	)
+}
+
+func (r *verifyResult) ExitCode() int {
+	if r.count(verifyStatusDrifted) > 0 {
+		return 1
+	}
+	return 0
+}
@@ -23,12 +23,13 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
-	"time"
 
 	"github.com/pkg/errors"
 	"github.com/radovskyb/watcher"
 
 	"github.com/onflow/flowkit/v2/config"
+
+	"github.com/onflow/flow-cli/internal/util"
 )
 
 const (
@@ -120,18 +121,11 @@ func (f *projectFiles) transactions() ([]string, error) {
 // This function returns two channels, accountChange which reports any changes on the accounts folders and
 // contractChange which reports any changes to the contract files.
 func (f *projectFiles) watch() (<-chan accountChange, <-chan contractChange, error) {
-	err := f.watcher.AddRecursive(filepath.Join(f.cadencePath, contractDir))
+	startErr, err := util.WatchFiles(f.watcher, filepath.Join(f.cadencePath, contractDir))
 	if err != nil {
-		return nil, nil, errors.Wrap(err, "add recursive files failed")
+		return nil, nil, err
 	}
 
-	go func() {
-		err = f.watcher.Start(500 * time.Millisecond)
-		if err != nil {
-			panic(err)
-		}
-	}()
-
 	accounts := make(chan accountChange)
 	contracts := make(chan contractChange)
 
@@ -179,6 +173,8 @@ func (f *projectFiles) watch() (<-chan accountChange, <-chan contractChange, err
 					oldPath: oldPath,
 					account: name,
 				}
+			case err := <-startErr:
+				panic(err)
 			case <-f.watcher.Closed:
 				close(contracts)
 				close(accounts)
@@ -19,12 +19,16 @@
 package super
 
 import (
+	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/onflow/flowkit/v2"
 	"github.com/onflow/flowkit/v2/output"
 
 	"github.com/spf13/cobra"
+	"golang.org/x/exp/slices"
 
 	"github.com/onflow/flow-cli/internal/command"
 	"github.com/onflow/flow-cli/internal/super/generator"
@@ -38,6 +42,15 @@ type generateFlagsDef struct {
 
 var generateFlags = generateFlagsDef{}
 
+type generateBindingsFlagsDef struct {
+	Lang            string   `default:"go" flag:"lang" info:"Language of the bindings, go or ts"`
+	Out             string   `default:"" flag:"out" info:"File to write the bindings to, defaults to bindings/bindings.go or bindings/bindings.ts"`
+	Package         string   `default:"bindings" flag:"package" info:"Package name of the Go bindings"`
+	ExcludeNetworks []string `default:"" flag:"exclude-networks" info:"Networks to not generate bindings for"`
+}
+
+var generateBindingsFlags = generateBindingsFlagsDef{}
+
 var GenerateCommand = &cobra.Command{
 	Use:     "generate",
 	Short:   "Generate template files for common Cadence code",
@@ -89,11 +102,23 @@ var GenerateTestCommand = &command.Command{
 	RunS:  generateTest,
 }
 
+var GenerateBindingsCommand = &command.Command{
+	Cmd: &cobra.Command{
+		Use:     "bindings [<path>...]",
+		Short:   "Generate typed Go or TypeScript bindings for the scripts and transactions of the project",
+		Example: "flow generate bindings --lang ts --out src/bindings.ts",
+		Args:    cobra.ArbitraryArgs,
+	},
+	Flags: &generateBindingsFlags,
+	RunS:  generateBindings,
+}
+
 func init() {
 	GenerateContractCommand.AddToParent(GenerateCommand)
 	GenerateTransactionCommand.AddToParent(GenerateCommand)
 	GenerateScriptCommand.AddToParent(GenerateCommand)
 	GenerateTestCommand.AddToParent(GenerateCommand)
+	GenerateBindingsCommand.AddToParent(GenerateCommand)
 }
 
 func generateContract(
@@ -148,3 +173,82 @@ func generateTest(
 	err = g.Create(generator.TestTemplate{Name: nameWithoutSuffix})
 	return nil, err
 }
+
+func generateBindings(
+	args []string,
+	_ command.GlobalFlags,
+	logger output.Logger,
+	_ flowkit.Services,
+	state *flowkit.State,
+) (result command.Result, err error) {
+	paths := args
+	if len(paths) == 0 {
+		paths = []string{generator.DefaultCadenceDirectory}
+	}
+
+	sources, err := readBindingSources(state, paths)
+	if err != nil {
+		return nil, err
+	}
+
+	contracts := make(map[string]map[string]string)
+	for name, addresses := range getContractsFromState(state, generateBindingsFlags.ExcludeNetworks) {
+		contracts[name] = addresses
+	}
+
+	var networks []string
+	for _, network := range *state.Networks() {
+		if !slices.Contains(generateBindingsFlags.ExcludeNetworks, network.Name) {
+			networks = append(networks, network.Name)
+		}
+	}
+
+	template, warnings, err := generator.NewBindingsTemplate(
+		generateBindingsFlags.Lang,
+		generateBindingsFlags.Package,
+		generateBindingsFlags.Out,
+		sources,
+		contracts,
+		networks,
+	)
+	if err != nil {
+		return nil, err
+	}
+	for _, warning := range warnings {
+		logger.Info(fmt.Sprintf("%s %s", output.WarningEmoji(), warning))
+	}
+	if len(template.Bindings) == 0 {
+		return nil, fmt.Errorf("no scripts or transactions found in %s", strings.Join(paths, ", "))
+	}
+
+	g := generator.NewGenerator("", state, logger, false, false)
+	err = g.Create(template)
+	return nil, err
+}
+
+// readBindingSources reads the Cadence files at the paths, where directories are read recursively.
+// Test files are skipped, as they are neither scripts nor transactions.
+func readBindingSources(state *flowkit.State, paths []string) ([]generator.BindingSource, error) {
+	var sources []generator.BindingSource
+	for _, root := range paths {
+		err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if info.IsDir() || filepath.Ext(path) != ".cdc" || strings.HasSuffix(path, "_test.cdc") {
+				return nil
+			}
+
+			code, err := state.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			sources = append(sources, generator.BindingSource{Path: path, Code: code})
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to read Cadence files: %w", err)
+		}
+	}
+	return sources, nil
+}
@@ -0,0 +1,382 @@
+/*
+ * Flow CLI
+ *
+ * Copyright Flow Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package generator
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/onflow/cadence/ast"
+	"github.com/onflow/cadence/common"
+	"github.com/onflow/cadence/parser"
+)
+
+const (
+	BindingsLangGo = "go"
+	BindingsLangTS = "ts"
+
+	DefaultBindingsDirectory = "bindings"
+	DefaultBindingsPackage   = "bindings"
+)
+
+// BindingSource is a script or transaction of the project.
+type BindingSource struct {
+	Path string
+	Code []byte
+}
+
+// BindingsTemplate generates typed wrapper functions for the scripts and transactions of the project,
+// using flow-go-sdk for Go and FCL for TypeScript.
+type BindingsTemplate struct {
+	Lang       string
+	Package    string
+	TargetPath string
+	Networks   []BindingNetwork
+	Bindings   []Binding
+}
+
+type BindingNetwork struct {
+	Name string
+	// Const is the name of the Go constant of the network
+	Const string
+}
+
+// Binding is the wrapper function of a script or transaction.
+type Binding struct {
+	Name        string
+	CodeName    string
+	SourcePath  string
+	Transaction bool
+	// Authorizers is the number of accounts that must authorize the transaction
+	Authorizers int
+	// Codes holds the code for each network on which all imports are resolved
+	Codes  []BindingCode
+	Params []BindingParam
+	// Result is the type of the result of the script, empty if it returns nothing
+	Result string
+	// ResultConversion converts the result of the script to its type (Go only)
+	ResultConversion string
+}
+
+type BindingCode struct {
+	Network string
+	Const   string
+	Code    string
+}
+
+type BindingParam struct {
+	Name string
+	Type string
+	// Argument is the expression that converts the parameter to a Cadence argument
+	Argument string
+}
+
+var _ TemplateItemWithOverwrite = BindingsTemplate{}
+
+// NewBindingsTemplate returns the bindings of the scripts and transactions among the sources,
+// where the imports of each are resolved to the addresses of the contracts on each network.
+//
+// Sources which are not scripts or transactions are ignored. Sources which can not be bound,
+// e.g. because they can not be parsed, are skipped and returned as warnings.
+func NewBindingsTemplate(
+	lang string,
+	pkg string,
+	targetPath string,
+	sources []BindingSource,
+	contracts map[string]map[string]string,
+	networks []string,
+) (BindingsTemplate, []string, error) {
+	var mapper bindingTypeMapper
+	switch lang {
+	case BindingsLangGo:
+		mapper = goTypeMapper{}
+		if pkg == "" {
+			pkg = DefaultBindingsPackage
+		}
+		if targetPath == "" {
+			targetPath = filepath.Join(DefaultBindingsDirectory, "bindings.go")
+		}
+	case BindingsLangTS:
+		mapper = tsTypeMapper{}
+		if targetPath == "" {
+			targetPath = filepath.Join(DefaultBindingsDirectory, "bindings.ts")
+		}
+	default:
+		return BindingsTemplate{}, nil, fmt.Errorf("unsupported language %q, expected %q or %q", lang, BindingsLangGo, BindingsLangTS)
+	}
+
+	template := BindingsTemplate{
+		Lang:       lang,
+		Package:    pkg,
+		TargetPath: targetPath,
+	}
+	for _, network := range networks {
+		template.Networks = append(template.Networks, BindingNetwork{
+			Name:  network,
+			Const: "Network" + pascalCase(network),
+		})
+	}
+
+	sources = append([]BindingSource(nil), sources...)
+	sort.Slice(sources, func(i, j int) bool {
+		return sources[i].Path < sources[j].Path
+	})
+
+	var warnings []string
+	names := make(map[string]string)
+	for _, source := range sources {
+		binding, ok, err := newBinding(source, mapper, contracts, template.Networks)
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("skipped %s: %s", source.Path, err))
+			continue
+		}
+		if !ok {
+			continue
+		}
+
+		if other, exists := names[binding.Name]; exists {
+			warnings = append(warnings, fmt.Sprintf("skipped %s: %s has the same name as %s", source.Path, binding.Name, other))
+			continue
+		}
+		names[binding.Name] = source.Path
+
+		template.Bindings = append(template.Bindings, binding)
+	}
+
+	return template, warnings, nil
+}
+
+// newBinding returns the binding of the source, or false if it is not a script or transaction.
+func newBinding(
+	source BindingSource,
+	mapper bindingTypeMapper,
+	contracts map[string]map[string]string,
+	networks []BindingNetwork,
+) (Binding, bool, error) {
+	program, err := parser.ParseProgram(nil, source.Code, parser.Config{})
+	if err != nil {
+		return Binding{}, false, fmt.Errorf("invalid Cadence code")
+	}
+
+	baseName := pascalCase(strings.TrimSuffix(filepath.Base(source.Path), filepath.Ext(source.Path)))
+	binding := Binding{
+		CodeName:   mapper.localName(baseName) + "Code",
+		SourcePath: filepath.ToSlash(source.Path),
+	}
+
+	var parameters []*ast.Parameter
+	if transaction := program.SoleTransactionDeclaration(); transaction != nil {
+		binding.Transaction = true
+		binding.Name = mapper.transactionName(baseName)
+		if transaction.ParameterList != nil {
+			parameters = transaction.ParameterList.Parameters
+		}
+		if transaction.Prepare != nil && transaction.Prepare.FunctionDeclaration.ParameterList != nil {
+			binding.Authorizers = len(transaction.Prepare.FunctionDeclaration.ParameterList.Parameters)
+		}
+	} else {
+		var main *ast.FunctionDeclaration
+		for _, function := range program.FunctionDeclarations() {
+			if function.Identifier.Identifier == "main" {
+				main = function
+			}
+		}
+		if main == nil {
+			return Binding{}, false, nil
+		}
+
+		binding.Name = mapper.scriptName(baseName)
+		if main.ParameterList != nil {
+			parameters = main.ParameterList.Parameters
+		}
+		if main.ReturnTypeAnnotation != nil && main.ReturnTypeAnnotation.Type != nil {
+			resultType := newCadenceType(main.ReturnTypeAnnotation.Type)
+			if resultType.kind != nominalType || resultType.name != "Void" {
+				binding.Result, binding.ResultConversion = mapper.result(resultType)
+			}
+		}
+	}
+
+	for _, parameter := range parameters {
+		param, err := mapper.param(parameter.Identifier.Identifier, newCadenceType(parameter.TypeAnnotation.Type))
+		if err != nil {
+			return Binding{}, false, err
+		}
+		binding.Params = append(binding.Params, param)
+	}
+
+	var unresolved []string
+	for _, network := range networks {
+		code, missing := resolveImports(program, source.Code, contracts, network.Name)
+		if len(missing) > 0 {
+			unresolved = append(unresolved, missing...)
+			continue
+		}
+		binding.Codes = append(binding.Codes, BindingCode{
+			Network: network.Name,
+			Const:   network.Const,
+			Code:    code,
+		})
+	}
+	if len(binding.Codes) == 0 && len(networks) > 0 {
+		return Binding{}, false, fmt.Errorf("imports can not be resolved on any network: %s", strings.Join(unique(unresolved), ", "))
+	}
+
+	return binding, true, nil
+}
+
+// resolveImports replaces the string and file imports of the program with imports from the address
+// of the contract on the network. It returns the names of the contracts without an address instead.
+func resolveImports(
+	program *ast.Program,
+	code []byte,
+	contracts map[string]map[string]string,
+	network string,
+) (string, []string) {
+	imports := program.ImportDeclarations()
+	var missing []string
+
+	// Replace from the end, so the offsets of the remaining imports stay valid
+	resolved := string(code)
+	for i := len(imports) - 1; i >= 0; i-- {
+		declaration := imports[i]
+		location, ok := declaration.Location.(common.StringLocation)
+		if !ok {
+			continue
+		}
+
+		names := make([]string, 0, len(declaration.Imports))
+		for _, imported := range declaration.Imports {
+			names = append(names, imported.Identifier.Identifier)
+		}
+		if len(names) == 0 {
+			names = append(names, string(location))
+		}
+
+		replacements := make([]string, 0, len(names))
+		for _, name := range names {
+			address, ok := contracts[name][network]
+			if !ok {
+				missing = append(missing, name)
+				continue
+			}
+			replacements = append(replacements, fmt.Sprintf("import %s from %s", name, address))
+		}
+
+		start := declaration.StartPosition().Offset
+		end := declaration.EndPosition(nil).Offset + 1
+		resolved = resolved[:start] + strings.Join(replacements, "\n") + resolved[end:]
+	}
+
+	return resolved, missing
+}
+
+func (b BindingsTemplate) GetType() string {
+	return "bindings"
+}
+
+func (b BindingsTemplate) GetTemplatePath() string {
+	return fmt.Sprintf("bindings_%s.tmpl", b.Lang)
+}
+
+func (b BindingsTemplate) GetData() map[string]any {
+	usesFlow, hasScripts := false, false
+	for _, binding := range b.Bindings {
+		hasScripts = hasScripts || !binding.Transaction
+		if binding.Transaction || strings.Contains(binding.Result, "flow.") {
+			usesFlow = true
+		}
+		for _, param := range binding.Params {
+			if strings.Contains(param.Type, "flow.") {
+				usesFlow = true
+			}
+		}
+	}
+
+	return map[string]any{
+		"Package":    b.Package,
+		"Networks":   b.Networks,
+		"Bindings":   b.Bindings,
+		"UsesFlow":   usesFlow,
+		"HasScripts": hasScripts,
+	}
+}
+
+func (b BindingsTemplate) GetTargetPath() string {
+	return b.TargetPath
+}
+
+// Overwrite is true, as bindings are regenerated whenever the code changes.
+func (b BindingsTemplate) Overwrite() bool {
+	return true
+}
+
+// pascalCase converts a name like "get_balance" or "mainnet-fork" to "GetBalance" and "MainnetFork".
+func pascalCase(name string) string {
+	var sb strings.Builder
+	for _, part := range strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		runes := []rune(part)
+		sb.WriteRune(unicode.ToUpper(runes[0]))
+		sb.WriteString(string(runes[1:]))
+	}
+
+	result := sb.String()
+	if result == "" || unicode.IsDigit([]rune(result)[0]) {
+		result = "Binding" + result
+	}
+	return result
+}
+
+// camelCase converts a Pascal case name to camel case, e.g. "GetNFTIDs" to "getNFTIDs"
+// and "NFTBalance" to "nftBalance".
+func camelCase(name string) string {
+	runes := []rune(name)
+	upper := 0
+	for upper < len(runes) && unicode.IsUpper(runes[upper]) {
+		upper++
+	}
+
+	// Keep the last upper case letter of an acronym followed by a word, e.g. "NFTBalance"
+	if upper > 1 && upper < len(runes) {
+		upper--
+	}
+	for i := 0; i < upper; i++ {
+		runes[i] = unicode.ToLower(runes[i])
+	}
+	return string(runes)
+}
+
+func unique(values []string) []string {
+	seen := make(map[string]struct{})
+	result := make([]string, 0, len(values))
+	for _, value := range values {
+		if _, ok := seen[value]; ok {
+			continue
+		}
+		seen[value] = struct{}{}
+		result = append(result, value)
+	}
+	sort.Strings(result)
+	return result
+}
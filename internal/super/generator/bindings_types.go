@@ -0,0 +1,383 @@
+/*
+ * Flow CLI
+ *
+ * Copyright Flow Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/onflow/cadence/ast"
+)
+
+type cadenceTypeKind int
+
+const (
+	nominalType cadenceTypeKind = iota
+	arrayType
+	optionalType
+	dictionaryType
+	otherType
+)
+
+// cadenceType is the part of a Cadence type annotation which is mapped to the types of the bindings.
+type cadenceType struct {
+	kind cadenceTypeKind
+	// name is the name of nominal types and the source of other types
+	name    string
+	element *cadenceType
+	key     *cadenceType
+}
+
+func newCadenceType(t ast.Type) *cadenceType {
+	switch t := t.(type) {
+	case *ast.NominalType:
+		return &cadenceType{kind: nominalType, name: t.String()}
+	case *ast.OptionalType:
+		return &cadenceType{kind: optionalType, element: newCadenceType(t.Type)}
+	case *ast.VariableSizedType:
+		return &cadenceType{kind: arrayType, element: newCadenceType(t.Type)}
+	case *ast.ConstantSizedType:
+		return &cadenceType{kind: arrayType, element: newCadenceType(t.Type)}
+	case *ast.DictionaryType:
+		return &cadenceType{kind: dictionaryType, key: newCadenceType(t.KeyType), element: newCadenceType(t.ValueType)}
+	default:
+		return &cadenceType{kind: otherType, name: t.String()}
+	}
+}
+
+// bindingTypeMapper maps names and Cadence types to the language of the bindings.
+type bindingTypeMapper interface {
+	scriptName(name string) string
+	transactionName(name string) string
+	localName(name string) string
+	param(name string, t *cadenceType) (BindingParam, error)
+	result(t *cadenceType) (string, string)
+}
+
+// goNativeTypes are the Cadence types which are bound to Go types,
+// all other numeric types are bound to their cadence-go types.
+var goNativeTypes = map[string]string{
+	"String":    "string",
+	"Character": "string",
+	"Bool":      "bool",
+	"Address":   "flow.Address",
+	"Int8":      "int8",
+	"Int16":     "int16",
+	"Int32":     "int32",
+	"Int64":     "int64",
+	"UInt8":     "uint8",
+	"UInt16":    "uint16",
+	"UInt32":    "uint32",
+	"UInt64":    "uint64",
+	"Word8":     "uint8",
+	"Word16":    "uint16",
+	"Word32":    "uint32",
+	"Word64":    "uint64",
+}
+
+var goCadenceTypes = map[string]bool{
+	"Int":     true,
+	"UInt":    true,
+	"Int128":  true,
+	"Int256":  true,
+	"UInt128": true,
+	"UInt256": true,
+	"Word128": true,
+	"Word256": true,
+	"Fix64":   true,
+	"UFix64":  true,
+}
+
+// goReservedNames are Go keywords and the names used in the bodies of the bindings
+var goReservedNames = map[string]bool{
+	"break": true, "case": true, "chan": true, "const": true, "continue": true, "default": true,
+	"defer": true, "else": true, "fallthrough": true, "for": true, "func": true, "go": true,
+	"goto": true, "if": true, "import": true, "interface": true, "map": true, "package": true,
+	"range": true, "return": true, "select": true, "struct": true, "switch": true, "type": true,
+	"var": true, "ctx": true, "client": true, "network": true, "code": true, "err": true,
+	"tx": true, "value": true, "result": true, "argument": true, "cadence": true, "flow": true,
+	"access": true, "fmt": true, "context": true,
+}
+
+type goTypeMapper struct{}
+
+func (goTypeMapper) scriptName(name string) string {
+	return name
+}
+
+func (goTypeMapper) transactionName(name string) string {
+	return "New" + name + "Transaction"
+}
+
+func (goTypeMapper) localName(name string) string {
+	return camelCase(name)
+}
+
+func (m goTypeMapper) param(name string, t *cadenceType) (BindingParam, error) {
+	if goReservedNames[name] {
+		name += "Arg"
+	}
+	return BindingParam{
+		Name:     name,
+		Type:     m.goType(t),
+		Argument: m.toCadence(t, name),
+	}, nil
+}
+
+func (m goTypeMapper) result(t *cadenceType) (string, string) {
+	return m.goType(t), m.fromCadence(t)
+}
+
+func (m goTypeMapper) goType(t *cadenceType) string {
+	switch t.kind {
+	case nominalType:
+		if native, ok := goNativeTypes[t.name]; ok {
+			return native
+		}
+		if goCadenceTypes[t.name] {
+			return "cadence." + t.name
+		}
+	case arrayType:
+		return "[]" + m.goType(t.element)
+	case optionalType:
+		return "*" + m.goType(t.element)
+	case dictionaryType:
+		return "cadence.Dictionary"
+	}
+	return "cadence.Value"
+}
+
+// toCadence returns the expression converting the Go value to a Cadence value.
+func (m goTypeMapper) toCadence(t *cadenceType, value string) string {
+	switch t.kind {
+	case nominalType:
+		if _, ok := goNativeTypes[t.name]; ok {
+			return fmt.Sprintf("cadence.%s(%s)", t.name, value)
+		}
+	case arrayType:
+		return fmt.Sprintf("arrayToCadence(%s, %s)", value, m.toCadenceFunc(t.element))
+	case optionalType:
+		return fmt.Sprintf("optionalToCadence(%s, %s)", value, m.toCadenceFunc(t.element))
+	}
+	return value
+}
+
+func (m goTypeMapper) toCadenceFunc(t *cadenceType) string {
+	return fmt.Sprintf("func(value %s) cadence.Value { return %s }", m.goType(t), m.toCadence(t, "value"))
+}
+
+// fromCadence returns the function converting a Cadence value to the Go value.
+func (m goTypeMapper) fromCadence(t *cadenceType) string {
+	switch t.kind {
+	case nominalType:
+		if native, ok := goNativeTypes[t.name]; ok {
+			return fmt.Sprintf("fromCadence(func(value cadence.%s) %s { return %s(value) })", t.name, native, native)
+		}
+	case arrayType:
+		return fmt.Sprintf("arrayFromCadence(%s)", m.fromCadence(t.element))
+	case optionalType:
+		return fmt.Sprintf("optionalFromCadence(%s)", m.fromCadence(t.element))
+	}
+	return fmt.Sprintf("asCadence[%s]", m.goType(t))
+}
+
+// tsNumberTypes are the Cadence types which FCL decodes to numbers,
+// all other numeric types are decoded to strings.
+var tsNumberTypes = map[string]bool{
+	"Int8": true, "Int16": true, "Int32": true, "Int64": true,
+	"UInt8": true, "UInt16": true, "UInt32": true, "UInt64": true,
+	"Word8": true, "Word16": true, "Word32": true, "Word64": true,
+}
+
+var tsStringTypes = map[string]bool{
+	"Int": true, "UInt": true, "Int128": true, "Int256": true, "UInt128": true, "UInt256": true,
+	"Word128": true, "Word256": true, "Fix64": true, "UFix64": true,
+	"String": true, "Character": true, "Address": true,
+}
+
+// tsReservedNames are TypeScript reserved words and the names used in the bodies of the bindings
+var tsReservedNames = map[string]bool{
+	"break": true, "case": true, "catch": true, "class": true, "const": true, "continue": true,
+	"debugger": true, "default": true, "delete": true, "do": true, "else": true, "enum": true,
+	"export": true, "extends": true, "false": true, "finally": true, "for": true, "function": true,
+	"if": true, "import": true, "in": true, "instanceof": true, "new": true, "null": true,
+	"return": true, "super": true, "switch": true, "this": true, "throw": true, "true": true,
+	"try": true, "typeof": true, "var": true, "void": true, "while": true, "with": true,
+	"let": true, "static": true, "yield": true, "await": true, "network": true, "options": true,
+	"arg": true, "t": true, "fcl": true,
+}
+
+var tsPathTypes = map[string]bool{
+	"Path": true, "StoragePath": true, "PublicPath": true, "PrivatePath": true, "CapabilityPath": true,
+}
+
+const tsPathType = "{ domain: string; identifier: string }"
+
+type tsTypeMapper struct{}
+
+func (m tsTypeMapper) scriptName(name string) string {
+	return m.localName(name)
+}
+
+func (m tsTypeMapper) transactionName(name string) string {
+	return m.localName(name)
+}
+
+func (tsTypeMapper) localName(name string) string {
+	name = camelCase(name)
+	if tsReservedNames[name] {
+		name += "Binding"
+	}
+	return name
+}
+
+func (m tsTypeMapper) param(name string, t *cadenceType) (BindingParam, error) {
+	tsType, err := m.argumentType(t)
+	if err != nil {
+		return BindingParam{}, fmt.Errorf("parameter %s: %w", name, err)
+	}
+	fclType, err := m.fclType(t)
+	if err != nil {
+		return BindingParam{}, fmt.Errorf("parameter %s: %w", name, err)
+	}
+
+	if tsReservedNames[name] {
+		name += "Arg"
+	}
+	return BindingParam{
+		Name:     name,
+		Type:     tsType,
+		Argument: fmt.Sprintf("arg(%s, %s)", name, fclType),
+	}, nil
+}
+
+func (m tsTypeMapper) result(t *cadenceType) (string, string) {
+	return m.resultType(t), ""
+}
+
+func (m tsTypeMapper) argumentType(t *cadenceType) (string, error) {
+	switch t.kind {
+	case nominalType:
+		switch {
+		case tsNumberTypes[t.name]:
+			return "number", nil
+		case tsStringTypes[t.name]:
+			return "string", nil
+		case t.name == "Bool":
+			return "boolean", nil
+		case tsPathTypes[t.name]:
+			return tsPathType, nil
+		}
+	case arrayType:
+		element, err := m.argumentType(t.element)
+		if err != nil {
+			return "", err
+		}
+		return tsArray(element), nil
+	case optionalType:
+		element, err := m.argumentType(t.element)
+		if err != nil {
+			return "", err
+		}
+		return element + " | null", nil
+	case dictionaryType:
+		key, err := m.argumentType(t.key)
+		if err != nil {
+			return "", err
+		}
+		value, err := m.argumentType(t.element)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("{ key: %s; value: %s }[]", key, value), nil
+	}
+	return "", fmt.Errorf("type %s is not supported as an argument", t.name)
+}
+
+func (m tsTypeMapper) fclType(t *cadenceType) (string, error) {
+	switch t.kind {
+	case nominalType:
+		if tsNumberTypes[t.name] || tsStringTypes[t.name] || t.name == "Bool" {
+			return "t." + t.name, nil
+		}
+		if tsPathTypes[t.name] {
+			return "t.Path", nil
+		}
+	case arrayType:
+		element, err := m.fclType(t.element)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("t.Array(%s)", element), nil
+	case optionalType:
+		element, err := m.fclType(t.element)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("t.Optional(%s)", element), nil
+	case dictionaryType:
+		key, err := m.fclType(t.key)
+		if err != nil {
+			return "", err
+		}
+		value, err := m.fclType(t.element)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("t.Dictionary({ key: %s, value: %s })", key, value), nil
+	}
+	return "", fmt.Errorf("type %s is not supported as an argument", t.name)
+}
+
+// resultType returns the type of the value decoded by FCL.
+func (m tsTypeMapper) resultType(t *cadenceType) string {
+	switch t.kind {
+	case nominalType:
+		switch {
+		case tsNumberTypes[t.name]:
+			return "number"
+		case tsStringTypes[t.name]:
+			return "string"
+		case t.name == "Bool":
+			return "boolean"
+		case t.name == "Void":
+			return "null"
+		case tsPathTypes[t.name]:
+			return tsPathType
+		}
+	case arrayType:
+		return tsArray(m.resultType(t.element))
+	case optionalType:
+		element := m.resultType(t.element)
+		if element == "unknown" || strings.HasSuffix(element, " | null") {
+			return element
+		}
+		return element + " | null"
+	case dictionaryType:
+		return fmt.Sprintf("Record<string, %s>", m.resultType(t.element))
+	}
+	return "unknown"
+}
+
+func tsArray(element string) string {
+	if strings.Contains(element, "|") {
+		return "(" + element + ")[]"
+	}
+	return element + "[]"
+}
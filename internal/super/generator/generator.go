@@ -22,8 +22,11 @@ import (
 	"bytes"
 	"embed"
 	"fmt"
+	"go/format"
 	"maps"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"text/template"
 
 	"github.com/onflow/flowkit/v2"
@@ -38,6 +41,28 @@ const (
 //go:embed templates/*.tmpl templates/cursor/*.tmpl
 var templatesFS embed.FS
 
+// templateFuncs are the functions available to templates
+var templateFuncs = template.FuncMap{
+	"goString": goString,
+	"tsString": tsString,
+}
+
+// goString returns the value as a Go raw string literal, or as an interpreted one if it contains a backtick
+func goString(value string) string {
+	if strings.Contains(value, "`") {
+		return strconv.Quote(value)
+	}
+	return "`" + value + "`"
+}
+
+// tsString returns the value as a TypeScript template literal
+func tsString(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, "`", "\\`")
+	value = strings.ReplaceAll(value, "${", "\\${")
+	return "`" + value + "`"
+}
+
 // TemplateItem is an interface for different template types
 type TemplateItem interface {
 	GetType() string
@@ -58,6 +83,12 @@ type TemplateItemWithChildren interface {
 	GetChildren() []TemplateItem
 }
 
+// TemplateItemWithOverwrite is an interface for template items whose target file is regenerated if it exists
+type TemplateItemWithOverwrite interface {
+	TemplateItem
+	Overwrite() bool
+}
+
 type Generator struct {
 	directory   string
 	state       *flowkit.State
@@ -115,11 +146,22 @@ func (g *Generator) generate(item TemplateItem) error {
 		return fmt.Errorf("error generating %s template: %w", item.GetType(), err)
 	}
 
+	// Format generated Go code, so templates do not need to align it
+	if filepath.Ext(targetRelativeToRoot) == ".go" {
+		formatted, err := format.Source([]byte(outputContent))
+		if err != nil {
+			return fmt.Errorf("error formatting %s: %w", item.GetType(), err)
+		}
+		outputContent = string(formatted)
+	}
+
 	targetPath := filepath.Join(rootDir, targetRelativeToRoot)
 	targetDirectory := filepath.Dir(targetPath)
 
 	// Check file existence
-	if _, err := g.state.ReaderWriter().ReadFile(targetPath); err == nil {
+	itemWithOverwrite, overwrite := item.(TemplateItemWithOverwrite)
+	overwrite = overwrite && itemWithOverwrite.Overwrite()
+	if _, err := g.state.ReaderWriter().ReadFile(targetPath); err == nil && !overwrite {
 		return fmt.Errorf("file already exists: %s", targetPath)
 	}
 
@@ -157,7 +199,7 @@ func (g *Generator) processTemplate(templatePath string, data map[string]any) (s
 		return "", fmt.Errorf("failed to read template file: %w", err)
 	}
 
-	tmpl, err := template.New("template").Parse(string(templateData))
+	tmpl, err := template.New("template").Funcs(templateFuncs).Parse(string(templateData))
 	if err != nil {
 		return "", fmt.Errorf("failed to parse template: %w", err)
 	}
@@ -296,3 +296,94 @@ func TestGenerateReadmeWithDeps(t *testing.T) {
 	readmeWithDepsFixture, _ := fixturesFS.ReadFile("fixtures/README_with_deps.md")
 	assert.Equal(t, string(readmeWithDepsFixture), string(content))
 }
+
+var bindingSources = []BindingSource{
+	{
+		Path: "cadence/scripts/GetCounter.cdc",
+		Code: []byte("import \"Counter\"\n\naccess(all) fun main(account: Address, ids: [UInt64]): Int? {\n    return Counter.count\n}\n"),
+	},
+	{
+		Path: "cadence/transactions/IncrementCounter.cdc",
+		Code: []byte("import Counter from \"../contracts/Counter.cdc\"\n\ntransaction(by: UFix64, label: String?) {\n    prepare(signer: &Account) {}\n\n    execute {\n        Counter.increment()\n    }\n}\n"),
+	},
+	{
+		Path: "cadence/contracts/Counter.cdc",
+		Code: []byte("access(all) contract Counter {}\n"),
+	},
+	{
+		Path: "cadence/scripts/GetToken.cdc",
+		Code: []byte("import \"Token\"\n\naccess(all) fun main(): UFix64 {\n    return Token.totalSupply\n}\n"),
+	},
+}
+
+var bindingContracts = map[string]map[string]string{
+	"Counter": {"emulator": "0xf8d6e0586b0a20c7", "testnet": "0x8c5303eaa26202d6"},
+}
+
+func TestGenerateBindingsGo(t *testing.T) {
+	logger := output.NewStdoutLogger(output.NoneLog)
+	_, state, _ := util.TestMocks(t)
+
+	template, warnings, err := NewBindingsTemplate(BindingsLangGo, "", "", bindingSources, bindingContracts, []string{"emulator", "testnet", "mainnet"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"skipped cadence/scripts/GetToken.cdc: imports can not be resolved on any network: Token"}, warnings)
+
+	g := NewGenerator("", state, logger, false, false)
+	err = g.Create(template)
+	assert.NoError(t, err, "Failed to generate bindings")
+
+	fileContent, err := state.ReaderWriter().ReadFile(filepath.FromSlash("bindings/bindings.go"))
+	assert.NoError(t, err, "Failed to read generated file")
+
+	bindingsFixture, _ := fixturesFS.ReadFile("fixtures/bindings_go.golden")
+	assert.Equal(t, string(bindingsFixture), util.NormalizeLineEndings(string(fileContent)))
+
+	// Bindings are regenerated
+	err = g.Create(template)
+	assert.NoError(t, err)
+}
+
+func TestGenerateBindingsTS(t *testing.T) {
+	logger := output.NewStdoutLogger(output.NoneLog)
+	_, state, _ := util.TestMocks(t)
+
+	template, _, err := NewBindingsTemplate(BindingsLangTS, "", "web/bindings.ts", bindingSources, bindingContracts, []string{"emulator", "testnet", "mainnet"})
+	assert.NoError(t, err)
+
+	g := NewGenerator("", state, logger, false, false)
+	err = g.Create(template)
+	assert.NoError(t, err, "Failed to generate bindings")
+
+	fileContent, err := state.ReaderWriter().ReadFile(filepath.FromSlash("web/bindings.ts"))
+	assert.NoError(t, err, "Failed to read generated file")
+
+	bindingsFixture, _ := fixturesFS.ReadFile("fixtures/bindings_ts.golden")
+	assert.Equal(t, string(bindingsFixture), util.NormalizeLineEndings(string(fileContent)))
+}
+
+func TestGenerateBindingsUnsupported(t *testing.T) {
+	_, _, err := NewBindingsTemplate("rust", "", "", bindingSources, bindingContracts, nil)
+	assert.EqualError(t, err, `unsupported language "rust", expected "go" or "ts"`)
+
+	sources := []BindingSource{
+		{Path: "cadence/scripts/GetItem.cdc", Code: []byte("access(all) struct Item {}\n\naccess(all) fun main(item: Item): Item {\n    return item\n}\n")},
+		{Path: "cadence/scripts/get_item.cdc", Code: []byte("access(all) fun main(): Int {\n    return 1\n}\n")},
+		{Path: "cadence/scripts/Invalid.cdc", Code: []byte("access(all) fun main(")},
+	}
+
+	template, warnings, err := NewBindingsTemplate(BindingsLangTS, "", "", sources, nil, []string{"emulator"})
+	assert.NoError(t, err)
+	assert.Len(t, template.Bindings, 1)
+	assert.Equal(t, []string{
+		"skipped cadence/scripts/GetItem.cdc: parameter item: type Item is not supported as an argument",
+		"skipped cadence/scripts/Invalid.cdc: invalid Cadence code",
+	}, warnings)
+
+	template, warnings, err = NewBindingsTemplate(BindingsLangGo, "", "", sources, nil, []string{"emulator"})
+	assert.NoError(t, err)
+	assert.Len(t, template.Bindings, 1)
+	assert.Equal(t, []string{
+		"skipped cadence/scripts/Invalid.cdc: invalid Cadence code",
+		"skipped cadence/scripts/get_item.cdc: GetItem has the same name as cadence/scripts/GetItem.cdc",
+	}, warnings)
+}
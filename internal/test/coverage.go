@@ -0,0 +1,274 @@
+/*
+ * Flow CLI
+ *
+ * Copyright Flow Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package test
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/onflow/cadence/common"
+	"github.com/onflow/cadence/runtime"
+
+	"github.com/onflow/flowkit/v2"
+)
+
+// newCoverageReport creates an empty coverage report for the given network,
+// restricted to contracts when coverCode equals "contracts".
+func newCoverageReport(state *flowkit.State, network string, coverCode string) *runtime.CoverageReport {
+	coverageReport := state.CreateCoverageReport(network)
+	if coverCode == contractsCoverCode {
+		coverageReport.WithLocationFilter(
+			func(location common.Location) bool {
+				_, addressLoc := location.(common.AddressLocation)
+				// We only allow inspection of AddressLocation,
+				// since scripts and transactions cannot be
+				// attributed to their source files anyway.
+				return addressLoc
+			},
+		)
+	}
+
+	return coverageReport
+}
+
+// mergeCoverageReports adds the coverage collected in each of the given reports to dst.
+//
+// Unlike runtime.CoverageReport.Merge, line hits of locations covered by
+// more than one report are summed up instead of being overwritten.
+// The reports are merged in the order of their keys, so the result
+// does not depend on the order in which test files finished.
+func mergeCoverageReports(dst *runtime.CoverageReport, reports map[string]*runtime.CoverageReport) {
+	keys := make([]string, 0, len(reports))
+	for key := range reports {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		mergeCoverageReport(dst, reports[key])
+	}
+}
+
+func mergeCoverageReport(dst *runtime.CoverageReport, src *runtime.CoverageReport) {
+	for location, srcCoverage := range src.Coverage {
+		dstCoverage, ok := dst.Coverage[location]
+		if !ok {
+			dstCoverage = &runtime.LocationCoverage{
+				LineHits: make(map[int]int, len(srcCoverage.LineHits)),
+			}
+			dst.Coverage[location] = dstCoverage
+		}
+
+		for line, hits := range srcCoverage.LineHits {
+			dstCoverage.LineHits[line] += hits
+		}
+		dstCoverage.Statements = max(dstCoverage.Statements, srcCoverage.Statements)
+	}
+	for location := range src.Locations {
+		dst.Locations[location] = struct{}{}
+	}
+	for location := range src.ExcludedLocations {
+		dst.ExcludedLocations[location] = struct{}{}
+	}
+}
+
+// coverageProfile is the JSON coverage profile written by `flow test --coverprofile *.json`.
+type coverageProfile struct {
+	Coverage map[string]struct {
+		LineHits   map[int]int `json:"line_hits"`
+		Statements int         `json:"statements"`
+	} `json:"coverage"`
+	ExcludedLocations []string `json:"excluded_locations"`
+}
+
+// loadCoverageProfile reads a JSON coverage profile written by a previous run.
+//
+// Contract coverage is keyed by the contract's source path in the profile,
+// so it is attributed back to the contract declared at that path in flow.json,
+// at the address the contract has on the given network.
+func loadCoverageProfile(state *flowkit.State, network string, path string) (*runtime.CoverageReport, error) {
+	data, err := state.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var profile coverageProfile
+	if err := json.Unmarshal(data, &profile); err != nil {
+		return nil, fmt.Errorf("invalid coverage profile %s: %w", path, err)
+	}
+
+	contractLocations := make(map[string]common.Location)
+	for _, contract := range *state.Contracts() {
+		var address common.Address
+		if alias := contract.Aliases.ByNetwork(network); alias != nil {
+			address = common.Address(alias.Address)
+		}
+		contractLocations[filepath.Clean(contract.Location)] = common.AddressLocation{
+			Address: address,
+			Name:    contract.Name,
+		}
+	}
+
+	decodeLocation := func(id string) (common.Location, error) {
+		location, _, err := common.DecodeTypeID(nil, id)
+		if err != nil {
+			return nil, err
+		}
+		if location != nil {
+			return location, nil
+		}
+
+		location, ok := contractLocations[filepath.Clean(id)]
+		if !ok {
+			return nil, fmt.Errorf("no contract with location %q in configuration", id)
+		}
+		return location, nil
+	}
+
+	report := runtime.NewCoverageReport()
+	for id, locationCoverage := range profile.Coverage {
+		location, err := decodeLocation(id)
+		if err != nil {
+			return nil, fmt.Errorf("invalid coverage profile %s: %w", path, err)
+		}
+
+		lineHits := locationCoverage.LineHits
+		if lineHits == nil {
+			lineHits = map[int]int{}
+		}
+		report.Coverage[location] = &runtime.LocationCoverage{
+			LineHits:   lineHits,
+			Statements: locationCoverage.Statements,
+		}
+		report.Locations[location] = struct{}{}
+	}
+	for _, id := range profile.ExcludedLocations {
+		location, err := decodeLocation(id)
+		if err != nil {
+			return nil, fmt.Errorf("invalid coverage profile %s: %w", path, err)
+		}
+		report.ExcludedLocations[location] = struct{}{}
+	}
+
+	return report, nil
+}
+
+// coverageThresholds holds the minimum coverage percentages given with --cover-min.
+type coverageThresholds struct {
+	global    *float64
+	contracts map[string]float64
+}
+
+// parseCoverageThresholds parses --cover-min values, which are either
+// a percentage for all covered code (e.g. "80") or a percentage
+// for a single contract (e.g. "FooContract=90").
+func parseCoverageThresholds(values []string) (coverageThresholds, error) {
+	thresholds := coverageThresholds{
+		contracts: make(map[string]float64),
+	}
+
+	for _, value := range values {
+		contractName, percentage, perContract := strings.Cut(value, "=")
+		if !perContract {
+			percentage = contractName
+		}
+
+		minimum, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(percentage), "%"), 64)
+		if err != nil || minimum < 0 || minimum > 100 {
+			return coverageThresholds{}, fmt.Errorf("invalid minimum coverage %q, expected a percentage between 0 and 100", value)
+		}
+
+		if perContract {
+			thresholds.contracts[strings.TrimSpace(contractName)] = minimum
+		} else {
+			thresholds.global = &minimum
+		}
+	}
+
+	return thresholds, nil
+}
+
+// check returns a message for every threshold the coverage report does not meet.
+func (t coverageThresholds) check(report *runtime.CoverageReport) []string {
+	var failures []string
+
+	if t.global != nil {
+		percentage := coveragePercentage(report.Hits(), report.Statements())
+		if percentage < *t.global {
+			failures = append(failures, fmt.Sprintf(
+				"Coverage of %.1f%% is below the minimum of %.1f%%",
+				percentage,
+				*t.global,
+			))
+		}
+	}
+
+	contractNames := make([]string, 0, len(t.contracts))
+	for name := range t.contracts {
+		contractNames = append(contractNames, name)
+	}
+	sort.Strings(contractNames)
+
+	for _, name := range contractNames {
+		minimum := t.contracts[name]
+
+		covered, statements, found := 0, 0, false
+		for location, locationCoverage := range report.Coverage {
+			addressLocation, ok := location.(common.AddressLocation)
+			if !ok || addressLocation.Name != name {
+				continue
+			}
+			found = true
+			covered += min(locationCoverage.CoveredLines(), locationCoverage.Statements)
+			statements += locationCoverage.Statements
+		}
+
+		if !found {
+			failures = append(failures, fmt.Sprintf(
+				"No coverage was collected for contract %s, which requires a minimum of %.1f%%",
+				name,
+				minimum,
+			))
+			continue
+		}
+
+		percentage := coveragePercentage(covered, statements)
+		if percentage < minimum {
+			failures = append(failures, fmt.Sprintf(
+				"Coverage of contract %s is %.1f%%, below the minimum of %.1f%%",
+				name,
+				percentage,
+				minimum,
+			))
+		}
+	}
+
+	return failures
+}
+
+func coveragePercentage(covered int, statements int) float64 {
+	if statements == 0 {
+		return 100
+	}
+	return min(100, 100*float64(covered)/float64(statements))
+}
@@ -0,0 +1,171 @@
+/*
+ * Flow CLI
+ *
+ * Copyright Flow Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package test
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"sort"
+	"strings"
+
+	"github.com/onflow/cadence/common"
+	"github.com/onflow/cadence/runtime"
+
+	"github.com/onflow/flowkit/v2"
+)
+
+type htmlCoverageLine struct {
+	Number int
+	Code   string
+	Hits   int
+	// Status is "covered" or "missed" for lines with statements, empty otherwise.
+	Status string
+}
+
+type htmlCoverageFile struct {
+	ID         string
+	Name       string
+	Percentage string
+	Covered    int
+	Statements int
+	// Lines is empty when the source of the location is not available,
+	// e.g. for scripts and transactions executed by the tests.
+	Lines []htmlCoverageLine
+}
+
+type htmlCoverageReport struct {
+	Percentage string
+	Files      []htmlCoverageFile
+}
+
+// renderCoverageHTML renders the coverage report as a single HTML page,
+// with the Cadence source of every covered contract annotated with its line hits.
+func renderCoverageHTML(report *runtime.CoverageReport, state *flowkit.State) ([]byte, error) {
+	contractLocations := make(map[string]string)
+	for _, contract := range *state.Contracts() {
+		contractLocations[contract.Name] = contract.Location
+	}
+
+	files := make([]htmlCoverageFile, 0, len(report.Coverage))
+	for location, locationCoverage := range report.Coverage {
+		covered := min(locationCoverage.CoveredLines(), locationCoverage.Statements)
+		file := htmlCoverageFile{
+			Name:       location.ID(),
+			Percentage: fmt.Sprintf("%.1f%%", coveragePercentage(covered, locationCoverage.Statements)),
+			Covered:    covered,
+			Statements: locationCoverage.Statements,
+		}
+
+		var sourcePath string
+		switch location := location.(type) {
+		case common.AddressLocation:
+			sourcePath = contractLocations[location.Name]
+		case common.StringLocation:
+			sourcePath = location.String()
+		}
+
+		if sourcePath != "" {
+			if code, err := state.ReadFile(sourcePath); err == nil {
+				file.Name = sourcePath
+				file.Lines = annotateCoverageLines(string(code), locationCoverage.LineHits)
+			}
+		}
+
+		files = append(files, file)
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Name < files[j].Name
+	})
+	for i := range files {
+		files[i].ID = fmt.Sprintf("file-%d", i)
+	}
+
+	var b bytes.Buffer
+	err := coverageHTMLTemplate.Execute(&b, htmlCoverageReport{
+		Percentage: report.Percentage(),
+		Files:      files,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return b.Bytes(), nil
+}
+
+func annotateCoverageLines(code string, lineHits map[int]int) []htmlCoverageLine {
+	sourceLines := strings.Split(strings.TrimSuffix(code, "\n"), "\n")
+	lines := make([]htmlCoverageLine, 0, len(sourceLines))
+
+	for i, sourceLine := range sourceLines {
+		line := htmlCoverageLine{
+			Number: i + 1,
+			Code:   sourceLine,
+		}
+		if hits, ok := lineHits[line.Number]; ok {
+			line.Hits = hits
+			line.Status = "missed"
+			if hits > 0 {
+				line.Status = "covered"
+			}
+		}
+		lines = append(lines, line)
+	}
+
+	return lines
+}
+
+var coverageHTMLTemplate = template.Must(template.New("coverage").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Cadence coverage report</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table.summary { border-collapse: collapse; margin-bottom: 2em; }
+table.summary td, table.summary th { padding: 0.25em 1em; text-align: left; border-bottom: 1px solid #ddd; }
+table.source { border-collapse: collapse; font-family: monospace; width: 100%; }
+table.source td { padding: 0 0.5em; white-space: pre; }
+td.number, td.hits { color: #888; text-align: right; width: 1%; }
+tr.covered { background: #dfd; }
+tr.missed { background: #fdd; }
+</style>
+</head>
+<body>
+<h1>Coverage: {{.Percentage}}</h1>
+<table class="summary">
+<tr><th>Location</th><th>Coverage</th><th>Statements</th></tr>
+{{- range .Files}}
+<tr><td>{{if .Lines}}<a href="#{{.ID}}">{{.Name}}</a>{{else}}{{.Name}}{{end}}</td><td>{{.Percentage}}</td><td>{{.Covered}}/{{.Statements}}</td></tr>
+{{- end}}
+</table>
+{{- range .Files}}
+{{- if .Lines}}
+<h2 id="{{.ID}}">{{.Name}} ({{.Percentage}})</h2>
+<table class="source">
+{{- range .Lines}}
+<tr{{if .Status}} class="{{.Status}}"{{end}}><td class="number">{{.Number}}</td><td class="hits">{{if .Status}}{{.Hits}}{{end}}</td><td>{{.Code}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+{{- end}}
+</body>
+</html>
+`))
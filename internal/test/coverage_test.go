@@ -0,0 +1,224 @@
+/*
+ * Flow CLI
+ *
+ * Copyright Flow Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package test
+
+import (
+	"testing"
+
+	"github.com/onflow/cadence/common"
+	"github.com/onflow/cadence/runtime"
+	flowsdk "github.com/onflow/flow-go-sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flowkit/v2/config"
+
+	"github.com/onflow/flow-cli/internal/util"
+)
+
+func TestMergeCoverageReports(t *testing.T) {
+	t.Parallel()
+
+	contract := common.AddressLocation{Address: common.Address{0, 0, 0, 0, 0, 0, 0, 7}, Name: "Foo"}
+	script := common.ScriptLocation{0x1}
+
+	first := runtime.NewCoverageReport()
+	first.Coverage[contract] = &runtime.LocationCoverage{
+		LineHits:   map[int]int{1: 1, 2: 0, 3: 2},
+		Statements: 3,
+	}
+	first.Locations[contract] = struct{}{}
+
+	second := runtime.NewCoverageReport()
+	second.Coverage[contract] = &runtime.LocationCoverage{
+		LineHits:   map[int]int{1: 1, 2: 4, 3: 0},
+		Statements: 3,
+	}
+	second.Coverage[script] = &runtime.LocationCoverage{
+		LineHits:   map[int]int{1: 1},
+		Statements: 1,
+	}
+	second.Locations[contract] = struct{}{}
+	second.Locations[script] = struct{}{}
+	second.ExcludedLocations[common.IdentifierLocation("Test")] = struct{}{}
+
+	merged := runtime.NewCoverageReport()
+	mergeCoverageReports(merged, map[string]*runtime.CoverageReport{
+		"b_test.cdc": second,
+		"a_test.cdc": first,
+	})
+
+	assert.Equal(t, map[int]int{1: 2, 2: 4, 3: 2}, merged.Coverage[contract].LineHits)
+	assert.Equal(t, 3, merged.Coverage[contract].Statements)
+	assert.Equal(t, map[int]int{1: 1}, merged.Coverage[script].LineHits)
+	assert.Equal(t, 2, merged.TotalLocations())
+	assert.Equal(t, []string{"I.Test"}, merged.ExcludedLocationIDs())
+
+	// The source reports are left untouched
+	assert.Equal(t, map[int]int{1: 1, 2: 0, 3: 2}, first.Coverage[contract].LineHits)
+}
+
+func TestLoadCoverageProfile(t *testing.T) {
+	t.Parallel()
+
+	_, state, rw := util.TestMocks(t)
+	state.Contracts().AddOrUpdate(config.Contract{
+		Name:     "FooContract",
+		Location: "contracts/FooContract.cdc",
+		Aliases: config.Aliases{{
+			Network: coverageNetwork,
+			Address: flowsdk.HexToAddress("0x0000000000000007"),
+		}},
+	})
+
+	t.Run("merges profiles of multiple shards", func(t *testing.T) {
+		t.Parallel()
+
+		require.NoError(t, rw.WriteFile("shard-1.json", []byte(`{
+			"coverage": {
+				"contracts/FooContract.cdc": {"line_hits": {"6": 1, "14": 0}, "statements": 2},
+				"s.7465737400000000000000000000000000000000000000000000000000000000": {"line_hits": {"1": 1}, "statements": 1}
+			},
+			"excluded_locations": ["I.Test"]
+		}`), 0644))
+		require.NoError(t, rw.WriteFile("shard-2.json", []byte(`{
+			"coverage": {
+				"contracts/FooContract.cdc": {"line_hits": {"6": 1, "14": 3}, "statements": 2}
+			},
+			"excluded_locations": ["I.Test", "I.Crypto"]
+		}`), 0644))
+
+		merged := newCoverageReport(state, coverageNetwork, "all")
+		for _, path := range []string{"shard-1.json", "shard-2.json"} {
+			profile, err := loadCoverageProfile(state, coverageNetwork, path)
+			require.NoError(t, err)
+			mergeCoverageReport(merged, profile)
+		}
+
+		location := common.AddressLocation{
+			Name:    "FooContract",
+			Address: common.Address{0, 0, 0, 0, 0, 0, 0, 7},
+		}
+		require.Contains(t, merged.Coverage, location)
+		assert.Equal(t, map[int]int{6: 2, 14: 3}, merged.Coverage[location].LineHits)
+		assert.Equal(t, "100.0%", merged.Coverage[location].Percentage())
+		assert.Equal(t, 2, merged.TotalLocations())
+		assert.Contains(t, merged.ExcludedLocationIDs(), "I.Crypto")
+
+		lcovReport, err := merged.MarshalLCOV()
+		require.NoError(t, err)
+		assert.Contains(t, string(lcovReport), "TN:\nSF:contracts/FooContract.cdc\nDA:6,2\nDA:14,3\n")
+	})
+
+	t.Run("fails for unknown contract locations", func(t *testing.T) {
+		t.Parallel()
+
+		require.NoError(t, rw.WriteFile("unknown.json", []byte(`{
+			"coverage": {
+				"contracts/Unknown.cdc": {"line_hits": {"1": 1}, "statements": 1}
+			}
+		}`), 0644))
+
+		_, err := loadCoverageProfile(state, coverageNetwork, "unknown.json")
+		assert.ErrorContains(t, err, `no contract with location "contracts/Unknown.cdc" in configuration`)
+	})
+}
+
+func TestCoverageThresholds(t *testing.T) {
+	t.Parallel()
+
+	report := runtime.NewCoverageReport()
+	report.Coverage[common.AddressLocation{Name: "FooContract"}] = &runtime.LocationCoverage{
+		LineHits:   map[int]int{1: 1, 2: 0, 3: 2, 4: 0},
+		Statements: 4,
+	}
+	report.Coverage[common.AddressLocation{Name: "BarContract"}] = &runtime.LocationCoverage{
+		LineHits:   map[int]int{1: 1, 2: 1},
+		Statements: 2,
+	}
+
+	t.Run("met", func(t *testing.T) {
+		t.Parallel()
+
+		thresholds, err := parseCoverageThresholds([]string{"60", "BarContract=100", "FooContract=50%"})
+		require.NoError(t, err)
+		assert.Empty(t, thresholds.check(report))
+	})
+
+	t.Run("not met", func(t *testing.T) {
+		t.Parallel()
+
+		thresholds, err := parseCoverageThresholds([]string{"80", "FooContract=75", "BazContract=10"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{
+			"Coverage of 66.7% is below the minimum of 80.0%",
+			"No coverage was collected for contract BazContract, which requires a minimum of 10.0%",
+			"Coverage of contract FooContract is 50.0%, below the minimum of 75.0%",
+		}, thresholds.check(report))
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := parseCoverageThresholds([]string{"FooContract=120"})
+		assert.ErrorContains(t, err, `invalid minimum coverage "FooContract=120"`)
+
+		_, err = parseCoverageThresholds([]string{"most"})
+		assert.ErrorContains(t, err, `invalid minimum coverage "most"`)
+	})
+}
+
+func TestRenderCoverageHTML(t *testing.T) {
+	t.Parallel()
+
+	_, state, rw := util.TestMocks(t)
+	state.Contracts().AddOrUpdate(config.Contract{
+		Name:     "FooContract",
+		Location: "contracts/FooContract.cdc",
+	})
+	require.NoError(t, rw.WriteFile("contracts/FooContract.cdc", []byte(`access(all) contract FooContract {
+    access(all) fun greet(): String {
+        return "<hello>"
+    }
+    access(all) fun unused(): Int {
+        return 1
+    }
+}
+`), 0644))
+
+	report := runtime.NewCoverageReport()
+	report.Coverage[common.AddressLocation{Name: "FooContract"}] = &runtime.LocationCoverage{
+		LineHits:   map[int]int{3: 2, 6: 0},
+		Statements: 2,
+	}
+	report.Coverage[common.ScriptLocation{0x1}] = &runtime.LocationCoverage{
+		LineHits:   map[int]int{1: 1},
+		Statements: 1,
+	}
+
+	page, err := renderCoverageHTML(report, state)
+	require.NoError(t, err)
+
+	html := string(page)
+	assert.Contains(t, html, `<a href="#file-0">contracts/FooContract.cdc</a></td><td>50.0%</td><td>1/2</td>`)
+	assert.Contains(t, html, `<td>s.0100000000000000000000000000000000000000000000000000000000000000</td><td>100.0%</td><td>1/1</td>`)
+	assert.Contains(t, html, `<tr class="covered"><td class="number">3</td><td class="hits">2</td><td>        return &#34;&lt;hello&gt;&#34;</td></tr>`)
+	assert.Contains(t, html, `<tr class="missed"><td class="number">6</td><td class="hits">0</td><td>        return 1</td></tr>`)
+	assert.Contains(t, html, `<tr><td class="number">8</td><td class="hits"></td><td>}</td></tr>`)
+}
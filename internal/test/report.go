@@ -0,0 +1,261 @@
+/*
+ * Flow CLI
+ *
+ * Copyright Flow Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Available values for flagsTests.Reporter.
+const (
+	reporterJUnit     = "junit"
+	reporterTAP       = "tap"
+	reporterJSONLines = "json-lines"
+)
+
+// defaultReporterOutputs holds the file a report is written to
+// when no --reporter-output is given.
+var defaultReporterOutputs = map[string]string{
+	reporterJUnit:     "test-report.xml",
+	reporterTAP:       "test-report.tap",
+	reporterJSONLines: "test-report.jsonl",
+}
+
+// testCaseReport is the outcome of a single test case, as written by the reporters.
+//
+// The test runner executes all test cases of a file in one go, sharing their state,
+// so test cases are not timed separately, and FileDuration is the run time of the whole file.
+type testCaseReport struct {
+	File         string  `json:"file"`
+	Name         string  `json:"name"`
+	Status       string  `json:"status"`
+	Failure      string  `json:"failure,omitempty"`
+	FileDuration float64 `json:"fileDuration"`
+	Seed         int64   `json:"seed,omitempty"`
+}
+
+func (r *result) testCases() []testCaseReport {
+	cases := make([]testCaseReport, 0)
+
+	for _, scriptPath := range r.sortedFiles() {
+		for _, testResult := range r.Results[scriptPath] {
+			testCase := testCaseReport{
+				File:         scriptPath,
+				Name:         testResult.TestName,
+				Status:       "pass",
+				FileDuration: r.Durations[scriptPath].Seconds(),
+				Seed:         r.RandomSeed,
+			}
+			if testResult.Error != nil {
+				testCase.Status = "fail"
+				testCase.Failure = testResult.Error.Error()
+			}
+			cases = append(cases, testCase)
+		}
+	}
+
+	return cases
+}
+
+func (r *result) sortedFiles() []string {
+	files := make([]string, 0, len(r.Results))
+	for scriptPath := range r.Results {
+		files = append(files, scriptPath)
+	}
+	sort.Strings(files)
+	return files
+}
+
+func validateReporter(reporter string) error {
+	if _, ok := defaultReporterOutputs[reporter]; !ok {
+		return fmt.Errorf(
+			"unsupported reporter: %q, available values are %q, %q and %q",
+			reporter,
+			reporterJUnit,
+			reporterTAP,
+			reporterJSONLines,
+		)
+	}
+	return nil
+}
+
+// renderReport renders the test results in the format of the given reporter.
+func (r *result) renderReport(reporter string) ([]byte, error) {
+	switch reporter {
+	case reporterJUnit:
+		return r.junitReport()
+	case reporterTAP:
+		return r.tapReport(), nil
+	case reporterJSONLines:
+		return r.jsonLinesReport()
+	default:
+		return nil, validateReporter(reporter)
+	}
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name       string          `xml:"name,attr"`
+	Tests      int             `xml:"tests,attr"`
+	Failures   int             `xml:"failures,attr"`
+	Time       string          `xml:"time,attr"`
+	Properties []junitProperty `xml:"properties>property,omitempty"`
+	Cases      []junitTestCase `xml:"testcase"`
+}
+
+type junitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	File      string        `xml:"file,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+func formatSeconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
+
+func (r *result) junitReport() ([]byte, error) {
+	report := junitTestSuites{}
+	var total time.Duration
+
+	for _, scriptPath := range r.sortedFiles() {
+		duration := r.Durations[scriptPath]
+		total += duration
+
+		suite := junitTestSuite{
+			Name: scriptPath,
+			Time: formatSeconds(duration),
+		}
+		if r.RandomSeed > 0 {
+			suite.Properties = []junitProperty{{Name: TestReportSeedKey, Value: fmt.Sprint(r.RandomSeed)}}
+		}
+
+		// Test cases are not timed separately, so the run time of the file is split evenly
+		// among them, which keeps the time of the suite the sum of the times of its test cases
+		var caseDuration time.Duration
+		if count := len(r.Results[scriptPath]); count > 0 {
+			caseDuration = duration / time.Duration(count)
+		}
+
+		for _, testResult := range r.Results[scriptPath] {
+			testCase := junitTestCase{
+				Name:      testResult.TestName,
+				ClassName: scriptPath,
+				File:      scriptPath,
+				Time:      formatSeconds(caseDuration),
+			}
+			if testResult.Error != nil {
+				message := testResult.Error.Error()
+				testCase.Failure = &junitFailure{
+					Message: strings.SplitN(message, "\n", 2)[0],
+					Text:    message,
+				}
+				suite.Failures++
+			}
+			suite.Cases = append(suite.Cases, testCase)
+			suite.Tests++
+		}
+
+		report.Suites = append(report.Suites, suite)
+		report.Tests += suite.Tests
+		report.Failures += suite.Failures
+	}
+	report.Time = formatSeconds(total)
+
+	data, err := xml.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte(xml.Header), append(data, '\n')...), nil
+}
+
+func (r *result) tapReport() []byte {
+	var b bytes.Buffer
+	cases := r.testCases()
+
+	b.WriteString("TAP version 13\n")
+	fmt.Fprintf(&b, "1..%d\n", len(cases))
+	if r.RandomSeed > 0 {
+		fmt.Fprintf(&b, "# seed: %d\n", r.RandomSeed)
+	}
+
+	currentFile := ""
+	for i, testCase := range cases {
+		if testCase.File != currentFile {
+			currentFile = testCase.File
+			fmt.Fprintf(&b, "# %s (%ss)\n", currentFile, formatSeconds(r.Durations[currentFile]))
+		}
+
+		status := "ok"
+		if testCase.Failure != "" {
+			status = "not ok"
+		}
+		fmt.Fprintf(&b, "%s %d - %s: %s\n", status, i+1, testCase.File, testCase.Name)
+
+		if testCase.Failure != "" {
+			b.WriteString("  ---\n")
+			b.WriteString("  message: |\n")
+			for _, line := range strings.Split(testCase.Failure, "\n") {
+				fmt.Fprintf(&b, "    %s\n", line)
+			}
+			fmt.Fprintf(&b, "  file: %s\n", testCase.File)
+			b.WriteString("  ...\n")
+		}
+	}
+
+	return b.Bytes()
+}
+
+func (r *result) jsonLinesReport() ([]byte, error) {
+	var b bytes.Buffer
+	encoder := json.NewEncoder(&b)
+
+	for _, testCase := range r.testCases() {
+		if err := encoder.Encode(testCase); err != nil {
+			return nil, err
+		}
+	}
+
+	return b.Bytes(), nil
+}
@@ -0,0 +1,134 @@
+/*
+ * Flow CLI
+ *
+ * Copyright Flow Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	cdcTests "github.com/onflow/cadence-tools/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func reportTestResult() *result {
+	return &result{
+		Results: map[string]cdcTests.Results{
+			"b_test.cdc": {
+				{TestName: "testFails", Error: errors.New("assertion failed\nexpected 1, got 2")},
+			},
+			"a_test.cdc": {
+				{TestName: "testFirst"},
+				{TestName: "testSecond"},
+			},
+		},
+		Durations: map[string]time.Duration{
+			"a_test.cdc": 1500 * time.Millisecond,
+			"b_test.cdc": 250 * time.Millisecond,
+		},
+		RandomSeed: 42,
+		exitCode:   1,
+	}
+}
+
+func TestReporters(t *testing.T) {
+	t.Parallel()
+
+	t.Run("junit", func(t *testing.T) {
+		t.Parallel()
+
+		report, err := reportTestResult().renderReport(reporterJUnit)
+		require.NoError(t, err)
+
+		expected := `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites tests="3" failures="1" time="1.750">
+  <testsuite name="a_test.cdc" tests="2" failures="0" time="1.500">
+    <properties>
+      <property name="seed" value="42"></property>
+    </properties>
+    <testcase name="testFirst" classname="a_test.cdc" file="a_test.cdc" time="0.750"></testcase>
+    <testcase name="testSecond" classname="a_test.cdc" file="a_test.cdc" time="0.750"></testcase>
+  </testsuite>
+  <testsuite name="b_test.cdc" tests="1" failures="1" time="0.250">
+    <properties>
+      <property name="seed" value="42"></property>
+    </properties>
+    <testcase name="testFails" classname="b_test.cdc" file="b_test.cdc" time="0.250">
+      <failure message="assertion failed">assertion failed&#xA;expected 1, got 2</failure>
+    </testcase>
+  </testsuite>
+</testsuites>
+`
+		assert.Equal(t, expected, string(report))
+	})
+
+	t.Run("tap", func(t *testing.T) {
+		t.Parallel()
+
+		report, err := reportTestResult().renderReport(reporterTAP)
+		require.NoError(t, err)
+
+		expected := `TAP version 13
+1..3
+# seed: 42
+# a_test.cdc (1.500s)
+ok 1 - a_test.cdc: testFirst
+ok 2 - a_test.cdc: testSecond
+# b_test.cdc (0.250s)
+not ok 3 - b_test.cdc: testFails
+  ---
+  message: |
+    assertion failed
+    expected 1, got 2
+  file: b_test.cdc
+  ...
+`
+		assert.Equal(t, expected, string(report))
+	})
+
+	t.Run("json-lines", func(t *testing.T) {
+		t.Parallel()
+
+		report, err := reportTestResult().renderReport(reporterJSONLines)
+		require.NoError(t, err)
+
+		expected := `{"file":"a_test.cdc","name":"testFirst","status":"pass","fileDuration":1.5,"seed":42}
+{"file":"a_test.cdc","name":"testSecond","status":"pass","fileDuration":1.5,"seed":42}
+{"file":"b_test.cdc","name":"testFails","status":"fail","failure":"assertion failed\nexpected 1, got 2","fileDuration":0.25,"seed":42}
+`
+		assert.Equal(t, expected, string(report))
+	})
+
+	t.Run("exit code is unchanged", func(t *testing.T) {
+		t.Parallel()
+
+		r := reportTestResult()
+		_, err := r.renderReport(reporterJUnit)
+		require.NoError(t, err)
+		assert.Equal(t, 1, r.ExitCode())
+	})
+
+	t.Run("unsupported reporter", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := reportTestResult().renderReport("html")
+		assert.ErrorContains(t, err, `unsupported reporter: "html"`)
+	})
+}
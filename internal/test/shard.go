@@ -0,0 +1,161 @@
+/*
+ * Flow CLI
+ *
+ * Copyright Flow Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/onflow/flowkit/v2"
+)
+
+// defaultFileDuration is the duration assumed for test files without a
+// recorded duration, when no test file has a recorded duration at all.
+const defaultFileDuration = 1.0
+
+// testShard is the part of the test files given with --shard, e.g. 2/4.
+type testShard struct {
+	// index of the shard, starting at 1
+	index int
+	total int
+}
+
+func parseShard(value string) (testShard, error) {
+	index, total, ok := strings.Cut(value, "/")
+	if ok {
+		shard := testShard{}
+		var indexErr, totalErr error
+		shard.index, indexErr = strconv.Atoi(strings.TrimSpace(index))
+		shard.total, totalErr = strconv.Atoi(strings.TrimSpace(total))
+		if indexErr == nil && totalErr == nil && shard.total > 0 && shard.index >= 1 && shard.index <= shard.total {
+			return shard, nil
+		}
+	}
+
+	return testShard{}, fmt.Errorf("invalid shard %q, expected i/N with 1 <= i <= N, e.g. 1/4", value)
+}
+
+func (s testShard) String() string {
+	return fmt.Sprintf("%d/%d", s.index, s.total)
+}
+
+// files returns the test files that belong to the shard.
+//
+// Files are distributed so that all shards take about the same time to run,
+// based on the given durations. Files without a recorded duration are assumed
+// to take the average recorded duration. The split only depends on the set of
+// files and the durations, so every machine computes the same shards.
+func (s testShard) files(filenames []string, timings testTimings) []string {
+	type weightedFile struct {
+		name     string
+		duration float64
+	}
+
+	var recordedTotal float64
+	recordedFiles := 0
+	for _, filename := range filenames {
+		if duration, ok := timings[timingKey(filename)]; ok {
+			recordedTotal += duration
+			recordedFiles++
+		}
+	}
+	assumedDuration := defaultFileDuration
+	if recordedFiles > 0 {
+		assumedDuration = recordedTotal / float64(recordedFiles)
+	}
+
+	files := make([]weightedFile, 0, len(filenames))
+	for _, filename := range filenames {
+		duration, ok := timings[timingKey(filename)]
+		if !ok {
+			duration = assumedDuration
+		}
+		files = append(files, weightedFile{name: filename, duration: duration})
+	}
+
+	// Assign the longest files first, each to the shard with the least work so far.
+	sort.Slice(files, func(i, j int) bool {
+		if files[i].duration != files[j].duration {
+			return files[i].duration > files[j].duration
+		}
+		return timingKey(files[i].name) < timingKey(files[j].name)
+	})
+
+	shardDurations := make([]float64, s.total)
+	var shardFiles []string
+	for _, file := range files {
+		shortest := 0
+		for i, duration := range shardDurations {
+			if duration < shardDurations[shortest] {
+				shortest = i
+			}
+		}
+		shardDurations[shortest] += file.duration
+
+		if shortest == s.index-1 {
+			shardFiles = append(shardFiles, file.name)
+		}
+	}
+
+	sort.Strings(shardFiles)
+	return shardFiles
+}
+
+// testTimings holds the duration in seconds of each test file, keyed by its slash-separated path.
+type testTimings map[string]float64
+
+func timingKey(filename string) string {
+	return filepath.ToSlash(filepath.Clean(filename))
+}
+
+// loadTestTimings reads the timing file written by a previous run.
+// A missing timing file results in no recorded durations.
+func loadTestTimings(state *flowkit.State, path string) (testTimings, error) {
+	timings := testTimings{}
+
+	data, err := state.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return timings, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal(data, &timings)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timing file %s: %w", path, err)
+	}
+
+	return timings, nil
+}
+
+// update records the durations of the test files that ran,
+// keeping the durations of all other files.
+func (t testTimings) update(durations map[string]time.Duration) {
+	for filename, duration := range durations {
+		t[timingKey(filename)] = duration.Seconds()
+	}
+}
@@ -0,0 +1,122 @@
+/*
+ * Flow CLI
+ *
+ * Copyright Flow Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-cli/internal/util"
+)
+
+func TestParseShard(t *testing.T) {
+	t.Parallel()
+
+	shard, err := parseShard("2/4")
+	require.NoError(t, err)
+	assert.Equal(t, testShard{index: 2, total: 4}, shard)
+	assert.Equal(t, "2/4", shard.String())
+
+	for _, value := range []string{"0/4", "5/4", "1/0", "2", "a/b"} {
+		_, err := parseShard(value)
+		assert.ErrorContains(t, err, "invalid shard", value)
+	}
+}
+
+func TestShardFiles(t *testing.T) {
+	t.Parallel()
+
+	filenames := []string{
+		"tests/a_test.cdc",
+		"tests/b_test.cdc",
+		"tests/c_test.cdc",
+		"tests/d_test.cdc",
+		"tests/e_test.cdc",
+	}
+
+	t.Run("without timings", func(t *testing.T) {
+		t.Parallel()
+
+		var all []string
+		for i := 1; i <= 2; i++ {
+			shard := testShard{index: i, total: 2}
+			files := shard.files(filenames, testTimings{})
+
+			// The order of the found files does not matter
+			reversed := []string{filenames[4], filenames[3], filenames[2], filenames[1], filenames[0]}
+			assert.Equal(t, files, shard.files(reversed, testTimings{}))
+
+			all = append(all, files...)
+		}
+		assert.ElementsMatch(t, filenames, all)
+	})
+
+	t.Run("with timings", func(t *testing.T) {
+		t.Parallel()
+
+		timings := testTimings{
+			"tests/a_test.cdc": 10,
+			"tests/b_test.cdc": 4,
+			"tests/c_test.cdc": 3,
+			"tests/d_test.cdc": 2,
+		}
+
+		// e_test.cdc has no recorded duration and is assumed to take the average of 4.75s
+		assert.Equal(t,
+			[]string{"tests/a_test.cdc", "tests/d_test.cdc"},
+			testShard{index: 1, total: 2}.files(filenames, timings),
+		)
+		assert.Equal(t,
+			[]string{"tests/b_test.cdc", "tests/c_test.cdc", "tests/e_test.cdc"},
+			testShard{index: 2, total: 2}.files(filenames, timings),
+		)
+	})
+
+	t.Run("more shards than files", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Empty(t, testShard{index: 6, total: 6}.files(filenames, testTimings{}))
+	})
+}
+
+func TestTestTimings(t *testing.T) {
+	t.Parallel()
+
+	_, state, rw := util.TestMocks(t)
+
+	timings, err := loadTestTimings(state, "missing.json")
+	require.NoError(t, err)
+	assert.Empty(t, timings)
+
+	require.NoError(t, rw.WriteFile("timings.json", []byte(`{"tests/a_test.cdc": 1.5, "tests/b_test.cdc": 2}`), 0644))
+	timings, err = loadTestTimings(state, "timings.json")
+	require.NoError(t, err)
+
+	timings.update(map[string]time.Duration{
+		"./tests/b_test.cdc": 500 * time.Millisecond,
+	})
+	assert.Equal(t, testTimings{"tests/a_test.cdc": 1.5, "tests/b_test.cdc": 0.5}, timings)
+
+	require.NoError(t, rw.WriteFile("invalid.json", []byte(`[]`), 0644))
+	_, err = loadTestTimings(state, "invalid.json")
+	assert.ErrorContains(t, err, "invalid timing file invalid.json")
+}
@@ -29,6 +29,7 @@ import (
 	goRuntime "runtime"
 	"strings"
 	"sync"
+	"time"
 
 	cdcTests "github.com/onflow/cadence-tools/test"
 	"github.com/onflow/cadence/common"
@@ -68,18 +69,28 @@ const helperScriptSubstr = "_helper"
 // scripts and transactions are excluded from coverage report.
 const contractsCoverCode = "contracts"
 
+// The network used to attribute coverage to contract source files.
+const coverageNetwork = "testing"
+
 // The default glob pattern to find test files.
 const defaultTestSuffix = "_test.cdc"
 
 type flagsTests struct {
-	Cover        bool   `default:"false" flag:"cover" info:"Use the cover flag to calculate coverage report"`
-	CoverProfile string `default:"lcov.info" flag:"coverprofile" info:"Filename to write the calculated coverage report. Supported extensions are .info, .lcov, and .json"`
-	CoverCode    string `default:"all" flag:"covercode" info:"Use the covercode flag to calculate coverage report only for certain types of code. Available values are \"all\" & \"contracts\""`
-	Random       bool   `default:"false" flag:"random" info:"Use the random flag to execute test cases randomly"`
-	Seed         int64  `default:"0" flag:"seed" info:"Use the seed flag to manipulate random execution of test cases"`
-	Name         string `default:"" flag:"name" info:"Use the name flag to run only tests that match the given name"`
-	Jobs         int    `default:"0" flag:"jobs" info:"Maximum number of test files to run concurrently (default: number of CPU cores)"`
-	BaseDir      string `default:"" flag:"base-dir" info:"Directory to search for test files (defaults to current directory)"`
+	Cover          bool     `default:"false" flag:"cover" info:"Use the cover flag to calculate coverage report"`
+	CoverProfile   string   `default:"lcov.info" flag:"coverprofile" info:"Filename to write the calculated coverage report. Supported extensions are .info, .lcov, .json and .html"`
+	CoverCode      string   `default:"all" flag:"covercode" info:"Use the covercode flag to calculate coverage report only for certain types of code. Available values are \"all\" & \"contracts\""`
+	CoverMin       []string `default:"" flag:"cover-min" info:"Minimum coverage percentage, either for all covered code (e.g. 80) or for a single contract (e.g. FooContract=90). The run fails if coverage is lower"`
+	CoverMerge     []string `default:"" flag:"cover-merge" info:"JSON coverage profiles from previous runs (e.g. CI shards) to merge into the coverage report. Without the cover flag, only the given profiles are merged and no tests are run"`
+	Random         bool     `default:"false" flag:"random" info:"Use the random flag to execute test cases randomly"`
+	Seed           int64    `default:"0" flag:"seed" info:"Use the seed flag to manipulate random execution of test cases"`
+	Name           string   `default:"" flag:"name" info:"Use the name flag to run only tests that match the given name"`
+	Jobs           int      `default:"0" flag:"jobs" info:"Maximum number of test files to run concurrently (default: number of CPU cores)"`
+	BaseDir        string   `default:"" flag:"base-dir" info:"Directory to search for test files (defaults to current directory)"`
+	Reporter       string   `default:"" flag:"reporter" info:"Write a report with one entry per test case. Available values are \"junit\", \"tap\" & \"json-lines\""`
+	ReporterOutput string   `default:"" flag:"reporter-output" info:"Filename to write the test report to (defaults to test-report.xml, test-report.tap or test-report.jsonl)"`
+	Watch          bool     `default:"false" flag:"watch" info:"Watch Cadence files for changes and rerun the affected tests"`
+	Shard          string   `default:"" flag:"shard" info:"Run only one shard of the test files, e.g. 2/4 runs the second of four shards"`
+	Timings        string   `default:"" flag:"timings" info:"File with test file durations of previous runs, used to balance shards. The durations of this run are written back to it"`
 
 	// Fork mode flags
 	Fork       string // Use definition in init()
@@ -97,7 +108,25 @@ var TestCommand = &command.Command{
 flow test
 
 # Run tests in the specified files
-flow test test1.cdc test2.cdc`,
+flow test test1.cdc test2.cdc
+
+# Write a JUnit report for CI
+flow test --reporter junit --reporter-output report.xml
+
+# Merge the coverage profiles of several CI shards
+flow test --cover-merge shard-1.json,shard-2.json --coverprofile lcov.info
+
+# Run the second of four CI shards, balanced by the durations of previous runs
+flow test --shard 2/4 --timings test-timings.json
+
+# Rerun the affected tests whenever a Cadence file changes
+flow test --watch
+
+# Fail if coverage is below 80%, or below 90% for FooContract
+flow test --cover --cover-min 80 --cover-min FooContract=90
+
+# Write an HTML report with annotated contract sources
+flow test --cover --coverprofile report.html`,
 		Args:    cobra.ArbitraryArgs,
 		GroupID: "tools",
 	},
@@ -116,13 +145,29 @@ func init() {
 
 func run(
 	args []string,
-	_ command.GlobalFlags,
+	global command.GlobalFlags,
 	logger output.Logger,
 	_ flowkit.Services,
 	state *flowkit.State,
 ) (command.Result, error) {
-	if !testFlags.Cover && testFlags.CoverProfile != "lcov.info" {
-		return nil, fmt.Errorf("the '--coverprofile' flag requires the '--cover' flag")
+	coverMerge := len(testFlags.CoverMerge) > 0
+	if !testFlags.Cover && !coverMerge && testFlags.CoverProfile != "lcov.info" {
+		return nil, fmt.Errorf("the '--coverprofile' flag requires the '--cover' or '--cover-merge' flag")
+	}
+	if !testFlags.Cover && !coverMerge && len(testFlags.CoverMin) > 0 {
+		return nil, fmt.Errorf("the '--cover-min' flag requires the '--cover' or '--cover-merge' flag")
+	}
+	thresholds, err := parseCoverageThresholds(testFlags.CoverMin)
+	if err != nil {
+		return nil, err
+	}
+	if testFlags.Reporter == "" && testFlags.ReporterOutput != "" {
+		return nil, fmt.Errorf("the '--reporter-output' flag requires the '--reporter' flag")
+	}
+	if testFlags.Reporter != "" {
+		if err := validateReporter(testFlags.Reporter); err != nil {
+			return nil, err
+		}
 	}
 	if testFlags.Random && testFlags.Seed > 0 {
 		logger.Info(fmt.Sprintf(
@@ -130,55 +175,99 @@ func run(
 			output.WarningEmoji(),
 		))
 	}
-	if testFlags.Cover && testFlags.Jobs > 1 {
-		logger.Info(fmt.Sprintf(
-			"%s The '--jobs' flag is ignored when coverage is enabled. Tests will run sequentially.",
-			output.WarningEmoji(),
-		))
+
+	if testFlags.Watch {
+		if testFlags.Cover || coverMerge || testFlags.Reporter != "" {
+			return nil, fmt.Errorf("the '--watch' flag cannot be combined with the coverage or reporter flags")
+		}
+		if testFlags.Shard != "" || testFlags.Timings != "" {
+			return nil, fmt.Errorf("the '--watch' flag cannot be combined with the '--shard' or '--timings' flags")
+		}
+		projectDir, err := util.ProjectDir(global.ConfigPaths)
+		if err != nil {
+			return nil, err
+		}
+		return nil, watchTests(args, projectDir, state, testFlags, logger)
 	}
 
-	var filenames []string
-	if len(args) == 0 {
-		baseDir := "."
-		if testFlags.BaseDir != "" {
-			baseDir = testFlags.BaseDir
+	var shard *testShard
+	if testFlags.Shard != "" {
+		s, err := parseShard(testFlags.Shard)
+		if err != nil {
+			return nil, err
 		}
-		var err error
-		filenames, err = findAllTestFiles(baseDir)
+		shard = &s
+	}
+
+	timings := testTimings{}
+	if testFlags.Timings != "" {
+		timings, err = loadTestTimings(state, testFlags.Timings)
 		if err != nil {
-			return nil, fmt.Errorf("error loading script files: %w", err)
+			return nil, fmt.Errorf("error loading timing file: %w", err)
 		}
-	} else {
-		filenames = args
 	}
 
-	testFiles := make(map[string][]byte, 0)
-	for _, filename := range filenames {
-		code, err := state.ReadFile(filename)
+	var testResult *result
+	if testFlags.Cover || !coverMerge {
+		testFiles, err := loadTestFiles(args, state)
 		if err != nil {
-			return nil, fmt.Errorf("error loading script file: %w", err)
+			return nil, err
 		}
 
-		testFiles[filename] = code
+		if shard != nil {
+			filenames := make([]string, 0, len(testFiles))
+			for filename := range testFiles {
+				filenames = append(filenames, filename)
+			}
+
+			shardFiles := make(map[string][]byte)
+			for _, filename := range shard.files(filenames, timings) {
+				shardFiles[filename] = testFiles[filename]
+			}
+			logger.Info(fmt.Sprintf("Running shard %s with %d of %d test files", shard, len(shardFiles), len(testFiles)))
+			testFiles = shardFiles
+		}
+
+		testResult, err = testCode(testFiles, state, testFlags)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		// Only merge the given coverage profiles, without running any tests.
+		testResult = &result{
+			CoverageReport: newCoverageReport(state, coverageNetwork, testFlags.CoverCode),
+		}
 	}
 
-	result, err := testCode(testFiles, state, testFlags)
-	if err != nil {
-		return nil, err
+	for _, profile := range testFlags.CoverMerge {
+		profileReport, err := loadCoverageProfile(state, coverageNetwork, profile)
+		if err != nil {
+			return nil, fmt.Errorf("error loading coverage profile: %w", err)
+		}
+		mergeCoverageReport(testResult.CoverageReport, profileReport)
 	}
 
-	if result.CoverageReport != nil {
+	if testResult.CoverageReport != nil {
+		testResult.coverageFailures = thresholds.check(testResult.CoverageReport)
+		if len(testResult.coverageFailures) > 0 {
+			testResult.exitCode = 1
+		}
+	}
+
+	if testResult.CoverageReport != nil {
 		var file []byte
 		var err error
 
 		ext := filepath.Ext(testFlags.CoverProfile)
 		switch ext {
 		case ".json":
-			file, err = json.MarshalIndent(result.CoverageReport, "", "  ")
+			file, err = json.MarshalIndent(testResult.CoverageReport, "", "  ")
 		case ".lcov", ".info":
-			file, err = result.CoverageReport.MarshalLCOV()
+			file, err = testResult.CoverageReport.MarshalLCOV()
+		case ".html":
+			file, err = renderCoverageHTML(testResult.CoverageReport, state)
 		default:
-			return nil, fmt.Errorf("given format: %v, only .json and .lcov are supported", ext)
+			return nil, fmt.Errorf("given format: %v, only .json, .lcov and .html are supported", ext)
 		}
 		if err != nil {
 			return nil, fmt.Errorf("error serializing coverage report: %w", err)
@@ -190,7 +279,69 @@ func run(
 		}
 	}
 
-	return result, nil
+	if testFlags.Timings != "" && len(testResult.Durations) > 0 {
+		timings.update(testResult.Durations)
+
+		file, err := json.MarshalIndent(timings, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("error serializing timing file: %w", err)
+		}
+
+		err = os.WriteFile(testFlags.Timings, file, 0644)
+		if err != nil {
+			return nil, fmt.Errorf("error writing timing file: %w", err)
+		}
+	}
+
+	if testFlags.Reporter != "" {
+		report, err := testResult.renderReport(testFlags.Reporter)
+		if err != nil {
+			return nil, fmt.Errorf("error rendering test report: %w", err)
+		}
+
+		reporterOutput := testFlags.ReporterOutput
+		if reporterOutput == "" {
+			reporterOutput = defaultReporterOutputs[testFlags.Reporter]
+		}
+
+		err = os.WriteFile(reporterOutput, report, 0644)
+		if err != nil {
+			return nil, fmt.Errorf("error writing test report file: %w", err)
+		}
+	}
+
+	return testResult, nil
+}
+
+// loadTestFiles reads the given test files, or all test files
+// found in the base directory if no files are given.
+func loadTestFiles(args []string, state *flowkit.State) (map[string][]byte, error) {
+	var filenames []string
+	if len(args) == 0 {
+		baseDir := "."
+		if testFlags.BaseDir != "" {
+			baseDir = testFlags.BaseDir
+		}
+		var err error
+		filenames, err = findAllTestFiles(baseDir)
+		if err != nil {
+			return nil, fmt.Errorf("error loading script files: %w", err)
+		}
+	} else {
+		filenames = args
+	}
+
+	testFiles := make(map[string][]byte, 0)
+	for _, filename := range filenames {
+		code, err := state.ReadFile(filename)
+		if err != nil {
+			return nil, fmt.Errorf("error loading script file: %w", err)
+		}
+
+		testFiles[filename] = code
+	}
+
+	return testFiles, nil
 }
 
 func testCode(
@@ -255,20 +406,11 @@ func testCode(
 		}
 	}
 
+	// Each test file collects coverage into its own report,
+	// which are merged into this one once all files finished.
 	var coverageReport *runtime.CoverageReport
 	if flags.Cover {
-		coverageReport = state.CreateCoverageReport("testing")
-		if flags.CoverCode == contractsCoverCode {
-			coverageReport.WithLocationFilter(
-				func(location common.Location) bool {
-					_, addressLoc := location.(common.AddressLocation)
-					// We only allow inspection of AddressLocation,
-					// since scripts and transactions cannot be
-					// attributed to their source files anyway.
-					return addressLoc
-				},
-			)
-		}
+		coverageReport = newCoverageReport(state, coverageNetwork, flags.CoverCode)
 	}
 
 	var seed int64
@@ -279,12 +421,8 @@ func testCode(
 	}
 
 	// Limit concurrency to flags.Jobs, defaulting to number of CPU cores.
-	// When coverage is enabled, force sequential execution because the
-	// coverage report is shared across all test file goroutines.
 	jobs := flags.Jobs
-	if flags.Cover {
-		jobs = 1
-	} else if jobs <= 0 {
+	if jobs <= 0 {
 		jobs = goRuntime.NumCPU()
 	}
 	sem := make(chan struct{}, jobs)
@@ -293,6 +431,8 @@ func testCode(
 		scriptPath        string
 		results           cdcTests.Results
 		networkResolution string
+		coverageReport    *runtime.CoverageReport
+		duration          time.Duration
 		err               error
 	}
 
@@ -378,8 +518,10 @@ func testCode(
 			if forkCfg != nil {
 				fileRunner = fileRunner.WithFork(*forkCfg)
 			}
-			if coverageReport != nil {
-				fileRunner = fileRunner.WithCoverageReport(coverageReport)
+			var fileCoverageReport *runtime.CoverageReport
+			if flags.Cover {
+				fileCoverageReport = newCoverageReport(state, coverageNetwork, flags.CoverCode)
+				fileRunner = fileRunner.WithCoverageReport(fileCoverageReport)
 			}
 			if seed > 0 {
 				fileRunner = fileRunner.WithRandomSeed(seed)
@@ -387,6 +529,7 @@ func testCode(
 
 			var fileResults cdcTests.Results
 			var runErr error
+			start := time.Now()
 
 			if flags.Name != "" {
 				testFunctions, err := fileRunner.GetTests(string(code))
@@ -415,6 +558,8 @@ func testCode(
 				scriptPath:        scriptPath,
 				results:           fileResults,
 				networkResolution: resolvedNetwork,
+				coverageReport:    fileCoverageReport,
+				duration:          time.Since(start),
 				err:               runErr,
 			}
 		}(scriptPath, code)
@@ -426,6 +571,8 @@ func testCode(
 	}()
 
 	testResults := make(map[string]cdcTests.Results, 0)
+	durations := make(map[string]time.Duration, len(testFiles))
+	fileCoverageReports := make(map[string]*runtime.CoverageReport, len(testFiles))
 	exitCode := 0
 	var firstErr error
 
@@ -435,10 +582,14 @@ func testCode(
 		}
 		if r.results != nil {
 			testResults[r.scriptPath] = r.results
+			durations[r.scriptPath] = r.duration
 		}
 		if r.networkResolution != "" {
 			fileNetworkResolutions[r.scriptPath] = r.networkResolution
 		}
+		if r.coverageReport != nil {
+			fileCoverageReports[r.scriptPath] = r.coverageReport
+		}
 		for _, res := range testResults[r.scriptPath] {
 			if res.Error != nil {
 				exitCode = 1
@@ -451,6 +602,10 @@ func testCode(
 		return nil, firstErr
 	}
 
+	if coverageReport != nil {
+		mergeCoverageReports(coverageReport, fileCoverageReports)
+	}
+
 	// Track fork test usage metrics - aggregate into single event
 	hasPragmaFiles := len(fileNetworkResolutions) > 0
 	hasStaticFork := forkCfg != nil
@@ -507,6 +662,7 @@ func testCode(
 		Results:        testResults,
 		CoverageReport: coverageReport,
 		RandomSeed:     seed,
+		Durations:      durations,
 		exitCode:       exitCode,
 	}, nil
 }
@@ -518,35 +674,24 @@ func importResolver(scriptPath string, state *flowkit.State) cdcTests.ImportReso
 	}
 
 	return func(network string, location common.Location) (string, error) {
-		contract := config.Contract{}
-
-		switch location := location.(type) {
-		case common.AddressLocation:
-			contract = contracts[location.Name]
+		importedFilePath, helperScript := importPath(scriptPath, location, contracts)
 
-		case common.StringLocation:
-			relativePath := location.String()
-
-			if strings.Contains(relativePath, helperScriptSubstr) {
-				importedScriptFilePath := util.AbsolutePath(scriptPath, relativePath)
-				scriptCode, err := state.ReadFile(importedScriptFilePath)
-				if err != nil {
-					return "", nil
-				}
-				return string(scriptCode), nil
+		if helperScript {
+			scriptCode, err := state.ReadFile(importedFilePath)
+			if err != nil {
+				return "", nil
 			}
-
-			contract = contracts[relativePath]
+			return string(scriptCode), nil
 		}
 
-		if contract.Location == "" {
+		if importedFilePath == "" {
 			return "", fmt.Errorf(
 				"cannot find contract with location '%s' in configuration",
 				location,
 			)
 		}
 
-		contractCode, err := state.ReadFile(contract.Location)
+		contractCode, err := state.ReadFile(importedFilePath)
 		if err != nil {
 			return "", err
 		}
@@ -555,6 +700,27 @@ func importResolver(scriptPath string, state *flowkit.State) cdcTests.ImportReso
 	}
 }
 
+// importPath returns the path of the file an import of the given script resolves to,
+// and whether that file is a helper script rather than a contract.
+// The path is empty if the import does not resolve to a contract in configuration.
+func importPath(scriptPath string, location common.Location, contracts map[string]config.Contract) (string, bool) {
+	switch location := location.(type) {
+	case common.AddressLocation:
+		return contracts[location.Name].Location, false
+
+	case common.StringLocation:
+		relativePath := location.String()
+
+		if strings.Contains(relativePath, helperScriptSubstr) {
+			return util.AbsolutePath(scriptPath, relativePath), true
+		}
+
+		return contracts[relativePath].Location, false
+	}
+
+	return "", false
+}
+
 func fileResolver(scriptPath string, state *flowkit.State) cdcTests.FileResolver {
 	return func(path string) (string, error) {
 		importFilePath := util.AbsolutePath(scriptPath, path)
@@ -578,7 +744,10 @@ type result struct {
 	Results        map[string]cdcTests.Results
 	CoverageReport *runtime.CoverageReport
 	RandomSeed     int64
-	exitCode       int
+	Durations      map[string]time.Duration
+	// coverageFailures lists the --cover-min thresholds that were not met.
+	coverageFailures []string
+	exitCode         int
 }
 
 var _ command.ResultWithExitCode = &result{}
@@ -649,6 +818,9 @@ func (r *result) String() string {
 
 	if len(r.Results) == 0 {
 		_, _ = fmt.Fprint(writer, "No tests found")
+		if r.CoverageReport != nil {
+			_, _ = fmt.Fprintf(writer, "\n%s", r.CoverageReport.String())
+		}
 	} else {
 		for scriptPath, testResult := range r.Results {
 			testOutput := cdcTests.PrettyPrintResults(testResult, scriptPath)
@@ -662,6 +834,9 @@ func (r *result) String() string {
 			_, _ = fmt.Fprintf(writer, "\nSeed: %d", r.RandomSeed)
 		}
 	}
+	for _, failure := range r.coverageFailures {
+		_, _ = fmt.Fprintf(writer, "\n%s", branding.ErrorStyle.Render(failure))
+	}
 
 	_ = writer.Flush()
 
@@ -687,6 +862,10 @@ func (r *result) Oneliner() string {
 		builder.WriteString(fmt.Sprintf("Seed: %d", r.RandomSeed))
 		builder.WriteString("\n")
 	}
+	for _, failure := range r.coverageFailures {
+		builder.WriteString(failure)
+		builder.WriteString("\n")
+	}
 
 	return builder.String()
 }
@@ -405,6 +405,51 @@ func TestExecutingTests(t *testing.T) {
 		assert.Contains(t, string(jsonReport), `{"coverage":{"FooContract.cdc":{`)
 	})
 
+	t.Run("with code coverage and parallel jobs", func(t *testing.T) {
+		// Setup
+		_, state, _ := util.TestMocks(t)
+		state.Contracts().AddOrUpdate(config.Contract{
+			Name:     tests.ContractFooCoverage.Name,
+			Location: tests.ContractFooCoverage.Filename,
+			Aliases:  aliases,
+		})
+		t.Parallel()
+
+		// Execute the same script from two files, so that
+		// the coverage of both runs has to be summed up.
+		script := tests.TestScriptWithCoverage
+		testFiles := map[string][]byte{
+			"first_" + script.Filename:  script.Source,
+			"second_" + script.Filename: script.Source,
+		}
+		flags := flagsTests{
+			Cover:     true,
+			CoverCode: contractsCoverCode,
+			Jobs:      2,
+		}
+		result, err := testCode(testFiles, state, flags)
+
+		require.NoError(t, err)
+		require.Len(t, result.Results, 2)
+
+		location := common.AddressLocation{
+			Name:    "FooContract",
+			Address: common.Address{0, 0, 0, 0, 0, 0, 0, 7},
+		}
+		coverage := result.CoverageReport.Coverage[location]
+
+		assert.Equal(t, 15, coverage.Statements)
+		assert.Equal(t, "100.0%", coverage.Percentage())
+		assert.EqualValues(
+			t,
+			map[int]int{
+				6: 2, 14: 2, 18: 20, 19: 2, 20: 18, 21: 2, 22: 16, 23: 2,
+				24: 14, 25: 2, 26: 12, 27: 2, 30: 10, 31: 8, 34: 2,
+			},
+			coverage.LineHits,
+		)
+	})
+
 	t.Run("with code coverage for contracts only", func(t *testing.T) {
 		// Setup
 		_, state, _ := util.TestMocks(t)
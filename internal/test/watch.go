@@ -0,0 +1,312 @@
+/*
+ * Flow CLI
+ *
+ * Copyright Flow Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/onflow/cadence/ast"
+	"github.com/onflow/cadence/common"
+	"github.com/onflow/cadence/parser"
+	"github.com/radovskyb/watcher"
+
+	"github.com/onflow/flowkit/v2"
+	"github.com/onflow/flowkit/v2/config"
+	"github.com/onflow/flowkit/v2/output"
+
+	"github.com/onflow/flow-cli/internal/util"
+)
+
+const cadenceExt = ".cdc"
+
+// watchTests runs the tests once and then watches the Cadence files of the project,
+// rerunning the test files affected by every change until the watcher is closed.
+func watchTests(args []string, projectDir string, state *flowkit.State, flags flagsTests, logger output.Logger) error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+
+	w := watcher.New()
+	w.IgnoreHiddenFiles(true)
+	startErr, err := util.WatchFiles(w, projectDir)
+	if err != nil {
+		return fmt.Errorf("failed watching project files: %w", err)
+	}
+	defer w.Close()
+
+	runAffectedTests(args, nil, nil, state, flags, logger)
+
+	for {
+		select {
+		case event := <-w.Event:
+			// Test files and the files they reference are read relative to the current directory,
+			// while the contract locations of the configuration are relative to the project directory,
+			// so changed files are matched in both forms and reported relative to the project directory.
+			changedFiles := map[string]struct{}{}
+			changedPaths := map[string]struct{}{}
+			addChange := func(event watcher.Event) {
+				for _, path := range []string{event.Path, event.OldPath} {
+					if filepath.Ext(path) != cadenceExt {
+						continue
+					}
+					for _, base := range []string{projectDir, cwd} {
+						rel, err := filepath.Rel(base, path)
+						if err != nil {
+							continue
+						}
+						changedPaths[rel] = struct{}{}
+						if base == projectDir {
+							changedFiles[rel] = struct{}{}
+						}
+					}
+				}
+			}
+
+			// A single save often results in several events, e.g. a removal and a creation,
+			// so collect all events of the current poll before running the tests.
+			addChange(event)
+			for pending := true; pending; {
+				select {
+				case event := <-w.Event:
+					addChange(event)
+				case <-time.After(util.WatchInterval / 5):
+					pending = false
+				}
+			}
+
+			if len(changedFiles) == 0 {
+				continue
+			}
+
+			runAffectedTests(args, sortedKeys(changedFiles), sortedKeys(changedPaths), state, flags, logger)
+
+		case err := <-w.Error:
+			return fmt.Errorf("failed watching project files: %w", err)
+
+		case err := <-startErr:
+			return fmt.Errorf("failed watching project files: %w", err)
+
+		case <-w.Closed:
+			return nil
+		}
+	}
+}
+
+// runAffectedTests runs the test files affected by the changed paths and prints a summary
+// listing the changed files. All test files are run if changedPaths is nil.
+func runAffectedTests(
+	args []string,
+	changedFiles []string,
+	changedPaths []string,
+	state *flowkit.State,
+	flags flagsTests,
+	logger output.Logger,
+) {
+	if changedPaths != nil {
+		logger.Info(fmt.Sprintf("\nChanged: %s", strings.Join(changedFiles, ", ")))
+	}
+
+	testFiles, err := loadTestFiles(args, state)
+	if err != nil {
+		logger.Error(err.Error())
+		return
+	}
+
+	if changedPaths != nil {
+		affected := affectedTestFiles(testDependencies(testFiles, state), changedPaths)
+		for scriptPath := range testFiles {
+			if _, ok := affected[filepath.Clean(scriptPath)]; !ok {
+				delete(testFiles, scriptPath)
+			}
+		}
+	}
+
+	if len(testFiles) == 0 {
+		logger.Info("No affected tests")
+	} else {
+		start := time.Now()
+		testResult, err := testCode(testFiles, state, flags)
+		if err != nil {
+			logger.Error(err.Error())
+		} else {
+			logger.Info(testResult.summary(time.Since(start)))
+		}
+	}
+
+	logger.Info(fmt.Sprintf("%s Watching for changes...", output.TryEmoji()))
+}
+
+// testDependencies returns the files each test file depends on: the contracts,
+// scripts and transactions it imports, directly or through other imports,
+// as well as the Cadence files it references by path, e.g. to deploy a contract.
+//
+// Test files are keyed by their cleaned path.
+func testDependencies(testFiles map[string][]byte, state *flowkit.State) map[string]map[string]struct{} {
+	contracts := make(map[string]config.Contract, 0)
+	for _, contract := range *state.Contracts() {
+		contracts[contract.Name] = contract
+	}
+
+	dependencies := make(map[string]map[string]struct{}, len(testFiles))
+	for scriptPath, code := range testFiles {
+		fileDependencies := map[string]struct{}{}
+		collectDependencies(scriptPath, code, state, contracts, fileDependencies)
+		dependencies[filepath.Clean(scriptPath)] = fileDependencies
+	}
+
+	return dependencies
+}
+
+func collectDependencies(
+	scriptPath string,
+	code []byte,
+	state *flowkit.State,
+	contracts map[string]config.Contract,
+	dependencies map[string]struct{},
+) {
+	program, _ := parser.ParseProgram(nil, code, parser.Config{})
+	if program == nil {
+		return
+	}
+
+	var paths []string
+
+	for _, declaration := range program.ImportDeclarations() {
+		locations := []common.Location{declaration.Location}
+		if addressLocation, ok := declaration.Location.(common.AddressLocation); ok {
+			// Address imports are resolved per imported contract
+			locations = locations[:0]
+			for _, imported := range declaration.Imports {
+				locations = append(locations, common.AddressLocation{
+					Address: addressLocation.Address,
+					Name:    imported.Identifier.Identifier,
+				})
+			}
+		}
+
+		for _, location := range locations {
+			importedFilePath, _ := importPath(scriptPath, location, contracts)
+			if importedFilePath == "" && util.IsPathLocation(location) {
+				importedFilePath = util.AbsolutePath(scriptPath, location.String())
+			}
+			if importedFilePath != "" {
+				paths = append(paths, importedFilePath)
+			}
+		}
+	}
+
+	// Files are resolved the same way as by fileResolver, relative to
+	// the script first and to the project root otherwise.
+	ast.NewInspector(program).Preorder(
+		[]ast.Element{(*ast.StringExpression)(nil)},
+		func(element ast.Element) {
+			path := element.(*ast.StringExpression).Value
+			if filepath.Ext(path) != cadenceExt {
+				return
+			}
+
+			relativePath := filepath.Clean(util.AbsolutePath(scriptPath, path))
+			if _, err := state.ReadFile(relativePath); err == nil {
+				paths = append(paths, relativePath)
+			} else {
+				paths = append(paths, path)
+			}
+		},
+	)
+
+	for _, path := range paths {
+		path = filepath.Clean(path)
+		if _, ok := dependencies[path]; ok {
+			continue
+		}
+		dependencies[path] = struct{}{}
+
+		dependencyCode, err := state.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		collectDependencies(path, dependencyCode, state, contracts, dependencies)
+	}
+}
+
+// affectedTestFiles returns the test files that changed or depend on any of the changed files.
+func affectedTestFiles(dependencies map[string]map[string]struct{}, changedFiles []string) map[string]struct{} {
+	affected := make(map[string]struct{})
+
+	for _, changedFile := range changedFiles {
+		changedFile = filepath.Clean(changedFile)
+
+		for testFile, fileDependencies := range dependencies {
+			if testFile == changedFile {
+				affected[testFile] = struct{}{}
+				continue
+			}
+			if _, ok := fileDependencies[changedFile]; ok {
+				affected[testFile] = struct{}{}
+			}
+		}
+	}
+
+	return affected
+}
+
+func sortedKeys(set map[string]struct{}) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// summary returns a compact overview of the test results, listing only the failed tests.
+func (r *result) summary(elapsed time.Duration) string {
+	var builder strings.Builder
+
+	passed, failed := 0, 0
+	for _, scriptPath := range r.sortedFiles() {
+		for _, testResult := range r.Results[scriptPath] {
+			if testResult.Error == nil {
+				passed++
+				continue
+			}
+
+			failed++
+			message := strings.SplitN(testResult.Error.Error(), "\n", 2)[0]
+			builder.WriteString(fmt.Sprintf("FAIL %s: %s: %s\n", scriptPath, testResult.TestName, message))
+		}
+	}
+
+	builder.WriteString(fmt.Sprintf(
+		"%d %s, %d passed, %d failed (%s)",
+		len(r.Results),
+		util.Pluralize("test file", len(r.Results)),
+		passed,
+		failed,
+		elapsed.Round(time.Millisecond),
+	))
+
+	return builder.String()
+}
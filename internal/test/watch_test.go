@@ -0,0 +1,148 @@
+/*
+ * Flow CLI
+ *
+ * Copyright Flow Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	cdcTests "github.com/onflow/cadence-tools/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flowkit/v2/config"
+
+	"github.com/onflow/flow-cli/internal/command"
+	"github.com/onflow/flow-cli/internal/util"
+)
+
+func TestAffectedTestFiles(t *testing.T) {
+	t.Parallel()
+
+	_, state, rw := util.TestMocks(t)
+	state.Contracts().AddOrUpdate(config.Contract{
+		Name:     "Foo",
+		Location: "contracts/Foo.cdc",
+	})
+	state.Contracts().AddOrUpdate(config.Contract{
+		Name:     "Bar",
+		Location: "contracts/Bar.cdc",
+	})
+
+	files := map[string]string{
+		"contracts/Foo.cdc": `
+			import "Bar"
+
+			access(all) contract Foo {}
+		`,
+		"contracts/Bar.cdc": `
+			access(all) contract Bar {}
+		`,
+		"transactions/setup.cdc": `
+			import Foo from 0x01
+
+			transaction {}
+		`,
+		"tests/test_helper.cdc": `
+			access(all) fun setup() {}
+		`,
+	}
+	for name, code := range files {
+		require.NoError(t, rw.WriteFile(name, []byte(code), 0644))
+	}
+
+	testFiles := map[string][]byte{
+		"tests/foo_test.cdc": []byte(`
+			import Test
+			import "Foo"
+
+			access(all) fun testFoo() {}
+		`),
+		"tests/setup_test.cdc": []byte(`
+			import Test
+			import "test_helper.cdc"
+
+			access(all) fun testSetup() {
+				let code = Test.readFile("../transactions/setup.cdc")
+			}
+		`),
+		"tests/other_test.cdc": []byte(`
+			import Test
+
+			access(all) fun testOther() {}
+		`),
+	}
+
+	dependencies := testDependencies(testFiles, state)
+
+	tests := []struct {
+		changed  string
+		affected []string
+	}{
+		{changed: "contracts/Bar.cdc", affected: []string{"tests/foo_test.cdc", "tests/setup_test.cdc"}},
+		{changed: "transactions/setup.cdc", affected: []string{"tests/setup_test.cdc"}},
+		{changed: "tests/test_helper.cdc", affected: []string{"tests/setup_test.cdc"}},
+		{changed: "tests/other_test.cdc", affected: []string{"tests/other_test.cdc"}},
+		{changed: "scripts/unused.cdc", affected: []string{}},
+	}
+
+	for _, test := range tests {
+		affected := make([]string, 0)
+		for testFile := range affectedTestFiles(dependencies, []string{test.changed}) {
+			affected = append(affected, testFile)
+		}
+		assert.ElementsMatch(t, test.affected, affected, test.changed)
+	}
+}
+
+func TestWatchFlags(t *testing.T) {
+	_, state, _ := util.TestMocks(t)
+
+	defer func(flags flagsTests) { testFlags = flags }(testFlags)
+
+	for _, flags := range []flagsTests{
+		{Watch: true, CoverProfile: "lcov.info", Shard: "1/2"},
+		{Watch: true, CoverProfile: "lcov.info", Timings: "timings.json"},
+	} {
+		testFlags = flags
+		_, err := run(nil, command.GlobalFlags{}, util.NoLogger, nil, state)
+		assert.EqualError(t, err, "the '--watch' flag cannot be combined with the '--shard' or '--timings' flags")
+	}
+}
+
+func TestWatchSummary(t *testing.T) {
+	t.Parallel()
+
+	r := &result{
+		Results: map[string]cdcTests.Results{
+			"b_test.cdc": {
+				{TestName: "testFails", Error: errors.New("assertion failed\nexpected 1, got 2")},
+			},
+			"a_test.cdc": {
+				{TestName: "testFirst"},
+				{TestName: "testSecond"},
+			},
+		},
+	}
+
+	expected := "FAIL b_test.cdc: testFails: assertion failed\n" +
+		"2 test files, 2 passed, 1 failed (1.5s)"
+	assert.Equal(t, expected, r.summary(1500*time.Millisecond))
+}
@@ -29,6 +29,7 @@ import (
 	"github.com/onflow/flow-go/fvm/environment"
 	reusableRuntime "github.com/onflow/flow-go/fvm/runtime"
 	fvmStorage "github.com/onflow/flow-go/fvm/storage"
+	"github.com/onflow/flow-go/fvm/storage/snapshot"
 	fvmState "github.com/onflow/flow-go/fvm/storage/state"
 	flowgo "github.com/onflow/flow-go/model/flow"
 	"github.com/rs/zerolog"
@@ -240,46 +241,6 @@ func profileTransactionWithFVM(
 		return nil, 0, fmt.Errorf("cannot profile transactions in genesis or block 1 (no prior state to fork from)")
 	}
 
-	nopLogger := zerolog.Nop()
-	baseStore, err := sqlite.New(sqlite.InMemory)
-	if err != nil {
-		return nil, 0, fmt.Errorf("failed to create storage: %w", err)
-	}
-
-	store, err := remote.New(baseStore, &nopLogger,
-		remote.WithForkHost(network.Host),
-		remote.WithForkHeight(forkHeight),
-	)
-	if err != nil {
-		return nil, 0, fmt.Errorf("failed to create forked storage at height %d: %w", forkHeight, err)
-	}
-
-	ctx := context.Background()
-	baseLedger, err := store.LedgerByHeight(ctx, forkHeight)
-	if err != nil {
-		return nil, 0, fmt.Errorf("failed to get ledger at height %d: %w", forkHeight, err)
-	}
-
-	execState := fvmState.NewExecutionState(baseLedger, fvmState.DefaultParameters())
-
-	computationProfile := runtime.NewComputationProfile()
-	executionEffortWeights := environment.MainnetExecutionEffortWeights
-	computationProfile.WithComputationWeights(executionEffortWeights)
-
-	runtimeConfig := runtime.Config{
-		ComputationProfile: computationProfile,
-	}
-	customRuntimePool := reusableRuntime.NewCustomReusableCadenceRuntimePool(
-		1,
-		chainID.Chain(),
-		runtimeConfig,
-		func(cfg runtime.Config) runtime.Runtime {
-			return runtime.NewRuntime(cfg)
-		},
-	)
-
-	vm := fvm.NewVirtualMachine()
-
 	blockHeader := &flowgo.Header{
 		HeaderBody: flowgo.HeaderBody{
 			ChainID:   chainID,
@@ -290,48 +251,30 @@ func profileTransactionWithFVM(
 		PayloadHash: flowgo.Identifier(block.ID),
 	}
 
-	baseFvmOptions := []fvm.Option{
-		fvm.WithLogger(nopLogger),
-		fvm.WithBlockHeader(blockHeader),
-		fvm.WithContractDeploymentRestricted(false),
-		fvm.WithComputationLimit(flowgo.DefaultMaxTransactionGasLimit),
-		fvm.WithReusableCadenceRuntimePool(customRuntimePool),
+	fork, err := newForkedExecution(network.Host, chainID, forkHeight, blockHeader)
+	if err != nil {
+		return nil, 0, err
 	}
 
-	userCtx := fvm.NewContext(
-		chainID.Chain(),
-		append(baseFvmOptions,
-			fvm.WithTransactionFeesEnabled(true),
-			fvm.WithAuthorizationChecksEnabled(true),
-			fvm.WithSequenceNumberCheckAndIncrementEnabled(true),
-		)...,
-	)
-
-	systemCtx := fvm.NewContext(
-		chainID.Chain(),
-		append(baseFvmOptions,
-			fvm.WithTransactionFeesEnabled(false),
-			fvm.WithAuthorizationChecksEnabled(false),
-			fvm.WithSequenceNumberCheckAndIncrementEnabled(false),
-		)...,
-	)
+	userCtx := fork.context(true, true)
+	systemCtx := fork.context(false, false)
 
 	// Execute prior transactions to recreate state
 	txIndex := 0
 	if len(priorUserTxs) > 0 {
-		if err := executeTransactions(vm, userCtx, execState, priorUserTxs, txIndex, logger); err != nil {
+		if err := fork.executeTransactions(userCtx, priorUserTxs, txIndex); err != nil {
 			return nil, 0, fmt.Errorf("failed to execute prior user transactions: %w", err)
 		}
 		txIndex += len(priorUserTxs)
 	}
 
 	if len(priorSystemTxs) > 0 {
-		if err := executeTransactions(vm, systemCtx, execState, priorSystemTxs, txIndex, logger); err != nil {
+		if err := fork.executeTransactions(systemCtx, priorSystemTxs, txIndex); err != nil {
 			return nil, 0, fmt.Errorf("failed to execute prior system transactions: %w", err)
 		}
 	}
 
-	computationProfile.Reset()
+	fork.profile.Reset()
 
 	targetFlowTx := convert.SDKTransactionToFlow(*targetTx)
 
@@ -340,15 +283,9 @@ func profileTransactionWithFVM(
 		targetCtx = systemCtx
 	}
 
-	blockDB := fvmStorage.NewBlockDatabase(execState, 0, nil)
-	txn, err := blockDB.NewTransaction(0, fvmState.DefaultParameters())
-	if err != nil {
-		return nil, 0, fmt.Errorf("failed to create transaction context: %w", err)
-	}
-
 	targetTxIndex := uint32(len(priorUserTxs) + len(priorSystemTxs))
 	txProc := fvm.Transaction(targetFlowTx, targetTxIndex)
-	_, output, err := vm.Run(targetCtx, txProc, txn)
+	_, output, err := fork.run(targetCtx, txProc)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to execute target transaction: %w", err)
 	}
@@ -357,7 +294,101 @@ func profileTransactionWithFVM(
 		logger.Info(fmt.Sprintf("⚠️  Transaction failed during execution: %s", output.Err.Error()))
 	}
 
-	return computationProfile, output.ComputationUsed, nil
+	return fork.profile, output.ComputationUsed, nil
+}
+
+// forkedExecution executes procedures with the FVM against the state of a network at a block height,
+// reading the registers from the network as they are accessed.
+type forkedExecution struct {
+	vm        *fvm.VirtualMachine
+	chainID   flowgo.ChainID
+	execState *fvmState.ExecutionState
+	profile   *runtime.ComputationProfile
+	options   []fvm.Option
+}
+
+func newForkedExecution(
+	host string,
+	chainID flowgo.ChainID,
+	forkHeight uint64,
+	blockHeader *flowgo.Header,
+) (*forkedExecution, error) {
+	nopLogger := zerolog.Nop()
+	baseStore, err := sqlite.New(sqlite.InMemory)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create storage: %w", err)
+	}
+
+	store, err := remote.New(baseStore, &nopLogger,
+		remote.WithForkHost(host),
+		remote.WithForkHeight(forkHeight),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create forked storage at height %d: %w", forkHeight, err)
+	}
+
+	ctx := context.Background()
+	baseLedger, err := store.LedgerByHeight(ctx, forkHeight)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ledger at height %d: %w", forkHeight, err)
+	}
+
+	execState := fvmState.NewExecutionState(baseLedger, fvmState.DefaultParameters())
+
+	computationProfile := runtime.NewComputationProfile()
+	executionEffortWeights := environment.MainnetExecutionEffortWeights
+	computationProfile.WithComputationWeights(executionEffortWeights)
+
+	runtimeConfig := runtime.Config{
+		ComputationProfile: computationProfile,
+	}
+	customRuntimePool := reusableRuntime.NewCustomReusableCadenceRuntimePool(
+		1,
+		chainID.Chain(),
+		runtimeConfig,
+		func(cfg runtime.Config) runtime.Runtime {
+			return runtime.NewRuntime(cfg)
+		},
+	)
+
+	return &forkedExecution{
+		vm:        fvm.NewVirtualMachine(),
+		chainID:   chainID,
+		execState: execState,
+		profile:   computationProfile,
+		options: []fvm.Option{
+			fvm.WithLogger(nopLogger),
+			fvm.WithBlockHeader(blockHeader),
+			fvm.WithContractDeploymentRestricted(false),
+			fvm.WithComputationLimit(flowgo.DefaultMaxTransactionGasLimit),
+			fvm.WithReusableCadenceRuntimePool(customRuntimePool),
+		},
+	}, nil
+}
+
+// context returns the FVM context of the fork, charging transaction fees if fees is set,
+// and checking signatures and sequence numbers if checks is set.
+func (f *forkedExecution) context(fees bool, checks bool) fvm.Context {
+	options := append([]fvm.Option{}, f.options...)
+	return fvm.NewContext(
+		f.chainID.Chain(),
+		append(options,
+			fvm.WithTransactionFeesEnabled(fees),
+			fvm.WithAuthorizationChecksEnabled(checks),
+			fvm.WithSequenceNumberCheckAndIncrementEnabled(checks),
+		)...,
+	)
+}
+
+// run executes the procedure against the state of the fork, without changing it.
+func (f *forkedExecution) run(ctx fvm.Context, proc fvm.Procedure) (*snapshot.ExecutionSnapshot, fvm.ProcedureOutput, error) {
+	blockDB := fvmStorage.NewBlockDatabase(f.execState, 0, nil)
+	txn, err := blockDB.NewTransaction(0, fvmState.DefaultParameters())
+	if err != nil {
+		return nil, fvm.ProcedureOutput{}, fmt.Errorf("failed to create transaction context: %w", err)
+	}
+
+	return f.vm.Run(ctx, proc, txn)
 }
 
 // findTransactionIndex returns the index of a transaction in a slice, or -1 if not found
@@ -391,30 +422,27 @@ func separateTransactionsByType(txs []*flowsdk.Transaction) (user, system []*flo
 }
 
 // executeTransactions executes a list of transactions and updates the execution state
-func executeTransactions(
-	vm *fvm.VirtualMachine,
+func (f *forkedExecution) executeTransactions(
 	ctx fvm.Context,
-	execState *fvmState.ExecutionState,
 	txs []*flowsdk.Transaction,
 	startIndex int,
-	logger output.Logger,
 ) error {
 	for i, tx := range txs {
 		flowTx := convert.SDKTransactionToFlow(*tx)
 
-		blockDB := fvmStorage.NewBlockDatabase(execState, 0, nil)
+		blockDB := fvmStorage.NewBlockDatabase(f.execState, 0, nil)
 		txn, err := blockDB.NewTransaction(0, fvmState.DefaultParameters())
 		if err != nil {
 			return fmt.Errorf("failed to create transaction context for tx %d: %w", startIndex+i, err)
 		}
 
 		txProc := fvm.Transaction(flowTx, uint32(startIndex+i))
-		executionSnapshot, _, err := vm.Run(ctx, txProc, txn)
+		executionSnapshot, _, err := f.vm.Run(ctx, txProc, txn)
 		if err != nil {
 			return fmt.Errorf("failed to execute transaction %d (%s): %w", startIndex+i, tx.ID().String()[:txIDDisplayLength], err)
 		}
 
-		if err := execState.Merge(executionSnapshot); err != nil {
+		if err := f.execState.Merge(executionSnapshot); err != nil {
 			return fmt.Errorf("failed to merge execution snapshot for tx %d: %w", startIndex+i, err)
 		}
 	}
@@ -44,6 +44,7 @@ type Flags struct {
 	Exclude      []string `default:"" flag:"exclude" info:"Fields to exclude from the output (events)"`
 	ComputeLimit uint64   `default:"1000" flag:"compute-limit" info:"transaction compute limit"`
 	GasLimit     uint64   `default:"" flag:"gas-limit" info:"(deprecated: use compute-limit) transaction gas limit"`
+	Simulate     bool     `default:"false" flag:"simulate" info:"Execute the transaction against a local fork of the network at the latest sealed block, without submitting it"`
 }
 
 var flags = Flags{}
@@ -146,6 +147,25 @@ func SendTransaction(code []byte, args []string, location string, flow flowkit.S
 		computeLimit = sendFlags.GasLimit
 	}
 
+	if sendFlags.Simulate {
+		roles := transactions.AddressesRoles{
+			Proposer: proposer.Address,
+			Payer:    payer.Address,
+		}
+		for _, authorizer := range authorizers {
+			roles.Authorizers = append(roles.Authorizers, authorizer.Address)
+		}
+
+		return simulateTransaction(
+			flow,
+			roles,
+			proposer.Key.Index(),
+			flowkit.Script{Code: code, Args: transactionArgs, Location: location},
+			computeLimit,
+			logger,
+		)
+	}
+
 	tx, txResult, err := flow.SendTransaction(
 		context.Background(),
 		transactions.AccountRoles{
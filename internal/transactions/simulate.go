@@ -0,0 +1,357 @@
+/*
+ * Flow CLI
+ *
+ * Copyright Flow Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package transactions
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/onflow/cadence"
+	"github.com/onflow/cadence/encoding/ccf"
+	jsoncdc "github.com/onflow/cadence/encoding/json"
+	"github.com/onflow/flow-go/fvm"
+	"github.com/onflow/flow-go/fvm/storage/snapshot"
+	flowgo "github.com/onflow/flow-go/model/flow"
+
+	"github.com/onflow/flow-emulator/convert"
+	flowsdk "github.com/onflow/flow-go-sdk"
+
+	"github.com/onflow/flowkit/v2"
+	"github.com/onflow/flowkit/v2/output"
+	"github.com/onflow/flowkit/v2/transactions"
+
+	"github.com/onflow/flow-cli/internal/command"
+	"github.com/onflow/flow-cli/internal/events"
+	"github.com/onflow/flow-cli/internal/util"
+)
+
+// accountStateScript returns the storage used and the FLOW balance of an account.
+const accountStateScript = `
+access(all) fun main(address: Address): [AnyStruct] {
+	let account = getAccount(address)
+	return [account.storage.used, account.balance]
+}
+`
+
+// accountChange is the change of the storage used and the FLOW balance of an account
+// written by a simulated transaction.
+type accountChange struct {
+	Address       flowsdk.Address
+	StorageBefore uint64
+	StorageAfter  uint64
+	BalanceBefore cadence.UFix64
+	BalanceAfter  cadence.UFix64
+}
+
+func (c accountChange) storageDelta() int64 {
+	return int64(c.StorageAfter) - int64(c.StorageBefore)
+}
+
+func (c accountChange) balanceDelta() cadence.Fix64 {
+	return cadence.Fix64(int64(c.BalanceAfter) - int64(c.BalanceBefore))
+}
+
+// simulateTransaction executes the transaction against a local fork of the network at the latest sealed block,
+// without signing or submitting it.
+//
+// Fees are charged as on the network, but signatures and sequence numbers are not checked.
+func simulateTransaction(
+	flow flowkit.Services,
+	roles transactions.AddressesRoles,
+	proposerKeyIndex uint32,
+	script flowkit.Script,
+	computeLimit uint64,
+	logger output.Logger,
+) (command.Result, error) {
+	ctx := context.Background()
+	network := flow.Network()
+
+	tx, err := flow.BuildTransaction(ctx, roles, proposerKeyIndex, script, computeLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.StartProgress(fmt.Sprintf("Simulating transaction on a fork of %s...", network.Name))
+	defer logger.StopProgress()
+
+	block, err := flow.GetBlock(ctx, flowkit.BlockQuery{Latest: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest sealed block: %w", err)
+	}
+
+	chainID, err := util.GetChainIDFromHost(network.Host)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chain ID from host %s: %w", network.Host, err)
+	}
+
+	blockHeader := &flowgo.Header{
+		HeaderBody: flowgo.HeaderBody{
+			ChainID:   chainID,
+			ParentID:  flowgo.Identifier(block.ID),
+			Height:    block.Height + 1,
+			Timestamp: uint64(block.Timestamp.UnixMilli()),
+		},
+	}
+
+	fork, err := newForkedExecution(network.Host, chainID, block.Height, blockHeader)
+	if err != nil {
+		return nil, err
+	}
+
+	flowTx := tx.FlowTransaction()
+	executionSnapshot, txOutput, err := fork.run(fork.context(true, false), fvm.Transaction(convert.SDKTransactionToFlow(*flowTx), 0))
+	if err != nil {
+		return nil, fmt.Errorf("failed to simulate transaction: %w", err)
+	}
+
+	addresses := writtenAccounts(executionSnapshot)
+	changes := make([]accountChange, len(addresses))
+	for i, address := range addresses {
+		changes[i].Address = flowsdk.Address(address)
+		changes[i].StorageBefore, changes[i].BalanceBefore, err = fork.accountState(address)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := fork.execState.Merge(executionSnapshot); err != nil {
+		return nil, fmt.Errorf("failed to merge execution snapshot: %w", err)
+	}
+
+	for i, address := range addresses {
+		changes[i].StorageAfter, changes[i].BalanceAfter, err = fork.accountState(address)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	emitted, err := sdkEvents(txOutput.Events)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &simulationResult{
+		network:         network.Name,
+		blockHeight:     block.Height,
+		tx:              flowTx,
+		computationUsed: txOutput.ComputationUsed,
+		events:          emitted,
+		accounts:        changes,
+	}
+	if txOutput.Err != nil {
+		result.err = txOutput.Err.Error()
+	}
+	return result, nil
+}
+
+// writtenAccounts returns the addresses of the accounts whose registers are written in the snapshot, sorted.
+func writtenAccounts(executionSnapshot *snapshot.ExecutionSnapshot) []flowgo.Address {
+	written := make(map[flowgo.Address]bool)
+	for id := range executionSnapshot.WriteSet {
+		if id.Owner == "" {
+			continue
+		}
+		written[flowgo.BytesToAddress([]byte(id.Owner))] = true
+	}
+
+	addresses := make([]flowgo.Address, 0, len(written))
+	for address := range written {
+		addresses = append(addresses, address)
+	}
+	sort.Slice(addresses, func(i, j int) bool {
+		return bytes.Compare(addresses[i][:], addresses[j][:]) < 0
+	})
+	return addresses
+}
+
+// accountState returns the storage used and the FLOW balance of the account on the fork.
+// Both are zero if the account does not exist.
+func (f *forkedExecution) accountState(address flowgo.Address) (uint64, cadence.UFix64, error) {
+	argument, err := jsoncdc.Encode(cadence.NewAddress(address))
+	if err != nil {
+		return 0, 0, err
+	}
+
+	proc := fvm.Script([]byte(accountStateScript)).WithArguments(argument)
+	_, scriptOutput, err := f.run(f.context(false, false), proc)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to get state of account %s: %w", address.HexWithPrefix(), err)
+	}
+	if scriptOutput.Err != nil {
+		return 0, 0, nil
+	}
+
+	values, ok := scriptOutput.Value.(cadence.Array)
+	if !ok || len(values.Values) != 2 {
+		return 0, 0, fmt.Errorf("unexpected state of account %s: %v", address.HexWithPrefix(), scriptOutput.Value)
+	}
+	used, usedOk := values.Values[0].(cadence.UInt64)
+	balance, balanceOk := values.Values[1].(cadence.UFix64)
+	if !usedOk || !balanceOk {
+		return 0, 0, fmt.Errorf("unexpected state of account %s: %v", address.HexWithPrefix(), scriptOutput.Value)
+	}
+	return uint64(used), balance, nil
+}
+
+// sdkEvents converts the events emitted by the FVM to SDK events.
+func sdkEvents(emitted flowgo.EventsList) ([]flowsdk.Event, error) {
+	result := make([]flowsdk.Event, 0, len(emitted))
+	for _, event := range emitted {
+		value, err := ccf.Decode(nil, event.Payload)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode event %s: %w", event.Type, err)
+		}
+		eventValue, ok := value.(cadence.Event)
+		if !ok {
+			return nil, fmt.Errorf("failed to decode event %s: not an event", event.Type)
+		}
+
+		result = append(result, flowsdk.Event{
+			Type:             string(event.Type),
+			TransactionID:    flowsdk.Identifier(event.TransactionID),
+			TransactionIndex: int(event.TransactionIndex),
+			EventIndex:       int(event.EventIndex),
+			Value:            eventValue,
+			Payload:          event.Payload,
+		})
+	}
+	return result, nil
+}
+
+type simulationResult struct {
+	network         string
+	blockHeight     uint64
+	tx              *flowsdk.Transaction
+	err             string
+	computationUsed uint64
+	events          []flowsdk.Event
+	accounts        []accountChange
+}
+
+func (r *simulationResult) status() string {
+	if r.err != "" {
+		return "failed"
+	}
+	return "success"
+}
+
+func (r *simulationResult) JSON() any {
+	emitted := make([]any, 0, len(r.events))
+	for _, event := range r.events {
+		emitted = append(emitted, map[string]any{
+			"index":  event.EventIndex,
+			"type":   event.Type,
+			"values": json.RawMessage(jsoncdc.MustEncode(event.Value)),
+		})
+	}
+
+	changes := make(map[string]any)
+	for _, change := range r.accounts {
+		changes[change.Address.HexWithPrefix()] = map[string]any{
+			"storageBefore": change.StorageBefore,
+			"storageAfter":  change.StorageAfter,
+			"storageDelta":  change.storageDelta(),
+			"balanceBefore": change.BalanceBefore.String(),
+			"balanceAfter":  change.BalanceAfter.String(),
+			"balanceDelta":  change.balanceDelta().String(),
+		}
+	}
+
+	result := map[string]any{
+		"network":         r.network,
+		"blockHeight":     r.blockHeight,
+		"status":          r.status(),
+		"computationUsed": r.computationUsed,
+		"events":          emitted,
+		"accounts":        changes,
+		"submitted":       false,
+	}
+	if r.err != "" {
+		result["error"] = r.err
+	}
+	return result
+}
+
+func (r *simulationResult) String() string {
+	var b bytes.Buffer
+	writer := util.CreateTabWriter(&b)
+
+	_, _ = fmt.Fprintf(writer, "Simulated on a fork of %s at block %d\n\n", r.network, r.blockHeight)
+	if r.err != "" {
+		_, _ = fmt.Fprintf(writer, "Status\t%s FAILED\n", output.ErrorEmoji())
+		_, _ = fmt.Fprintf(writer, "Error\t%s\n", r.err)
+	} else {
+		_, _ = fmt.Fprintf(writer, "Status\t%s SUCCESS\n", output.OkEmoji())
+	}
+	_, _ = fmt.Fprintf(writer, "Payer\t%s\n", r.tx.Payer.HexWithPrefix())
+	_, _ = fmt.Fprintf(writer, "Authorizers\t%s\n", r.tx.Authorizers)
+	_, _ = fmt.Fprintf(writer, "Computation Used\t%d\n", r.computationUsed)
+
+	_, _ = fmt.Fprintf(writer, "\nAccounts:\n")
+	if len(r.accounts) == 0 {
+		_, _ = fmt.Fprintf(writer, "    None\n")
+	}
+	for _, change := range r.accounts {
+		_, _ = fmt.Fprintf(
+			writer,
+			"    %s\tstorage %+d bytes (%d → %d)\tbalance %s FLOW (%s → %s)\n",
+			change.Address.HexWithPrefix(),
+			change.storageDelta(),
+			change.StorageBefore,
+			change.StorageAfter,
+			signedAmount(change.balanceDelta()),
+			change.BalanceBefore,
+			change.BalanceAfter,
+		)
+	}
+
+	eventsOutput := (&events.EventResult{Events: r.events}).String()
+	if eventsOutput == "" {
+		eventsOutput = "None"
+	}
+	_, _ = fmt.Fprintf(writer, "\nEvents:\t %s\n", eventsOutput)
+
+	_, _ = fmt.Fprintf(writer, "\n%s The transaction was simulated only, nothing was submitted to %s", output.WarningEmoji(), r.network)
+
+	_ = writer.Flush()
+	return b.String()
+}
+
+func (r *simulationResult) Oneliner() string {
+	return fmt.Sprintf(
+		"Simulation on %s at block %d: %s, %d computation, %d %s, nothing submitted",
+		r.network,
+		r.blockHeight,
+		r.status(),
+		r.computationUsed,
+		len(r.events),
+		util.Pluralize("event", len(r.events)),
+	)
+}
+
+// signedAmount formats the amount with its sign, also when it is positive.
+func signedAmount(amount cadence.Fix64) string {
+	if amount > 0 {
+		return "+" + amount.String()
+	}
+	return amount.String()
+}
@@ -0,0 +1,186 @@
+/*
+ * Flow CLI
+ *
+ * Copyright Flow Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package transactions
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/onflow/cadence"
+	flow "github.com/onflow/flow-go-sdk"
+	"github.com/onflow/flow-go-sdk/crypto"
+	"github.com/onflow/flowkit/v2"
+	"github.com/onflow/flowkit/v2/accounts"
+	"github.com/onflow/flowkit/v2/config"
+	"github.com/onflow/flowkit/v2/gateway"
+	"github.com/onflow/flowkit/v2/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-cli/internal/util"
+)
+
+func Test_SimulationResult(t *testing.T) {
+	t.Parallel()
+
+	change := accountChange{
+		Address:       flow.HexToAddress("0x01"),
+		StorageBefore: 1000,
+		StorageAfter:  1200,
+		BalanceBefore: cadence.UFix64(100_000_000),
+		BalanceAfter:  cadence.UFix64(99_999_000),
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		t.Parallel()
+		result := &simulationResult{
+			network:         "testnet",
+			blockHeight:     123,
+			tx:              tests.NewTransaction(),
+			computationUsed: 42,
+			accounts:        []accountChange{change},
+		}
+
+		output := result.String()
+		assert.Contains(t, output, "Simulated on a fork of testnet at block 123")
+		assert.Contains(t, output, "SUCCESS")
+		assert.Contains(t, output, "0x0000000000000001")
+		assert.Contains(t, output, "storage +200 bytes (1000 → 1200)")
+		assert.Contains(t, output, "balance -0.00001000 FLOW (1.00000000 → 0.99999000)")
+		assert.Contains(t, output, "nothing was submitted to testnet")
+
+		jsonMap, ok := result.JSON().(map[string]any)
+		require.True(t, ok)
+		assert.Equal(t, "success", jsonMap["status"])
+		assert.Equal(t, uint64(42), jsonMap["computationUsed"])
+		assert.Equal(t, false, jsonMap["submitted"])
+		assert.NotContains(t, jsonMap, "error")
+
+		changes, ok := jsonMap["accounts"].(map[string]any)
+		require.True(t, ok)
+		assert.Equal(t, map[string]any{
+			"storageBefore": uint64(1000),
+			"storageAfter":  uint64(1200),
+			"storageDelta":  int64(200),
+			"balanceBefore": "1.00000000",
+			"balanceAfter":  "0.99999000",
+			"balanceDelta":  "-0.00001000",
+		}, changes["0x0000000000000001"])
+
+		assert.Equal(t, "Simulation on testnet at block 123: success, 42 computation, 0 events, nothing submitted", result.Oneliner())
+	})
+
+	t.Run("Failure", func(t *testing.T) {
+		t.Parallel()
+		result := &simulationResult{
+			network:     "testnet",
+			blockHeight: 123,
+			tx:          tests.NewTransaction(),
+			err:         "panic: not enough funds",
+		}
+
+		output := result.String()
+		assert.Contains(t, output, "FAILED")
+		assert.Contains(t, output, "panic: not enough funds")
+
+		jsonMap, ok := result.JSON().(map[string]any)
+		require.True(t, ok)
+		assert.Equal(t, "failed", jsonMap["status"])
+		assert.Equal(t, "panic: not enough funds", jsonMap["error"])
+	})
+}
+
+func Test_Simulate_Integration_LocalEmulator(t *testing.T) {
+	port := getFreePort(t)
+	emulatorHost := fmt.Sprintf("127.0.0.1:%d", port)
+
+	emulatorServer := createEmulatorServer(t, port)
+	createInitialBlocks(t, emulatorServer.Emulator())
+	startServer(t, emulatorServer, "127.0.0.1", port)
+	defer emulatorServer.Stop()
+
+	time.Sleep(emulatorStableWait)
+
+	rw, _ := tests.ReaderWriter()
+	state, err := flowkit.Init(rw)
+	require.NoError(t, err)
+
+	emulatorAccount, err := accounts.NewEmulatorAccount(rw, crypto.ECDSA_P256, crypto.SHA3_256, "")
+	require.NoError(t, err)
+	state.Accounts().AddOrUpdate(emulatorAccount)
+
+	network := config.Network{Name: "emulator", Host: emulatorHost}
+	state.Networks().AddOrUpdate(network)
+
+	gw, err := gateway.NewGrpcGateway(network)
+	require.NoError(t, err)
+	services := flowkit.NewFlowkit(state, network, gw, util.NoLogger)
+
+	ctx := context.Background()
+	latestBefore, err := services.GetBlock(ctx, flowkit.BlockQuery{Latest: true})
+	require.NoError(t, err)
+	accountBefore, err := services.GetAccount(ctx, emulatorAccount.Address)
+	require.NoError(t, err)
+
+	code := []byte(`
+		transaction {
+			prepare(signer: auth(SaveValue) &Account) {
+				signer.storage.save("simulated", to: /storage/simulated)
+			}
+		}
+	`)
+
+	result, err := SendTransaction(
+		code,
+		[]string{"simulate.cdc"},
+		"simulate.cdc",
+		services,
+		state,
+		Flags{Signer: emulatorAccount.Name, ComputeLimit: transactionGasLimit, Simulate: true},
+		util.NoLogger,
+	)
+	require.NoError(t, err)
+
+	simulation, ok := result.(*simulationResult)
+	require.True(t, ok)
+	assert.Equal(t, "emulator", simulation.network)
+	assert.Equal(t, latestBefore.Height, simulation.blockHeight)
+	assert.Empty(t, simulation.err)
+	assert.Positive(t, simulation.computationUsed)
+
+	var signerChange *accountChange
+	for i, change := range simulation.accounts {
+		if change.Address == emulatorAccount.Address {
+			signerChange = &simulation.accounts[i]
+		}
+	}
+	require.NotNil(t, signerChange)
+	assert.Positive(t, signerChange.storageDelta())
+
+	// nothing was submitted
+	latestAfter, err := services.GetBlock(ctx, flowkit.BlockQuery{Latest: true})
+	require.NoError(t, err)
+	assert.Equal(t, latestBefore.Height, latestAfter.Height)
+
+	accountAfter, err := services.GetAccount(ctx, emulatorAccount.Address)
+	require.NoError(t, err)
+	assert.Equal(t, accountBefore.Keys[0].SequenceNumber, accountAfter.Keys[0].SequenceNumber)
+}
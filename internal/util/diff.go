@@ -0,0 +1,123 @@
+/*
+ * Flow CLI
+ *
+ * Copyright Flow Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// diffContext is the number of unchanged lines shown around changes.
+const diffContext = 3
+
+type diffLine struct {
+	operation diffmatchpatch.Operation
+	text      string
+}
+
+// UnifiedDiff returns the changes from old to new in the unified diff format,
+// or an empty string if there are no changes.
+func UnifiedDiff(path string, old string, new string) string {
+	if old == new {
+		return ""
+	}
+
+	dmp := diffmatchpatch.New()
+	oldChars, newChars, lineArray := dmp.DiffLinesToChars(old, new)
+	diffs := dmp.DiffCharsToLines(dmp.DiffMain(oldChars, newChars, false), lineArray)
+
+	var lines []diffLine
+	for _, diff := range diffs {
+		for _, text := range strings.SplitAfter(diff.Text, "\n") {
+			if text != "" {
+				lines = append(lines, diffLine{operation: diff.Type, text: text})
+			}
+		}
+	}
+
+	// Line numbers in the old and new text before each line
+	oldLines := make([]int, len(lines)+1)
+	newLines := make([]int, len(lines)+1)
+	for i, line := range lines {
+		oldLines[i+1], newLines[i+1] = oldLines[i], newLines[i]
+		if line.operation != diffmatchpatch.DiffInsert {
+			oldLines[i+1]++
+		}
+		if line.operation != diffmatchpatch.DiffDelete {
+			newLines[i+1]++
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("--- a/%s\n+++ b/%s\n", path, path))
+
+	for i := 0; i < len(lines); {
+		if lines[i].operation == diffmatchpatch.DiffEqual {
+			i++
+			continue
+		}
+
+		// Changes separated by less than twice the context are in the same hunk
+		end := i + 1
+		for j := end; j < len(lines) && j-end < 2*diffContext; j++ {
+			if lines[j].operation != diffmatchpatch.DiffEqual {
+				end = j + 1
+			}
+		}
+
+		start := max(i-diffContext, 0)
+		end = min(end+diffContext, len(lines))
+
+		sb.WriteString(fmt.Sprintf(
+			"@@ -%s +%s @@\n",
+			hunkRange(oldLines[start], oldLines[end]),
+			hunkRange(newLines[start], newLines[end]),
+		))
+
+		for _, line := range lines[start:end] {
+			switch line.operation {
+			case diffmatchpatch.DiffEqual:
+				sb.WriteString(" ")
+			case diffmatchpatch.DiffDelete:
+				sb.WriteString("-")
+			case diffmatchpatch.DiffInsert:
+				sb.WriteString("+")
+			}
+			sb.WriteString(line.text)
+			if !strings.HasSuffix(line.text, "\n") {
+				sb.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+
+		i = end
+	}
+
+	return sb.String()
+}
+
+// hunkRange formats the lines after start up to end, where an empty range refers to the line before it.
+func hunkRange(start int, end int) string {
+	count := end - start
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
@@ -0,0 +1,72 @@
+/*
+ * Flow CLI
+ *
+ * Copyright Flow Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	t.Parallel()
+
+	t.Run("no changes", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Empty(t, UnifiedDiff("Foo.cdc", "a\nb\n", "a\nb\n"))
+	})
+
+	t.Run("separate hunks", func(t *testing.T) {
+		t.Parallel()
+
+		old := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n"
+		new := "1\nTWO\n3\n4\n5\n6\n7\n8\n9\n10\n12\n"
+
+		assert.Equal(t,
+			"--- a/Foo.cdc\n+++ b/Foo.cdc\n"+
+				"@@ -1,5 +1,5 @@\n 1\n-2\n+TWO\n 3\n 4\n 5\n"+
+				"@@ -8,5 +8,4 @@\n 8\n 9\n 10\n-11\n 12\n",
+			UnifiedDiff("Foo.cdc", old, new),
+		)
+	})
+
+	t.Run("nearby changes share a hunk", func(t *testing.T) {
+		t.Parallel()
+
+		old := "1\n2\n3\n4\n5\n"
+		new := "ONE\n2\n3\n4\nFIVE\n"
+
+		assert.Equal(t,
+			"--- a/Foo.cdc\n+++ b/Foo.cdc\n"+
+				"@@ -1,5 +1,5 @@\n-1\n+ONE\n 2\n 3\n 4\n-5\n+FIVE\n",
+			UnifiedDiff("Foo.cdc", old, new),
+		)
+	})
+
+	t.Run("insertion into empty file", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Equal(t,
+			"--- a/Foo.cdc\n+++ b/Foo.cdc\n"+
+				"@@ -0,0 +1,1 @@\n+a\n\\ No newline at end of file\n",
+			UnifiedDiff("Foo.cdc", "", "a"),
+		)
+	})
+}
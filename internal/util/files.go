@@ -20,12 +20,20 @@ package util
 
 import (
 	"fmt"
+	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/onflow/cadence/common"
+	"github.com/radovskyb/watcher"
+
+	"github.com/onflow/flowkit/v2/config"
 )
 
+// WatchInterval is how often watched files are polled for changes.
+const WatchInterval = 500 * time.Millisecond
+
 func AddCDCExtension(name string) string {
 	if strings.HasSuffix(name, ".cdc") {
 		return name
@@ -68,3 +76,40 @@ func NormalizePathLocation(base, relative common.Location) common.Location {
 	normalizedPath := AbsolutePath(baseString.String(), relativeString.String())
 	return common.StringLocation(normalizedPath)
 }
+
+// ProjectDir returns the absolute path of the directory containing the project configuration,
+// which is the last of the configuration paths that exists, apart from the global configuration.
+// It returns the current directory if there is no project configuration.
+func ProjectDir(configPaths []string) (string, error) {
+	for i := len(configPaths) - 1; i >= 0; i-- {
+		path := configPaths[i]
+		if path == config.GlobalPath() {
+			continue
+		}
+		if _, err := os.Stat(path); err == nil {
+			return filepath.Abs(filepath.Dir(path))
+		}
+	}
+	return os.Getwd()
+}
+
+// WatchFiles starts polling the directory and all its subdirectories for changes.
+// Changes are reported on the event channel of the watcher, and polling errors on its error channel.
+// The returned channel reports the error starting to poll, after which no changes are reported,
+// so callers must read it.
+func WatchFiles(w *watcher.Watcher, dir string) (<-chan error, error) {
+	err := w.AddRecursive(dir)
+	if err != nil {
+		return nil, fmt.Errorf("add recursive files failed: %w", err)
+	}
+
+	startErr := make(chan error, 1)
+	go func() {
+		err := w.Start(WatchInterval)
+		if err != nil {
+			startErr <- err
+		}
+	}()
+
+	return startErr, nil
+}